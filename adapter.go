@@ -0,0 +1,83 @@
+package lars
+
+import (
+	"context"
+	"net/http"
+)
+
+// adapterKey is the key the *Ctx is stored under in the request's context while
+// it's passed through net/http middleware, so the chain can be resumed.
+type adapterKey struct{}
+
+// adapterNext resumes the chain of the *Ctx the request was passed through
+// WrapMiddleware with, it's the next handler of all wrapped middleware.
+var adapterNext = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	r.Context().Value(adapterKey{}).(*Ctx).resume(w, r)
+})
+
+// WrapMiddleware adapts standard net/http middleware, func(http.Handler) http.Handler,
+// so it can be passed to Use or a route; the middleware is created once and calling
+// it's next handler continues the chain with the request and writer it was passed.
+// Passing the middleware to Use directly wraps it the same way.
+//
+// i.e. l.Use(lars.WrapMiddleware(handlers.ProxyHeaders))
+func WrapMiddleware(m func(http.Handler) http.Handler) HandlerFunc {
+
+	h := m(adapterNext)
+
+	return func(c Context) {
+
+		ctx := c.BaseContext()
+		r := ctx.Request()
+
+		h.ServeHTTP(ctx.response, r.WithContext(context.WithValue(r.Context(), adapterKey{}, ctx)))
+	}
+}
+
+// WrapNegroni adapts negroni style middleware, func(http.ResponseWriter, *http.Request,
+// http.HandlerFunc), so it can be passed to Use or a route; calling next continues the
+// chain with the request and writer it was passed. Passing the middleware to Use
+// directly wraps it the same way.
+func WrapNegroni(m func(http.ResponseWriter, *http.Request, http.HandlerFunc)) HandlerFunc {
+
+	return func(c Context) {
+
+		ctx := c.BaseContext()
+
+		m(ctx.response, ctx.Request(), ctx.resume)
+	}
+}
+
+// resume continues the chain with the request and writer passed to a net/http
+// middleware's next handler, writers wrapping the *Response, such as compression
+// middleware's, are written to by the remaining handlers until they return.
+func (c *Ctx) resume(w http.ResponseWriter, r *http.Request) {
+
+	c.request = r
+
+	if w != http.ResponseWriter(c.response) {
+
+		prev := c.response
+
+		c.response = &Response{
+			ResponseWriter: w,
+			context:        prev.context,
+			status:         prev.status,
+			committed:      prev.committed,
+		}
+
+		defer func() {
+
+			// hooks registered by the remaining handlers, Before hooks are only left
+			// when the writer hasn't been written to
+			prev.before = append(prev.before, c.response.before...)
+			prev.after = append(prev.after, c.response.after...)
+
+			c.response = prev
+		}()
+	}
+
+	if c.index+1 < len(c.handlers) {
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package lars
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+type adapterKeyTest struct{}
+
+// upperWriter wraps the writer, upper casing everything written, as compression
+// middleware would replace the writer
+type upperWriter struct {
+	http.ResponseWriter
+}
+
+func (u upperWriter) Write(b []byte) (int, error) {
+	return u.ResponseWriter.Write(bytes.ToUpper(b))
+}
+
+func stdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Header.Get("Authorization") == blank {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("X-Std", "1")
+		next.ServeHTTP(upperWriter{w}, r.WithContext(context.WithValue(r.Context(), adapterKeyTest{}, "std")))
+	})
+}
+
+func negroniMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	w.Header().Set("X-Negroni", "1")
+	next(w, r.WithContext(context.WithValue(r.Context(), adapterKeyTest{}, "negroni")))
+}
+
+func TestWrapMiddleware(t *testing.T) {
+
+	var status int
+
+	handler := func(c Context) {
+		c.Text(http.StatusOK, "value "+c.Request().Context().Value(adapterKeyTest{}).(string))
+	}
+
+	l := New()
+	l.Use(func(c Context) {
+		c.Next()
+		status = c.Response().Status()
+	})
+	l.Get("/std", WrapMiddleware(stdMiddleware), handler)
+	l.Get("/negroni", WrapNegroni(negroniMiddleware), handler)
+
+	g := l.Group("/direct", stdMiddleware, negroniMiddleware)
+	g.Get("", handler)
+
+	r, _ := http.NewRequest(GET, "/std", nil)
+	r.Header.Set("Authorization", "token")
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "VALUE STD")
+	Equal(t, w.Header().Get("X-Std"), "1")
+	Equal(t, status, http.StatusOK)
+
+	code, body := request(GET, "/std", l)
+	Equal(t, code, http.StatusUnauthorized)
+	Equal(t, body, "unauthorized\n")
+	Equal(t, status, http.StatusUnauthorized)
+
+	code, body = request(GET, "/negroni", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "value negroni")
+
+	r, _ = http.NewRequest(GET, "/direct", nil)
+	r.Header.Set("Authorization", "token")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "VALUE NEGRONI")
+	Equal(t, w.Header().Get("X-Std"), "1")
+	Equal(t, w.Header().Get("X-Negroni"), "1")
+}
@@ -0,0 +1,247 @@
+package lars
+
+import (
+	"encoding/xml"
+	"mime"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// BinderFunc decodes the request body of the Context into v
+type BinderFunc func(c Context, v interface{}) error
+
+// RegisterBinder registers the BinderFunc used by c.Bind for requests with the
+// given media type, i.e. "application/msgpack", replacing any existing one.
+// Registering a nil BinderFunc removes the media type's binder. JSON, XML,
+// form and multipart form binders are registered by default.
+func (l *LARS) RegisterBinder(mediaType string, fn BinderFunc) {
+
+	mediaType = strings.ToLower(mediaType)
+
+	if fn == nil {
+		delete(l.binders, mediaType)
+		return
+	}
+
+	l.binders[mediaType] = fn
+}
+
+// Bind decodes the request body into v using the BinderFunc registered for
+// the request's Content-Type, ErrUnsupportedMediaType is returned when none
+// is registered. Unlike Decode, URL query parameters are not included when
+// binding forms.
+func (c *Ctx) Bind(v interface{}) error {
+
+	typ, _, err := mime.ParseMediaType(c.request.Header.Get(ContentType))
+	if err != nil {
+		return ErrUnsupportedMediaType
+	}
+
+	fn, ok := c.lars.binders[typ]
+	if !ok {
+		return ErrUnsupportedMediaType
+	}
+
+	return requestBodyError(fn(c.parent, v))
+}
+
+func defaultBinders() map[string]BinderFunc {
+	return map[string]BinderFunc{
+		ApplicationJSON: bindJSON,
+		ApplicationXML:  bindXML,
+		TextXML:         bindXML,
+		ApplicationForm: bindForm,
+		MultipartForm:   bindMultipartForm,
+	}
+}
+
+func bindJSON(c Context, v interface{}) error {
+	return c.BaseContext().jsonCodec().Decode(c.Request().Body, v)
+}
+
+func bindXML(c Context, v interface{}) error {
+	return xml.NewDecoder(c.Request().Body).Decode(v)
+}
+
+func bindForm(c Context, v interface{}) error {
+
+	if err := c.ParseForm(); err != nil {
+		return err
+	}
+
+	return bindValues(v, c.Request().PostForm)
+}
+
+func bindMultipartForm(c Context, v interface{}) error {
+
+	if err := c.ParseMultipartForm(defaultMultipartMemory); err != nil {
+		return err
+	}
+
+	return bindValues(v, c.Request().MultipartForm.Value)
+}
+
+// BindQuery binds the URL query params into the struct pointed to by v, see BindForm
+// for the supported struct tags.
+func (c *Ctx) BindQuery(v interface{}) error {
+	return bindValues(v, c.QueryParams())
+}
+
+// BindForm binds the request's url-encoded or multipart form values, without the URL
+// query params, into the struct pointed to by v. Fields are named using the `form` tag,
+// `form:"-"` skips the field, and slices, pointers and nested structs, named
+// "parent.child", are supported. A `default` tag sets the value of a field missing from
+// the form, comma separated for slices, and time.Time fields are parsed using the
+// layout in a `layout` tag, RFC3339 by default.
+//
+// i.e.
+//
+//	type Search struct {
+//		Query string    `form:"q"`
+//		Page  int       `form:"page" default:"1"`
+//		Tags  []string  `form:"tag"`
+//		Since time.Time `form:"since" layout:"2006-01-02"`
+//	}
+func (c *Ctx) BindForm(v interface{}) error {
+
+	typ, _, _ := mime.ParseMediaType(c.request.Header.Get(ContentType))
+
+	if typ == MultipartForm {
+		return bindMultipartForm(c.parent, v)
+	}
+
+	return bindForm(c.parent, v)
+}
+
+// bindValues decodes values into the struct pointed to by v, after applying the
+// `default` and `layout` tags of it's fields
+func bindValues(v interface{}, values url.Values) error {
+
+	initFormDecoder()
+
+	if t := reflect.TypeOf(v); t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+
+		var err error
+
+		if values, _, err = prepareValues(t.Elem(), blank, values, false); err != nil {
+			return err
+		}
+	}
+
+	return formDecoder.Decode(v, values)
+}
+
+// prepareValues sets the defaults of the struct's fields missing from values and
+// converts the times using a layout to RFC3339, the only format the form decoder
+// parses; values is copied before it's first modified unless copied is set.
+func prepareValues(t reflect.Type, prefix string, values url.Values, copied bool) (url.Values, bool, error) {
+
+	set := func(key string, vals []string) {
+
+		if !copied {
+
+			c := make(url.Values, len(values)+1)
+
+			for k, v := range values {
+				c[k] = v
+			}
+
+			values, copied = c, true
+		}
+
+		values[key] = vals
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+
+		f := t.Field(i)
+
+		if f.PkgPath != blank {
+			continue // unexported
+		}
+
+		name := strings.Split(f.Tag.Get("form"), ",")[0]
+
+		if name == "-" {
+			continue
+		}
+
+		if name == blank {
+			name = f.Name
+		}
+
+		key := prefix + name
+
+		ft := derefType(f.Type)
+
+		if def, ok := f.Tag.Lookup("default"); ok && len(values[key]) == 0 {
+
+			if ft.Kind() == reflect.Slice {
+				set(key, strings.Split(def, ","))
+			} else {
+				set(key, []string{def})
+			}
+		}
+
+		if ft == timeType || (ft.Kind() == reflect.Slice && derefType(ft.Elem()) == timeType) {
+
+			layout := f.Tag.Get("layout")
+			if layout == blank || len(values[key]) == 0 {
+				continue
+			}
+
+			times := make([]string, len(values[key]))
+
+			for j, s := range values[key] {
+
+				tm, err := time.Parse(layout, s)
+				if err != nil {
+					return nil, false, err
+				}
+
+				times[j] = tm.Format(time.RFC3339Nano)
+			}
+
+			set(key, times)
+
+			continue
+		}
+
+		// pointers are only followed when the form has values for them, they're
+		// left nil otherwise, which also stops recursive types recursing forever
+		if ft.Kind() == reflect.Struct && (f.Type.Kind() != reflect.Ptr || hasPrefix(values, key+".")) {
+
+			var err error
+
+			if values, copied, err = prepareValues(ft, key+".", values, copied); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	return values, copied, nil
+}
+
+// derefType returns the type t points to, through any number of pointers
+func derefType(t reflect.Type) reflect.Type {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// hasPrefix returns whether any of the keys of values start with prefix
+func hasPrefix(values url.Values, prefix string) bool {
+
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
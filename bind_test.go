@@ -0,0 +1,238 @@
+package lars
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestBind(t *testing.T) {
+
+	type User struct {
+		ID   int    `json:"id" xml:"id" form:"id"`
+		Name string `json:"name" xml:"name" form:"name"`
+	}
+
+	var user *User
+	var bindErr error
+
+	l := New()
+	l.Post("/users", func(c Context) {
+		user = new(User)
+		bindErr = c.Bind(user)
+	})
+
+	hf := l.Serve()
+
+	bind := func(contentType string, body string) {
+		user, bindErr = nil, nil
+
+		r, _ := http.NewRequest(POST, "/users?id=13", strings.NewReader(body))
+		if contentType != blank {
+			r.Header.Set(ContentType, contentType)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+	}
+
+	bind(ApplicationJSONCharsetUTF8, `{"id":1,"name":"joeybloggs"}`)
+	Equal(t, bindErr, nil)
+	Equal(t, *user, User{ID: 1, Name: "joeybloggs"})
+
+	bind(ApplicationXML, `<User><id>2</id><name>joeybloggs</name></User>`)
+	Equal(t, bindErr, nil)
+	Equal(t, *user, User{ID: 2, Name: "joeybloggs"})
+
+	bind(TextXML, `<User><id>3</id><name>joeybloggs</name></User>`)
+	Equal(t, bindErr, nil)
+	Equal(t, *user, User{ID: 3, Name: "joeybloggs"})
+
+	// query params are not bound
+	form := url.Values{}
+	form.Add("name", "joeybloggs")
+
+	bind(ApplicationForm, form.Encode())
+	Equal(t, bindErr, nil)
+	Equal(t, *user, User{Name: "joeybloggs"})
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	Equal(t, writer.WriteField("id", "4"), nil)
+	Equal(t, writer.WriteField("name", "joeybloggs"), nil)
+	Equal(t, writer.Close(), nil)
+
+	bind(writer.FormDataContentType(), body.String())
+	Equal(t, bindErr, nil)
+	Equal(t, *user, User{ID: 4, Name: "joeybloggs"})
+
+	bind(ApplicationJSON, `{"id":`)
+	NotEqual(t, bindErr, nil)
+
+	bind("application/yaml", "id: 5")
+	Equal(t, bindErr, ErrUnsupportedMediaType)
+
+	bind(blank, `{"id":5}`)
+	Equal(t, bindErr, ErrUnsupportedMediaType)
+
+	// custom binder
+	l.RegisterBinder("Application/YAML", func(c Context, v interface{}) error {
+		v.(*User).Name = "yaml"
+		return nil
+	})
+
+	bind("application/yaml", "name: yaml")
+	Equal(t, bindErr, nil)
+	Equal(t, *user, User{Name: "yaml"})
+
+	// removing a binder
+	l.RegisterBinder(ApplicationJSON, nil)
+
+	bind(ApplicationJSON, `{"id":1}`)
+	Equal(t, bindErr, ErrUnsupportedMediaType)
+}
+
+func TestBindRequestEntityTooLarge(t *testing.T) {
+
+	var bindErr error
+
+	l := New()
+	l.SetMaxRequestBodySize(8)
+	l.Post("/users", func(c Context) error {
+		var v map[string]interface{}
+		bindErr = c.Bind(&v)
+		return bindErr
+	})
+
+	r, _ := http.NewRequest(POST, "/users", strings.NewReader(`{"name":"joeybloggs"}`))
+	r.Header.Set(ContentType, ApplicationJSON)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, bindErr, ErrRequestEntityTooLarge)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+}
+
+type bindAddress struct {
+	City string `form:"city" default:"Toronto"`
+	Zip  string `form:"zip"`
+}
+
+type bindNode struct {
+	Name string    `form:"name"`
+	Next *bindNode `form:"next"`
+}
+
+type bindSearch struct {
+	Query    string      `form:"q"`
+	Page     int         `form:"page" default:"1"`
+	Limit    *int        `form:"limit" default:"20"`
+	Tags     []string    `form:"tag" default:"a,b"`
+	IDs      []int       `form:"id"`
+	Since    time.Time   `form:"since" layout:"2006-01-02"`
+	Until    *time.Time  `form:"until"`
+	Dates    []time.Time `form:"date" layout:"01/02/2006"`
+	Address  bindAddress `form:"address"`
+	Node     *bindNode   `form:"node"`
+	Skipped  string      `form:"-"`
+	Untagged string
+	internal string
+}
+
+func TestBindQueryAndForm(t *testing.T) {
+
+	var s bindSearch
+
+	l := New()
+	l.Get("/search", func(c Context) error {
+		s = bindSearch{}
+		return c.BindQuery(&s)
+	})
+	l.Post("/search", func(c Context) error {
+		s = bindSearch{}
+		return c.BindForm(&s)
+	})
+
+	code, _ := request(GET, "/search?q=lars&page=3&limit=5&tag=x&id=1&id=2&since=2016-01-02&until=2016-01-02T03:04:05Z&date=01/02/2016&date=12/31/2016&address.zip=M5V&node.name=a&node.next.name=b&Skipped=no&Untagged=yes", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, s.Query, "lars")
+	Equal(t, s.Page, 3)
+	Equal(t, *s.Limit, 5)
+	Equal(t, s.Tags, []string{"x"})
+	Equal(t, s.IDs, []int{1, 2})
+	Equal(t, s.Since, time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC))
+	Equal(t, *s.Until, time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC))
+	Equal(t, s.Dates, []time.Time{time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC)})
+	Equal(t, s.Address, bindAddress{City: "Toronto", Zip: "M5V"})
+	Equal(t, s.Node.Name, "a")
+	Equal(t, s.Node.Next.Name, "b")
+	Equal(t, s.Node.Next.Next == nil, true)
+	Equal(t, s.Skipped, "")
+	Equal(t, s.Untagged, "yes")
+
+	// defaults
+	code, _ = request(GET, "/search", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, s.Query, "")
+	Equal(t, s.Page, 1)
+	Equal(t, *s.Limit, 20)
+	Equal(t, s.Tags, []string{"a", "b"})
+	Equal(t, s.Since.IsZero(), true)
+	Equal(t, s.Until == nil, true)
+	Equal(t, s.Address.City, "Toronto")
+	Equal(t, s.Node == nil, true)
+
+	code, _ = request(GET, "/search?since=02-01-2016", l)
+	Equal(t, code, http.StatusInternalServerError)
+
+	// the query params aren't bound from the form
+	r, _ := http.NewRequest(POST, "/search?q=query", strings.NewReader(url.Values{"page": {"2"}, "since": {"2016-01-02"}}.Encode()))
+	r.Header.Set(ContentType, ApplicationForm)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, s.Query, "")
+	Equal(t, s.Page, 2)
+	Equal(t, *s.Limit, 20)
+	Equal(t, s.Since, time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("q", "multipart")
+	mw.WriteField("tag", "m")
+	mw.Close()
+
+	r, _ = http.NewRequest(POST, "/search", body)
+	r.Header.Set(ContentType, mw.FormDataContentType())
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, s.Query, "multipart")
+	Equal(t, s.Page, 1)
+	Equal(t, s.Tags, []string{"m"})
+
+	// the request's values aren't modified by the defaults
+	r, _ = http.NewRequest(GET, "/search?q=a", nil)
+	c := l.pool.Get().(*Ctx)
+	c.RequestStart(httptest.NewRecorder(), r)
+	Equal(t, c.BindQuery(&s), nil)
+	Equal(t, len(c.QueryParams()), 1)
+	l.pool.Put(c)
+}
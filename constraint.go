@@ -0,0 +1,247 @@
+package lars
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// ParamTypeFunc reports whether a URL param value satisfies a named param type
+// used in route constraints i.e. /files/:name{uuid}
+type ParamTypeFunc func(value string) bool
+
+// paramConstraint restricts the value of the route's param at index
+type paramConstraint struct {
+	index int
+	match ParamTypeFunc
+}
+
+var paramTypes = map[string]ParamTypeFunc{
+	"int":      isInt,
+	"uuid":     isUUID,
+	"alpha":    isAlpha,
+	"alphanum": isAlphanum,
+}
+
+// RegisterParamType registers a named param type for use in route constraints,
+// replacing any existing type with the same name; "int", "uuid", "alpha" and
+// "alphanum" are available by default. Types must be registered before the
+// routes using them.
+//
+// i.e. l.RegisterParamType("slug", isSlug) then l.Get("/posts/:slug{slug}", ...)
+func (l *LARS) RegisterParamType(name string, fn ParamTypeFunc) {
+
+	if l.paramTypes == nil {
+		l.paramTypes = make(map[string]ParamTypeFunc)
+	}
+
+	l.paramTypes[name] = fn
+}
+
+// parseConstraints strips the param constraints, a regular expression in
+// parenthesis i.e. :id(\d+) or a named param type in braces i.e. :id{uuid},
+// from the path returning the path to add to the tree and the constraints.
+func (l *LARS) parseConstraints(path string) (string, []paramConstraint) {
+
+	var constraints []paramConstraint
+	var stripped []byte
+	param := -1
+
+	for i := 0; i < len(path); i++ {
+
+		c := path[i]
+
+		if stripped != nil {
+			stripped = append(stripped, c)
+		}
+
+		if c != paramByte && c != wildByte {
+			continue
+		}
+
+		param++
+
+		// find the end of the param name
+		end := i + 1
+		for end < len(path) && path[end] != slashByte && path[end] != '(' && path[end] != '{' {
+			end++
+		}
+
+		if stripped != nil {
+			stripped = append(stripped, path[i+1:end]...)
+		}
+
+		i = end - 1
+
+		if end == len(path) || path[end] == slashByte {
+			continue
+		}
+
+		if c == wildByte {
+			panic("constraints are not permitted on catch-all params, path '" + path + "'")
+		}
+
+		if stripped == nil {
+			stripped = append([]byte(nil), path[:end]...)
+		}
+
+		var fn ParamTypeFunc
+
+		if path[end] == '(' {
+			i, fn = parseRegexConstraint(path, end)
+		} else {
+			i, fn = l.parseTypeConstraint(path, end)
+		}
+
+		if i+1 < len(path) && path[i+1] != slashByte {
+			panic("Character after a param constraint is not permitted, path '" + path + "'")
+		}
+
+		constraints = append(constraints, paramConstraint{index: param, match: fn})
+	}
+
+	if stripped == nil {
+		return path, nil
+	}
+
+	return string(stripped), constraints
+}
+
+// parseRegexConstraint parses the regular expression starting with the
+// parenthesis at start, returning the index of the closing parenthesis.
+func parseRegexConstraint(path string, start int) (int, ParamTypeFunc) {
+
+	depth := 1
+	end := start + 1
+
+	for ; end < len(path) && depth > 0; end++ {
+		switch path[end] {
+		case '\\':
+			end++
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+
+	if depth != 0 {
+		panic("unterminated param constraint in path '" + path + "'")
+	}
+
+	end--
+
+	re, err := regexp.Compile("^(?:" + path[start+1:end] + ")$")
+	if err != nil {
+		panic("invalid param constraint in path '" + path + "': " + err.Error())
+	}
+
+	return end, re.MatchString
+}
+
+// parseTypeConstraint parses the named param type starting with the brace
+// at start, returning the index of the closing brace.
+func (l *LARS) parseTypeConstraint(path string, start int) (int, ParamTypeFunc) {
+
+	end := start + 1
+	for end < len(path) && path[end] != '}' {
+		end++
+	}
+
+	if end == len(path) {
+		panic("unterminated param constraint in path '" + path + "'")
+	}
+
+	name := path[start+1 : end]
+
+	fn, ok := l.paramTypes[name]
+	if !ok {
+		if fn, ok = paramTypes[name]; !ok {
+			panic("unknown param type '" + name + "' in path '" + path + "'")
+		}
+	}
+
+	return end, fn
+}
+
+// matches reports whether the params satisfy all of the constraints
+func (r *Route) matches(p Params) bool {
+
+	for _, c := range r.constraints {
+
+		// an optional param which wasn't matched
+		if c.index >= len(p) {
+			continue
+		}
+
+		if !c.match(p[c.index].Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isInt(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+func isUUID(s string) bool {
+
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func isAlpha(s string) bool {
+
+	if len(s) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAlphanum(s string) bool {
+
+	if len(s) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
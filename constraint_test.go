@@ -0,0 +1,144 @@
+package lars
+
+import (
+	"net/http"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestParamConstraints(t *testing.T) {
+
+	text := func(s string) HandlerFunc {
+		return func(c Context) {
+			c.Text(http.StatusOK, s+" "+c.Param("id"))
+		}
+	}
+
+	l := New()
+	l.Get(`/users/:id(\d+)`, text("id"))
+	l.Get("/users/:id", text("name"))
+	l.Get(`/users/:id([a-z]{2}-\d{2,})`, text("code"))
+	l.Get(`/users/:id(\d+)/posts`, text("posts"))
+	l.Get("/files/:id{uuid}", text("file"))
+	l.Get("/tags/:id{alpha}/*", text("tag"))
+	l.Get(`/orders/:id(\d+|new)/:line{int}`, func(c Context) {
+		c.Text(http.StatusOK, c.Param("id")+" "+c.Param("line"))
+	})
+
+	code, body := request(GET, "/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "id 13")
+
+	code, body = request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "name joeybloggs")
+
+	code, body = request(GET, "/users/ab-123", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "code ab-123")
+
+	code, body = request(GET, "/users/13/posts", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "posts 13")
+
+	code, _ = request(GET, "/users/joeybloggs/posts", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "/files/5e3a4a3c-0c43-4d0e-9a55-9a4f2c1b7e6F", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "file 5e3a4a3c-0c43-4d0e-9a55-9a4f2c1b7e6F")
+
+	code, _ = request(GET, "/files/5e3a4a3c", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "/tags/go/a/b", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "tag go")
+
+	code, _ = request(GET, "/tags/go1/a", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "/orders/new/-1", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "new -1")
+
+	code, _ = request(GET, "/orders/newer/1", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(GET, "/orders/1/x", l)
+	Equal(t, code, http.StatusNotFound)
+
+	// constraints are stripped from the tree path but kept on the route
+	Equal(t, l.Routes()[len(l.Routes())-1].Path, "/users/:id(\\d+)/posts")
+}
+
+func TestParamConstraintsMethodNotAllowed(t *testing.T) {
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.Post(`/users/:id(\d+)`, basicHandler)
+
+	code, _ := request(GET, "/users/13", l)
+	Equal(t, code, http.StatusMethodNotAllowed)
+
+	code, _ = request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusNotFound)
+}
+
+func TestRegisterParamType(t *testing.T) {
+
+	l := New()
+	l.RegisterParamType("even", func(s string) bool {
+		return len(s) > 0 && (s[len(s)-1]-'0')%2 == 0
+	})
+	l.RegisterParamType("int", func(s string) bool {
+		return s == "one"
+	})
+	l.Get("/even/:n{even}", basicHandler)
+	l.Get("/int/:n{int}", basicHandler)
+	l.Get("/alphanum/:n{alphanum}", basicHandler)
+
+	code, _ := request(GET, "/even/12", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/even/13", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(GET, "/int/one", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/int/1", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(GET, "/alphanum/abc123", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/alphanum/abc-123", l)
+	Equal(t, code, http.StatusNotFound)
+}
+
+func TestBadParamConstraints(t *testing.T) {
+
+	l := New()
+	l.Get("/users/:id", basicHandler)
+
+	PanicMatches(t, func() { l.Get("/users/:id", basicHandler) }, "handlers are already registered for path '/users/:id'")
+	PanicMatches(t, func() { l.Get(`/a/:id(\d+`, basicHandler) }, "unterminated param constraint in path '/a/:id(\\d+'")
+	PanicMatches(t, func() { l.Get("/a/:id{int", basicHandler) }, "unterminated param constraint in path '/a/:id{int'")
+	PanicMatches(t, func() { l.Get("/a/:id([)", basicHandler) }, "invalid param constraint in path '/a/:id([)': error parsing regexp: missing closing ]: `[)$`")
+	PanicMatches(t, func() { l.Get("/a/:id{date}", basicHandler) }, "unknown param type 'date' in path '/a/:id{date}'")
+	PanicMatches(t, func() { l.Get(`/a/:id(\d+)x`, basicHandler) }, "Character after a param constraint is not permitted, path '/a/:id(\\d+)x'")
+	PanicMatches(t, func() { l.Get(`/a/*(\d+)`, basicHandler) }, "constraints are not permitted on catch-all params, path '/a/*(\\d+)'")
+}
@@ -1,13 +1,24 @@
 package lars
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -23,26 +34,375 @@ type Param struct {
 // It is therefore safe to read values by the index.
 type Params []Param
 
+// Context is the context interface type
+type Context interface {
+	context.Context
+	Request() *http.Request
+	Response() *Response
+	WebSocket() *websocket.Conn
+	Upgrade(upgrader websocket.Upgrader, handler func(*websocket.Conn)) error
+	WebSocketConn() WebSocketConn
+	UpgradeWith(upgrader WebSocketUpgrader, handler func(WebSocketConn)) error
+	Param(name string) string
+	ParamDefault(name, def string) string
+	ParamInt(name string) (int, error)
+	ParamIntDefault(name string, def int) int
+	ParamInt64(name string) (int64, error)
+	ParamInt64Default(name string, def int64) int64
+	ParamBool(name string) (bool, error)
+	ParamBoolDefault(name string, def bool) bool
+	ParamUUID(name string) (string, error)
+	SafePath(name, root string) (string, error)
+	QueryParams() url.Values
+	QueryMap(prefix string) map[string]string
+	QueryParam(name string) string
+	QueryParamDefault(name, def string) string
+	QueryInt(name string) (int, error)
+	QueryIntDefault(name string, def int) int
+	QueryInt64(name string) (int64, error)
+	QueryInt64Default(name string, def int64) int64
+	QueryBool(name string) (bool, error)
+	QueryBoolDefault(name string, def bool) bool
+	QueryFloat(name string) (float64, error)
+	QueryFloatDefault(name string, def float64) float64
+	ParseForm() error
+	ParseMultipartForm(maxMemory int64) error
+	FormFile(name string) (*multipart.FileHeader, error)
+	MultipartReader() (*multipart.Reader, error)
+	SaveUploadedFile(file *multipart.FileHeader, dst string) error
+	FormFileBytes(name string) ([]byte, string, error)
+	BodyLines(fn func(line []byte) error) error
+	Set(key interface{}, value interface{})
+	Get(key interface{}) (value interface{}, exists bool)
+	GetString(key interface{}) (string, bool)
+	GetInt(key interface{}) (int, bool)
+	GetBool(key interface{}) (bool, bool)
+	Context() context.Context
+	WithContext(context.Context)
+	WithCancel() context.CancelFunc
+	WithDeadline(time.Time) context.CancelFunc
+	WithTimeout(time.Duration) context.CancelFunc
+	WithValue(key interface{}, val interface{})
+	Next()
+	NotAcceptable()
+	Accepts(offers ...string) string
+	Negotiate(code int, offers ...Offer) error
+	Render(code int, name string, data interface{}) error
+	Session() (*Session, error)
+	Cookie(name string) (*http.Cookie, error)
+	Cookies() []*http.Cookie
+	SetCookie(cookie *http.Cookie)
+	RequestStart(w http.ResponseWriter, r *http.Request)
+	RequestEnd()
+	ClientIP() (clientIP string)
+	SecureClientIP() string
+	RequestID() string
+	SetRequestID(id string)
+	AcceptedLanguages(lowercase bool) []string
+	NegotiateLanguage(supported ...string) string
+	Locale() string
+	SetLocale(locale string)
+	T(key string, args ...interface{}) string
+	AcceptCharset(supported ...string) string
+	AcceptEncoding(supported ...string) string
+	HandlerName() string
+	Route() *Route
+	RouteMeta(key string) (value interface{}, exists bool)
+	Log(level, msg string, kv ...interface{})
+	Stream(step func(w io.Writer) bool)
+	StreamErr(step func(w io.Writer) (bool, error)) error
+	SSE() *EventStream
+	CSV(code int, headers []string, rows func() ([]string, bool)) error
+	Push(target string, opts *http.PushOptions) error
+	Redirect(code int, url string) error
+	RedirectToRoute(name string, params ...string) error
+	JSON(int, interface{}) error
+	JSONBytes(int, []byte) error
+	JSONIndent(int, interface{}, string) error
+	JSONPretty(int, interface{}) error
+	JSONStream(int, interface{}) error
+	JSONArrayStream(code int, next func() (interface{}, bool)) error
+	Msgpack(int, interface{}) error
+	MsgpackBytes(int, []byte) error
+	Protobuf(int, interface{}) error
+	ProtobufBytes(int, []byte) error
+	YAML(int, interface{}) error
+	YAMLBytes(int, []byte) error
+	JSONRaw(int, json.RawMessage) error
+	JSONP(int, interface{}, string) error
+	XML(int, interface{}) error
+	XMLBytes(int, []byte) error
+	XMLIndent(int, interface{}, string) error
+	XMLStream(int, interface{}) error
+	Text(int, string) error
+	TextBytes(int, []byte) error
+	Attachment(r io.Reader, filename string) (err error)
+	Inline(r io.Reader, filename string) (err error)
+	ServeContent(name string, modtime time.Time, content io.ReadSeeker)
+	ServeContentConditional(name, etag string, modtime time.Time, content io.ReadSeeker) error
+	Decode(includeFormQueryParams bool, maxMemory int64, v interface{}) (err error)
+	Bind(v interface{}) error
+	BindQuery(v interface{}) error
+	BindForm(v interface{}) error
+	BaseContext() *Ctx
+}
+
+var _ context.Context = &Ctx{}
+
+// Ctx encapsulates the http request, response context
+type Ctx struct {
+	request             *http.Request
+	response            *Response
+	websocket           WebSocketConn
+	params              Params
+	queryParams         url.Values
+	handlers            HandlersChain
+	parent              Context
+	route               *Route
+	index               int
+	formParsed          bool
+	multipartFormParsed bool
+	lars                *LARS
+	logs                []LogEntry
+	session             *Session
+	sse                 *EventStream
+	locale              string
+	requestID           string
+	store               []storeEntry
+	// storeChanged is set when values have been Set since the request's context last
+	// included them
+	storeChanged bool
+	// storeCtx is the request's context last created by syncStore, replaced rather
+	// than wrapped when the values are next synced
+	storeCtx *storeContext
+}
+
+// storeEntry is a key/value pair set using Set
+type storeEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// storeContext is the request's context including a copy of the values set using Set,
+// a copy as the request's context may outlive the pooled Context.
+type storeContext struct {
+	context.Context
+	entries []storeEntry
+}
+
+func (s *storeContext) Value(key interface{}) interface{} {
+
+	for i := range s.entries {
+		if s.entries[i].key == key {
+			return s.entries[i].value
+		}
+	}
+
+	return s.Context.Value(key)
+}
+
+// RequestStart resets the Context to it's default request state
+func (c *Ctx) RequestStart(w http.ResponseWriter, r *http.Request) {
+	c.request = r
+	c.response.reset(w)
+	c.params = c.params[0:0]
+	c.queryParams = nil
+	c.index = -1
+	c.handlers = nil
+	c.route = nil
+	c.formParsed = false
+	c.multipartFormParsed = false
+	c.session = nil
+	c.sse = nil
+	c.locale = blank
+	c.requestID = blank
+
+	// cleared so the previous request's values can be garbage collected
+	for i := range c.store {
+		c.store[i] = storeEntry{}
+	}
+
+	c.store = c.store[:0]
+	c.storeChanged = false
+	c.storeCtx = nil
+}
+
+// Set is used to store a new key/value pair on this Context for the duration of the
+// request, the key must be comparable; setting an existing key replaces it's value.
+// Unlike context.WithValue it doesn't allocate once the store has grown, values are
+// available using Get and Value, the Context itself being a context.Context, and
+// through the *http.Request's context, i.e. to native http.Handler's, which is only
+// updated, with a copy of the values, once the request is next retrieved.
+func (c *Ctx) Set(key interface{}, value interface{}) {
+
+	c.storeChanged = true
+
+	for i := range c.store {
+		if c.store[i].key == key {
+			c.store[i].value = value
+			return
+		}
+	}
+
+	c.store = append(c.store, storeEntry{key: key, value: value})
+}
+
+// syncStore updates the request's context to include the values set using Set
+func (c *Ctx) syncStore() {
+
+	c.storeChanged = false
+
+	entries := make([]storeEntry, len(c.store))
+	copy(entries, c.store)
+
+	parent := c.request.Context()
+
+	// the previous copy of the values is replaced, keeping a single layer however many
+	// times they're synced; unless the context was since changed, i.e. using WithContext
+	if parent == context.Context(c.storeCtx) {
+		parent = c.storeCtx.Context
+	}
+
+	c.storeCtx = &storeContext{Context: parent, entries: entries}
+
+	*c.request = *c.request.WithContext(c.storeCtx) // temporarily shallow copying to avoid problems with external libraries
+}
+
+// Get returns the value for the given key, set using Set or in the *http.Request's
+// context, and whether it was found.
+func (c *Ctx) Get(key interface{}) (value interface{}, exists bool) {
+	value = c.Value(key)
+	exists = value != nil
+	return
+}
+
+// context.Context functions to comply with context.Context interface and keep context update on lars.Context object
+
+// Context returns the request's context. To change the context, use
+// WithContext.
+//
+// The returned context is always non-nil and is derived from the incoming
+// *http.Request's context, so it is cancelled when the client's connection
+// closes; handlers observe this through Done(). Contexts created using
+// WithCancel, WithDeadline and WithTimeout are derived from it and are
+// cancelled along with it. Values set using Set are included.
+func (c *Ctx) Context() context.Context {
+	return c.Request().Context()
+}
+
+// WithContext updates the underlying request's context with to ctx
+// The provided ctx must be non-nil.
+func (c *Ctx) WithContext(ctx context.Context) {
+	*c.request = *c.request.WithContext(ctx) // temporarily shallow copying to avoid problems with external libraries
+}
+
+// Deadline calls the underlying context.Context Deadline()
+func (c *Ctx) Deadline() (deadline time.Time, ok bool) {
+	return c.request.Context().Deadline()
+}
+
+// Done calls the underlying context.Context Done()
+func (c *Ctx) Done() <-chan struct{} {
+	return c.request.Context().Done()
+}
+
+// Err calls the underlying context.Context Err()
+func (c *Ctx) Err() error {
+	return c.request.Context().Err()
+}
+
+// Value returns the value set for key using Set, falling back to
+// the underlying context.Context Value()
+func (c *Ctx) Value(key interface{}) interface{} {
+
+	for i := range c.store {
+		if c.store[i].key == key {
+			return c.store[i].value
+		}
+	}
+
+	return c.request.Context().Value(key)
+}
+
+// WithCancel calls context.WithCancel and automatically
+// updates context on the containing lars.Context object.
+func (c *Ctx) WithCancel() context.CancelFunc {
+	ctx, cf := context.WithCancel(c.request.Context())
+	*c.request = *c.request.WithContext(ctx) // temporarily shallow copying to avoid problems with external libraries
+	return cf
+}
+
+// WithDeadline calls context.WithDeadline and automatically
+// updates context on the containing lars.Context object.
+func (c *Ctx) WithDeadline(deadline time.Time) context.CancelFunc {
+	ctx, cf := context.WithDeadline(c.request.Context(), deadline)
+	*c.request = *c.request.WithContext(ctx) // temporarily shallow copying to avoid problems with external libraries
+	return cf
+}
+
+// WithTimeout calls context.WithTimeout and automatically
+// updates context on the containing lars.Context object.
+func (c *Ctx) WithTimeout(timeout time.Duration) context.CancelFunc {
+	ctx, cf := context.WithTimeout(c.request.Context(), timeout)
+	*c.request = *c.request.WithContext(ctx) // temporarily shallow copying to avoid problems with external libraries
+	return cf
+}
+
+// WithValue calls context.WithValue and automatically
+// updates context on the containing lars.Context object.
+// Can also use Set() function on Context object (Recommended)
+func (c *Ctx) WithValue(key interface{}, val interface{}) {
+	*c.request = *c.request.WithContext(context.WithValue(c.request.Context(), key, val)) // temporarily shallow copying to avoid problems with external libraries
+}
+
 // NewContext returns a new default lars Context object.
 func NewContext(l *LARS) *Ctx {
 
 	c := &Ctx{
 		params: make(Params, l.mostParams),
+		lars:   l,
 	}
 
 	c.response = newResponse(nil, c)
+	c.parent = c
 
 	return c
 }
 
+// SetParams sets the Context's URL params, as the router would when matching a route;
+// it's meant for testing handlers without a router, see the larstest package.
+func (c *Ctx) SetParams(params Params) {
+	c.params = params
+}
+
+// RunHandlers runs the handlers, wrapped the same as when registering a route, in
+// place of any remaining handlers; it's meant for testing handlers and middleware
+// without a router, see the larstest package.
+func (c *Ctx) RunHandlers(handlers ...Handler) {
+
+	chain := make(HandlersChain, len(handlers))
+
+	for i, h := range handlers {
+		chain[i] = c.lars.wrapHandler(h)
+	}
+
+	c.run(chain)
+}
+
 // BaseContext returns the underlying context object LARS uses internally.
 // used when overriding the context object
 func (c *Ctx) BaseContext() *Ctx {
 	return c
 }
 
-// Request returns context assotiated *http.Request.
+// Request returns context assotiated *http.Request, it's context includes the values
+// set using Set.
 func (c *Ctx) Request() *http.Request {
+
+	if c.storeChanged && c.request != nil {
+		c.syncStore()
+	}
+
 	return c.request
 }
 
@@ -51,11 +411,6 @@ func (c *Ctx) Response() *Response {
 	return c.response
 }
 
-// WebSocket returns context's assotiated *websocket.Conn.
-func (c *Ctx) WebSocket() *websocket.Conn {
-	return c.websocket
-}
-
 // RequestEnd fires after request completes and just before
 // the *Ctx object gets put back into the pool.
 // Used to close DB connections and such on a custom context
@@ -64,6 +419,8 @@ func (c *Ctx) RequestEnd() {
 
 // Param returns the value of the first Param which key matches the given name.
 // If no matching Param is found, an empty string is returned.
+// NOTE: the value matched by a named catch-all, i.e. /*filepath, is available using
+// it's name, c.Param("filepath"), as well as WildcardParam.
 func (c *Ctx) Param(name string) string {
 
 	for _, entry := range c.params {
@@ -72,9 +429,142 @@ func (c *Ctx) Param(name string) string {
 		}
 	}
 
+	// the catch-all, when matched, is always the last param
+	if name == WildcardParam && c.route != nil && c.route.catchAll && len(c.params) > 0 {
+		return c.params[len(c.params)-1].Value
+	}
+
 	return blank
 }
 
+// ParamDefault returns the value of the Param which key matches the given name or def
+// when it's not found or is empty, such as when a catch-all matched nothing.
+func (c *Ctx) ParamDefault(name, def string) string {
+
+	if v := c.Param(name); v != blank {
+		return v
+	}
+
+	return def
+}
+
+// ParamInt returns the Param with the given name parsed as an int; when it's missing
+// or can't be parsed a 400 Bad Request *HTTPError is returned, so the handler can
+// simply return it.
+func (c *Ctx) ParamInt(name string) (int, error) {
+
+	i, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		return 0, paramError(name, err)
+	}
+
+	return i, nil
+}
+
+// ParamIntDefault returns the Param with the given name parsed as an int or def
+// when it's missing or can't be parsed.
+func (c *Ctx) ParamIntDefault(name string, def int) int {
+
+	if i, err := c.ParamInt(name); err == nil {
+		return i
+	}
+
+	return def
+}
+
+// ParamInt64 returns the Param with the given name parsed as an int64; when it's
+// missing or can't be parsed a 400 Bad Request *HTTPError is returned.
+func (c *Ctx) ParamInt64(name string) (int64, error) {
+
+	i, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, paramError(name, err)
+	}
+
+	return i, nil
+}
+
+// ParamInt64Default returns the Param with the given name parsed as an int64 or
+// def when it's missing or can't be parsed.
+func (c *Ctx) ParamInt64Default(name string, def int64) int64 {
+
+	if i, err := c.ParamInt64(name); err == nil {
+		return i
+	}
+
+	return def
+}
+
+// ParamBool returns the Param with the given name parsed as a bool, any value
+// accepted by strconv.ParseBool is valid; when it's missing or can't be parsed a
+// 400 Bad Request *HTTPError is returned.
+func (c *Ctx) ParamBool(name string) (bool, error) {
+
+	b, err := strconv.ParseBool(c.Param(name))
+	if err != nil {
+		return false, paramError(name, err)
+	}
+
+	return b, nil
+}
+
+// ParamBoolDefault returns the Param with the given name parsed as a bool or def
+// when it's missing or can't be parsed.
+func (c *Ctx) ParamBoolDefault(name string, def bool) bool {
+
+	if b, err := c.ParamBool(name); err == nil {
+		return b
+	}
+
+	return def
+}
+
+// ParamUUID returns the Param with the given name, lowercased, when it's a UUID
+// in it's canonical form i.e. 6ba7b810-9dad-11d1-80b4-00c04fd430c8; otherwise
+// a 400 Bad Request *HTTPError is returned.
+func (c *Ctx) ParamUUID(name string) (string, error) {
+
+	v := c.Param(name)
+	if !isUUID(v) {
+		return blank, paramError(name, fmt.Errorf("%q is not a valid UUID", v))
+	}
+
+	return strings.ToLower(v), nil
+}
+
+// SafePath returns the Param with the given name, usually a catch-all such as
+// /files/*filepath, cleaned and joined to root as a file path; when the Param would
+// traverse outside of root, i.e. ../../etc/passwd, or contains a NUL byte a 400 Bad
+// Request *HTTPError is returned.
+//
+//	p, err := c.SafePath("filepath", "/var/www/files")
+//	if err != nil {
+//		return err
+//	}
+//	f, err := os.Open(p)
+func (c *Ctx) SafePath(name, root string) (string, error) {
+
+	v := c.Param(name)
+
+	if strings.IndexByte(v, 0) != -1 {
+		return blank, paramError(name, errors.New("contains a NUL byte"))
+	}
+
+	root = filepath.Clean(root)
+	p := filepath.Join(root, filepath.FromSlash(v))
+
+	if rel, err := filepath.Rel(root, p); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return blank, paramError(name, fmt.Errorf("%q is outside of the root directory", v))
+	}
+
+	return p, nil
+}
+
+// paramError returns the 400 Bad Request *HTTPError for the invalid Param
+func paramError(name string, err error) error {
+	return NewHTTPError(http.StatusBadRequest, "invalid param '"+name+"'").SetInternal(err)
+}
+
 // QueryParams returns the http.Request.URL.Query() values
 // this function is not for convenience, but rather performance
 // URL.Query() reparses the RawQuery every time it's called, but this
@@ -91,6 +581,113 @@ func (c *Ctx) QueryParams() url.Values {
 	return c.queryParams
 }
 
+// QueryParam returns the first value of the query param with the given name,
+// using the cached QueryParams() values, or blank when it's not found.
+func (c *Ctx) QueryParam(name string) string {
+	return c.QueryParams().Get(name)
+}
+
+// QueryParamDefault returns the first value of the query param with the given
+// name or def when it's not found or is empty.
+func (c *Ctx) QueryParamDefault(name, def string) string {
+
+	if v := c.QueryParam(name); v != blank {
+		return v
+	}
+
+	return def
+}
+
+// QueryInt returns the query param with the given name parsed as an int, an
+// error is returned when it's missing or can't be parsed.
+func (c *Ctx) QueryInt(name string) (int, error) {
+	return strconv.Atoi(c.QueryParam(name))
+}
+
+// QueryIntDefault returns the query param with the given name parsed as an
+// int or def when it's missing or can't be parsed.
+func (c *Ctx) QueryIntDefault(name string, def int) int {
+
+	if i, err := c.QueryInt(name); err == nil {
+		return i
+	}
+
+	return def
+}
+
+// QueryInt64 returns the query param with the given name parsed as an int64,
+// an error is returned when it's missing or can't be parsed.
+func (c *Ctx) QueryInt64(name string) (int64, error) {
+	return strconv.ParseInt(c.QueryParam(name), 10, 64)
+}
+
+// QueryInt64Default returns the query param with the given name parsed as an
+// int64 or def when it's missing or can't be parsed.
+func (c *Ctx) QueryInt64Default(name string, def int64) int64 {
+
+	if i, err := c.QueryInt64(name); err == nil {
+		return i
+	}
+
+	return def
+}
+
+// QueryBool returns the query param with the given name parsed as a bool, any
+// value accepted by strconv.ParseBool is valid i.e. 1, t, true, 0, f, false;
+// an error is returned when it's missing or can't be parsed.
+func (c *Ctx) QueryBool(name string) (bool, error) {
+	return strconv.ParseBool(c.QueryParam(name))
+}
+
+// QueryBoolDefault returns the query param with the given name parsed as a
+// bool or def when it's missing or can't be parsed.
+func (c *Ctx) QueryBoolDefault(name string, def bool) bool {
+
+	if b, err := c.QueryBool(name); err == nil {
+		return b
+	}
+
+	return def
+}
+
+// QueryFloat returns the query param with the given name parsed as a float64,
+// an error is returned when it's missing or can't be parsed.
+func (c *Ctx) QueryFloat(name string) (float64, error) {
+	return strconv.ParseFloat(c.QueryParam(name), 64)
+}
+
+// QueryFloatDefault returns the query param with the given name parsed as a
+// float64 or def when it's missing or can't be parsed.
+func (c *Ctx) QueryFloatDefault(name string, def float64) float64 {
+
+	if f, err := c.QueryFloat(name); err == nil {
+		return f
+	}
+
+	return def
+}
+
+// QueryMap collects the query params in the form prefix[key]=value into a map
+// i.e. ?filter[status]=active&filter[role]=admin with prefix "filter" returns
+// map[string]string{"status": "active", "role": "admin"}. When a key is
+// sent more than once the first value is used; the cached QueryParams()
+// values are used so the RawQuery is not reparsed.
+func (c *Ctx) QueryMap(prefix string) map[string]string {
+
+	m := make(map[string]string)
+
+	for k, v := range c.QueryParams() {
+
+		if len(k) < len(prefix)+3 || k[:len(prefix)] != prefix || k[len(prefix)] != '[' || k[len(k)-1] != ']' {
+			continue
+		}
+
+		m[k[len(prefix)+1:len(k)-1]] = v[0]
+	}
+
+	return m
+}
+
 // ParseForm calls the underlying http.Request ParseForm
 // but also adds the URL params to the request Form as if
 // they were defined as query params i.e. ?id=13&ok=true but
@@ -103,7 +700,7 @@ func (c *Ctx) ParseForm() error {
 	}
 
 	if err := c.request.ParseForm(); err != nil {
-		return err
+		return requestBodyError(err)
 	}
 
 	for _, entry := range c.params {
@@ -126,7 +723,7 @@ func (c *Ctx) ParseMultipartForm(maxMemory int64) error {
 	}
 
 	if err := c.request.ParseMultipartForm(maxMemory); err != nil {
-		return err
+		return requestBodyError(err)
 	}
 
 	for _, entry := range c.params {
@@ -138,6 +735,129 @@ func (c *Ctx) ParseMultipartForm(maxMemory int64) error {
 	return nil
 }
 
+// MultipartReader returns a reader streaming the parts of a multipart/form-data request,
+// so large uploads can be processed, such as copied to storage, as they're received
+// instead of being parsed into memory and temporary files by ParseMultipartForm; it
+// can't be used along with ParseMultipartForm, FormFile or binding the form.
+func (c *Ctx) MultipartReader() (*multipart.Reader, error) {
+	return c.request.MultipartReader()
+}
+
+// FormFile returns the first uploaded file for the provided form name, parsing the
+// multipart form, using ParseMultipartForm, if it hasn't been already.
+// http.ErrMissingFile is returned when no file was uploaded using the name.
+func (c *Ctx) FormFile(name string) (*multipart.FileHeader, error) {
+
+	if err := c.ParseMultipartForm(defaultMultipartMemory); err != nil {
+		return nil, err
+	}
+
+	files := c.request.MultipartForm.File[name]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+
+	return files[0], nil
+}
+
+// SaveUploadedFile saves the uploaded file to dst, creating or truncating it, with
+// permissions readable and writable only by the owner. If the copy fails the partially
+// written dst is removed.
+func (c *Ctx) SaveUploadedFile(file *multipart.FileHeader, dst string) (err error) {
+
+	src, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return
+	}
+
+	if _, err = io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return
+	}
+
+	if err = out.Close(); err != nil {
+		os.Remove(dst)
+	}
+
+	return
+}
+
+// FormFileBytes reads the first uploaded file for the provided form name into memory
+// and returns it's contents along with the content type detected by sniffing those
+// contents; the client provided Content-Type is never trusted. Files larger than the
+// limit set using SetMaxFormFileBytes return ErrFormFileTooLarge.
+func (c *Ctx) FormFileBytes(name string) ([]byte, string, error) {
+
+	fh, err := c.FormFile(name)
+	if err != nil {
+		return nil, blank, err
+	}
+
+	max := c.lars.maxFormFileBytes
+
+	if fh.Size > max {
+		return nil, blank, ErrFormFileTooLarge
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, blank, err
+	}
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, blank, err
+	}
+
+	if int64(len(b)) > max {
+		return nil, blank, ErrFormFileTooLarge
+	}
+
+	return b, http.DetectContentType(b), nil
+}
+
+// BodyLines reads the request body and calls fn for each newline delimited line, without
+// the line ending, as it is read rather than buffering the whole body. Reading stops at
+// the first error returned by fn, which is returned. Lines longer than the limit set using
+// SetMaxBodyLineLength return ErrBodyLineTooLong.
+// NOTE: line is only valid until fn returns, copy it if it needs to be kept.
+func (c *Ctx) BodyLines(fn func(line []byte) error) error {
+
+	size := 4096
+
+	if c.lars.maxBodyLineLength < size {
+		size = c.lars.maxBodyLineLength
+	}
+
+	scanner := bufio.NewScanner(c.request.Body)
+	scanner.Buffer(make([]byte, 0, size), c.lars.maxBodyLineLength)
+
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+
+		if err == bufio.ErrTooLong {
+			return ErrBodyLineTooLong
+		}
+
+		return requestBodyError(err)
+	}
+
+	return nil
+}
+
 // Next should be used only inside middleware.
 // It executes the pending handlers in the chain inside the calling handler.
 // See example in github.
@@ -146,12 +866,110 @@ func (c *Ctx) Next() {
 	c.handlers[c.index](c.parent)
 }
 
-// http response helpers
+// GetString returns the string value for the given key set using Set, the zero
+// value and false are returned when the key is missing or the value isn't a string.
+func (c *Ctx) GetString(key interface{}) (s string, ok bool) {
+
+	if v, exists := c.Get(key); exists {
+		s, ok = v.(string)
+	}
+
+	return
+}
+
+// GetInt returns the int value for the given key set using Set, the zero
+// value and false are returned when the key is missing or the value isn't an int.
+func (c *Ctx) GetInt(key interface{}) (i int, ok bool) {
+
+	if v, exists := c.Get(key); exists {
+		i, ok = v.(int)
+	}
+
+	return
+}
+
+// GetBool returns the bool value for the given key set using Set, the zero
+// value and false are returned when the key is missing or the value isn't a bool.
+func (c *Ctx) GetBool(key interface{}) (b bool, ok bool) {
+
+	if v, exists := c.Get(key); exists {
+		b, ok = v.(bool)
+	}
+
+	return
+}
+
+// NotAcceptable runs the 406 Not Acceptable handlers, registered using Register406,
+// in place of the remaining handlers; used when the response can't be produced in
+// a format acceptable to the client.
+func (c *Ctx) NotAcceptable() {
+
+	handlers, index := c.handlers, c.index
+
+	c.handlers = c.lars.http406
+	c.index = -1
+	c.parent.Next()
+
+	c.handlers, c.index = handlers, index
+}
+
+// notFound runs the 404 Not Found handlers, registered using Register404, in
+// place of the remaining handlers.
+func (c *Ctx) notFound() {
+
+	chain := c.lars.http404
+
+	c.lars.routesMu.RLock()
+
+	h, _, _ := c.lars.matchHost(c.request.Host)
+
+	if g := c.lars.errorGroup(h, c.request.URL.Path, false); g != nil {
+		chain = g.http404
+	}
+
+	c.lars.routesMu.RUnlock()
+
+	c.run(chain)
+}
+
+// run runs chain in place of the remaining handlers.
+func (c *Ctx) run(chain HandlersChain) {
+
+	handlers, index := c.handlers, c.index
+
+	c.handlers = chain
+	c.index = -1
+	c.parent.Next()
+
+	c.handlers, c.index = handlers, index
+}
+
+// http response helpers
+
+// JSON marshals provided interface + returns JSON + status code
+func (c *Ctx) JSON(code int, i interface{}) (err error) {
+
+	if c.lars != nil && c.lars.jsonIndent {
+		return c.JSONIndent(code, i, defaultIndent)
+	}
+
+	b, err := c.jsonCodec().Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	return c.JSONBytes(code, b)
+}
+
+// JSONPretty marshals provided interface, pretty-printed, + returns JSON + status code
+func (c *Ctx) JSONPretty(code int, i interface{}) error {
+	return c.JSONIndent(code, i, defaultIndent)
+}
 
-// JSON marshals provided interface + returns JSON + status code
-func (c *Ctx) JSON(code int, i interface{}) (err error) {
+// JSONIndent marshals provided interface using the provided indent + returns JSON + status code
+func (c *Ctx) JSONIndent(code int, i interface{}, indent string) error {
 
-	b, err := json.Marshal(i)
+	b, err := c.jsonCodec().MarshalIndent(i, blank, indent)
 	if err != nil {
 		return err
 	}
@@ -163,38 +981,49 @@ func (c *Ctx) JSON(code int, i interface{}) (err error) {
 func (c *Ctx) JSONBytes(code int, b []byte) (err error) {
 
 	c.response.Header().Set(ContentType, ApplicationJSONCharsetUTF8)
-	c.response.WriteHeader(code)
-	_, err = c.response.Write(b)
-	return
+	return c.writeBody(code, b)
+}
+
+// JSONRaw returns the provided, already marshaled, JSON response with status code
+// without re-marshaling it; a nil or empty RawMessage is written as null.
+func (c *Ctx) JSONRaw(code int, raw json.RawMessage) error {
+
+	if len(raw) == 0 {
+		raw = jsonNull
+	}
+
+	return c.JSONBytes(code, raw)
 }
 
 // JSONP sends a JSONP response with status code and uses `callback` to construct
 // the JSONP payload.
 func (c *Ctx) JSONP(code int, i interface{}, callback string) (err error) {
 
-	b, e := json.Marshal(i)
+	b, e := c.jsonCodec().Marshal(i)
 	if e != nil {
 		err = e
 		return
 	}
 
 	c.response.Header().Set(ContentType, ApplicationJavaScriptCharsetUTF8)
-	c.response.WriteHeader(code)
+	return c.writeBody(code, []byte(callback+"("), b, []byte(");"))
+}
 
-	if _, err = c.response.Write([]byte(callback + "(")); err == nil {
+// XML marshals provided interface + returns XML + status code
+func (c *Ctx) XML(code int, i interface{}) error {
 
-		if _, err = c.response.Write(b); err == nil {
-			_, err = c.response.Write([]byte(");"))
-		}
+	b, err := xml.Marshal(i)
+	if err != nil {
+		return err
 	}
 
-	return
+	return c.XMLBytes(code, b)
 }
 
-// XML marshals provided interface + returns XML + status code
-func (c *Ctx) XML(code int, i interface{}) error {
+// XMLIndent marshals provided interface using the provided indent + returns XML + status code
+func (c *Ctx) XMLIndent(code int, i interface{}, indent string) error {
 
-	b, err := xml.Marshal(i)
+	b, err := xml.MarshalIndent(i, blank, indent)
 	if err != nil {
 		return err
 	}
@@ -206,13 +1035,7 @@ func (c *Ctx) XML(code int, i interface{}) error {
 func (c *Ctx) XMLBytes(code int, b []byte) (err error) {
 
 	c.response.Header().Set(ContentType, ApplicationXMLCharsetUTF8)
-	c.response.WriteHeader(code)
-
-	if _, err = c.response.Write([]byte(xml.Header)); err == nil {
-		_, err = c.response.Write(b)
-	}
-
-	return
+	return c.writeBody(code, xmlHeader, b)
 }
 
 // Text returns the provided string with status code
@@ -224,17 +1047,116 @@ func (c *Ctx) Text(code int, s string) error {
 func (c *Ctx) TextBytes(code int, b []byte) (err error) {
 
 	c.response.Header().Set(ContentType, TextPlainCharsetUTF8)
+	return c.writeBody(code, b)
+}
+
+// writeBody writes the provided body parts, whose combined size is known, with status
+// code. Bodies within the stream threshold are written with a Content-Length in one
+// go, larger bodies are streamed and sent chunked.
+func (c *Ctx) writeBody(code int, parts ...[]byte) (err error) {
+
+	var size int
+
+	for _, p := range parts {
+		size += len(p)
+	}
+
+	if c.fixedLength(size) {
+		c.response.Header().Set(ContentLength, strconv.Itoa(size))
+	}
+
+	c.response.WriteHeader(code)
+
+	for _, p := range parts {
+		if _, err = c.response.Write(p); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// writeReader writes the contents of r, whose size is unknown, with status code.
+// Up to the stream threshold is buffered and when r is exhausted within it the
+// body is written with a Content-Length, otherwise the body is streamed.
+func (c *Ctx) writeReader(code int, r io.Reader) (err error) {
+
+	threshold := c.streamThreshold()
+
+	if threshold <= 0 {
+		c.response.WriteHeader(code)
+		_, err = io.Copy(c.response, r)
+		return
+	}
+
+	buff := bufferPool.Get().(*bytes.Buffer)
+	buff.Reset()
+
+	defer bufferPool.Put(buff)
+
+	if _, err = io.CopyN(buff, r, int64(threshold)+1); err != nil && err != io.EOF {
+		return
+	}
+
+	if err == io.EOF {
+		return c.writeBody(code, buff.Bytes())
+	}
+
 	c.response.WriteHeader(code)
-	_, err = c.response.Write(b)
+
+	if _, err = c.response.Write(buff.Bytes()); err != nil {
+		return
+	}
+
+	_, err = io.Copy(c.response, r)
+
 	return
 }
 
+// fixedLength returns whether a body of the given size should be written
+// with a Content-Length rather than being streamed.
+func (c *Ctx) fixedLength(size int) bool {
+
+	// the Content-Length of an encoded, i.e. compressed, body is unknown until written
+	if c.response.Header().Get(ContentEncoding) != blank {
+		return false
+	}
+
+	return size <= c.streamThreshold()
+}
+
+func (c *Ctx) streamThreshold() int {
+
+	if c.lars == nil {
+		return defaultStreamThreshold
+	}
+
+	return c.lars.streamThreshold
+}
+
 // http request helpers
 
+// RequestID returns the request's ID, set by the request ID middleware, so logs across
+// services can be correlated; blank when there's none.
+func (c *Ctx) RequestID() string {
+	return c.requestID
+}
+
+// SetRequestID sets the request's ID, included in the entries logged using Log by the
+// default LogSink.
+func (c *Ctx) SetRequestID(id string) {
+	c.requestID = id
+}
+
 // ClientIP implements a best effort algorithm to return the real client IP, it parses
 // X-Real-IP and X-Forwarded-For in order to work properly with reverse-proxies such us: nginx or haproxy.
+// NOTE: the headers are trusted blindly unless trusted proxies are configured using SetTrustedProxies
 func (c *Ctx) ClientIP() (clientIP string) {
 
+	if c.lars != nil && len(c.lars.trustedProxies) > 0 {
+		return c.trustedClientIP()
+	}
+
 	var values []string
 
 	if values, _ = c.request.Header[XRealIP]; len(values) > 0 {
@@ -263,8 +1185,70 @@ func (c *Ctx) ClientIP() (clientIP string) {
 	return
 }
 
+// SecureClientIP returns the client IP for access control and rate limiting, which
+// unlike ClientIP can't be spoofed; the X-Real-IP and X-Forwarded-For headers are only
+// honored when the request was received from a proxy trusted using SetTrustedProxies,
+// without any configured the peer address from RemoteAddr is returned.
+func (c *Ctx) SecureClientIP() string {
+
+	if c.lars != nil && len(c.lars.trustedProxies) > 0 {
+		return c.trustedClientIP()
+	}
+
+	remoteIP, _, err := net.SplitHostPort(strings.TrimSpace(c.request.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(c.request.RemoteAddr)
+	}
+
+	return remoteIP
+}
+
+// trustedClientIP returns the client IP honoring the forwarding headers
+// only when the request was received from a trusted proxy.
+func (c *Ctx) trustedClientIP() (clientIP string) {
+
+	remoteIP, _, err := net.SplitHostPort(strings.TrimSpace(c.request.RemoteAddr))
+	if err != nil {
+		remoteIP = strings.TrimSpace(c.request.RemoteAddr)
+	}
+
+	if !c.lars.isTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+
+	if values := c.request.Header[XRealIP]; len(values) > 0 {
+
+		if clientIP = strings.TrimSpace(values[0]); clientIP != blank {
+			return
+		}
+	}
+
+	if values := c.request.Header[XForwardedFor]; len(values) > 0 {
+
+		hops := strings.Split(strings.Join(values, ","), ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+
+			if clientIP = strings.TrimSpace(hops[i]); clientIP == blank {
+				continue
+			}
+
+			if !c.lars.isTrustedProxy(clientIP) {
+				return
+			}
+		}
+
+		// every hop is a trusted proxy, the leftmost is the closest to the client
+		if clientIP != blank {
+			return
+		}
+	}
+
+	return remoteIP
+}
+
 // AcceptedLanguages returns an array of accepted languages denoted by
-// the Accept-Language header sent by the browser
+// the Accept-Language header sent by the browser, sorted by descending quality
 // NOTE: some stupid browsers send in locales lowercase when all the rest send it properly
 func (c *Ctx) AcceptedLanguages(lowercase bool) []string {
 
@@ -274,82 +1258,337 @@ func (c *Ctx) AcceptedLanguages(lowercase bool) []string {
 		return []string{}
 	}
 
-	options := strings.Split(accepted, ",")
-	l := len(options)
+	options := parseAccept(accepted)
+	language := make([]string, len(options))
+
+	for i, o := range options {
+
+		if lowercase {
+			language[i] = strings.ToLower(o.value)
+			continue
+		}
+
+		language[i] = o.value
+	}
+
+	return language
+}
+
+// NegotiateLanguage returns the best language, out of the supported ones, denoted by the
+// Accept-Language header sent by the client taking into account quality values; when a
+// language isn't supported it's prefixes are tried i.e. en-US falls back to en, and then
+// a supported regional variant i.e. en matches en-GB. Languages explicitly refused using
+// q=0 are never chosen and blank is returned when no supported language is acceptable.
+func (c *Ctx) NegotiateLanguage(supported ...string) string {
+
+	var accepted string
+
+	if accepted = c.request.Header.Get(AcceptedLanguage); accepted == blank || len(supported) == 0 {
+		return blank
+	}
+
+	values := parseAccept(accepted)
+
+	refused := func(s string) bool {
+		for _, v := range values {
+			if v.quality == 0 && strings.EqualFold(v.value, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, v := range values {
+
+		if v.quality == 0 {
+			continue
+		}
+
+		if v.value == "*" {
+			for _, s := range supported {
+				if !refused(s) {
+					return s
+				}
+			}
+			continue
+		}
 
-	language := make([]string, l)
+		// en-US-x-foo, en-US, en
+		for tag := v.value; tag != blank; {
+
+			for _, s := range supported {
+				if strings.EqualFold(tag, s) && !refused(s) {
+					return s
+				}
+			}
+
+			i := strings.LastIndexByte(tag, '-')
+			if i == -1 {
+				break
+			}
 
-	if lowercase {
+			tag = tag[:i]
+		}
 
-		for i := 0; i < l; i++ {
-			locale := strings.SplitN(options[i], ";", 2)
-			language[i] = strings.ToLower(strings.Trim(locale[0], " "))
+		for _, s := range supported {
+			if len(s) > len(v.value) && s[len(v.value)] == '-' && strings.EqualFold(s[:len(v.value)], v.value) && !refused(s) {
+				return s
+			}
 		}
-	} else {
+	}
+
+	return blank
+}
+
+// AcceptCharset returns the best charset, out of the supported ones, denoted by the
+// Accept-Charset header sent by the client taking into account quality values;
+// utf-8 is returned when no supported charset is acceptable or none are provided.
+func (c *Ctx) AcceptCharset(supported ...string) string {
+
+	var accepted string
+
+	if accepted = c.request.Header.Get(AcceptCharset); accepted == blank || len(supported) == 0 {
+		return UTF8
+	}
+
+	values := parseAccept(accepted)
 
-		for i := 0; i < l; i++ {
-			locale := strings.SplitN(options[i], ";", 2)
-			language[i] = strings.Trim(locale[0], " ")
+	// charsets explicitly refused using q=0 are never chosen, even by *
+	refused := func(s string) bool {
+		for _, v := range values {
+			if v.quality == 0 && strings.EqualFold(v.value, s) {
+				return true
+			}
 		}
+		return false
 	}
 
-	return language
+	for _, v := range values {
+
+		if v.quality == 0 {
+			continue
+		}
+
+		for _, s := range supported {
+			if (v.value == "*" || strings.EqualFold(v.value, s)) && !refused(s) {
+				return s
+			}
+		}
+	}
+
+	return UTF8
+}
+
+// AcceptEncoding returns the best content coding, out of the supported ones, denoted
+// by the Accept-Encoding header sent by the client taking into account quality values;
+// codings the client values equally are chosen in the order supported, so list them
+// from most to least preferred. Blank is returned when none are acceptable, in which
+// case the response shouldn't be encoded.
+func (c *Ctx) AcceptEncoding(supported ...string) string {
+
+	accepted := c.request.Header.Get(AcceptEncoding)
+	if accepted == blank {
+		return blank
+	}
+
+	values := parseAccept(accepted)
+
+	quality := func(s string) float64 {
+
+		q := -1.0
+
+		for _, v := range values {
+
+			if strings.EqualFold(v.value, s) {
+				return v.quality
+			}
+
+			if v.value == "*" && q == -1 {
+				q = v.quality
+			}
+		}
+
+		return q
+	}
+
+	var best string
+	var bestQ float64
+
+	for _, s := range supported {
+		if q := quality(s); q > bestQ {
+			best, bestQ = s, q
+		}
+	}
+
+	return best
 }
 
 // HandlerName returns the current Contexts final handler's name
 func (c *Ctx) HandlerName() string {
-	return c.handlerName
+
+	if c.route == nil {
+		return blank
+	}
+
+	return c.route.handlerName
+}
+
+// Route returns the current Contexts matched route, nil is returned
+// when no route was matched i.e. 404, 405, trailing slash redirects...
+func (c *Ctx) Route() *Route {
+	return c.route
+}
+
+// RouteMeta returns the matched route's metadata value for key, set using the Route's
+// Meta, and whether it exists; it doesn't when no route was matched.
+func (c *Ctx) RouteMeta(key string) (value interface{}, exists bool) {
+
+	if c.route == nil {
+		return
+	}
+
+	return c.route.MetaValue(key)
+}
+
+// Push initiates an HTTP/2 server push of target, such as the CSS and JS referenced by
+// the page being rendered, so the client has them before it asks; it must be called
+// before the response is written. It's a no-op, returning nil, when the client doesn't
+// support push such as over HTTP/1.1.
+func (c *Ctx) Push(target string, opts *http.PushOptions) error {
+
+	if err := c.response.Push(target, opts); err != http.ErrNotSupported {
+		return err
+	}
+
+	return nil
 }
 
 // Stream provides HTTP Streaming
+// NOTE: streaming stops once a write to the client fails, see StreamErr to be
+// notified of the error
 func (c *Ctx) Stream(step func(w io.Writer) bool) {
-	w := c.response
-	clientGone := w.CloseNotify()
+	c.StreamErr(func(w io.Writer) (bool, error) {
+		return step(w), nil
+	})
+}
+
+// StreamErr provides HTTP Streaming, stopping when step returns false or an
+// error or once a write to the client fails; the error, if any, is returned.
+// Streaming also stops, returning nil, when the client goes away.
+func (c *Ctx) StreamErr(step func(w io.Writer) (bool, error)) error {
+
+	w := &streamWriter{Response: c.response}
+	clientGone := c.response.CloseNotify()
 
 	for {
 		select {
 		case <-clientGone:
-			return
+			return nil
 		default:
-			keepOpen := step(w)
+			keepOpen, err := step(w)
+			if err != nil {
+				return err
+			}
+
+			if w.err != nil {
+				return w.err
+			}
+
 			w.Flush()
+
 			if !keepOpen {
-				return
+				return nil
 			}
 		}
 	}
 }
 
+// streamWriter records the first error writing to the *Response
+// and fails all subsequent writes with it.
+type streamWriter struct {
+	*Response
+	err error
+}
+
+func (w *streamWriter) Write(b []byte) (n int, err error) {
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, w.err = w.Response.Write(b)
+
+	return n, w.err
+}
+
 // Attachment is a helper method for returning an attachement file
 // to be downloaded, if you with to open inline see function
+// NOTE: when r is an io.ReadSeeker, such as an *os.File, Range and If-Range requests
+// are honored, so downloads can be resumed, see ServeContent; the modification time
+// of an *os.File is sent as Last-Modified, validating If-Range.
 func (c *Ctx) Attachment(r io.Reader, filename string) (err error) {
 
 	c.response.Header().Set(ContentDisposition, "attachment;filename="+filename)
 	c.response.Header().Set(ContentType, detectContentType(filename))
-	c.response.WriteHeader(http.StatusOK)
 
-	_, err = io.Copy(c.response, r)
+	if rs, ok := r.(io.ReadSeeker); ok {
+		c.ServeContent(filename, modTime(r), rs)
+		return
+	}
 
-	return
+	return c.writeReader(http.StatusOK, r)
 }
 
 // Inline is a helper method for returning a file inline to
 // be rendered/opened by the browser
+// NOTE: when r is an io.ReadSeeker, such as an *os.File, Range and If-Range requests
+// are honored, so videos can be seeked, see ServeContent; the modification time of
+// an *os.File is sent as Last-Modified, validating If-Range.
 func (c *Ctx) Inline(r io.Reader, filename string) (err error) {
 
 	c.response.Header().Set(ContentDisposition, "inline;filename="+filename)
 	c.response.Header().Set(ContentType, detectContentType(filename))
-	c.response.WriteHeader(http.StatusOK)
 
-	_, err = io.Copy(c.response, r)
+	if rs, ok := r.(io.ReadSeeker); ok {
+		c.ServeContent(filename, modTime(r), rs)
+		return
+	}
 
-	return
+	return c.writeReader(http.StatusOK, r)
+}
+
+// modTime returns the modification time of r when it's a file, such as an *os.File,
+// otherwise the zero time.
+func modTime(r io.Reader) time.Time {
+
+	if f, ok := r.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if fi, err := f.Stat(); err == nil {
+			return fi.ModTime()
+		}
+	}
+
+	return time.Time{}
+}
+
+// ServeContent replies to the request using the content in the provided ReadSeeker,
+// honoring Range requests with a 206 Partial Content, or 416 Requested Range Not
+// Satisfiable, response and conditional requests using modtime; a zero modtime is
+// ignored. The Content-Type, when not already set, is detected from name's extension.
+// It's a thin wrapper around http.ServeContent writing to the *Response.
+func (c *Ctx) ServeContent(name string, modtime time.Time, content io.ReadSeeker) {
+
+	if c.response.Header().Get(ContentType) == blank {
+		c.response.Header().Set(ContentType, detectContentType(name))
+	}
+
+	http.ServeContent(c.response, c.request, name, modtime, content)
 }
 
 // Decode takes the request and attempts to discover it's content type via
 // the http headers and then decode the request body into the provided struct.
 // Example if header was "application/json" would decode using
-// json.NewDecoder(io.LimitReader(c.request.Body, maxMemory)).Decode(v).
+// json.NewDecoder(io.LimitReader(c.request.Body, maxMemory)).Decode(v), using the
+// JSONCodec set using SetJSONCodec.
 func (c *Ctx) Decode(includeFormQueryParams bool, maxMemory int64, v interface{}) (err error) {
 
 	initFormDecoder()
@@ -363,11 +1602,35 @@ func (c *Ctx) Decode(includeFormQueryParams bool, maxMemory int64, v interface{}
 	switch typ {
 
 	case ApplicationJSON:
-		err = json.NewDecoder(io.LimitReader(c.request.Body, maxMemory)).Decode(v)
+		err = c.jsonCodec().Decode(io.LimitReader(c.request.Body, maxMemory), v)
 
 	case ApplicationXML:
 		err = xml.NewDecoder(io.LimitReader(c.request.Body, maxMemory)).Decode(v)
 
+	case ApplicationProtobuf, ApplicationXProtobuf:
+
+		if c.lars.protobufCodec == nil {
+			return ErrUnsupportedMediaType
+		}
+
+		err = c.decodeProtobuf(io.LimitReader(c.request.Body, maxMemory), v)
+
+	case ApplicationYAML, ApplicationXYAML, TextYAML:
+
+		if c.lars.yamlCodec == nil {
+			return ErrUnsupportedMediaType
+		}
+
+		err = c.lars.yamlCodec.Decode(io.LimitReader(c.request.Body, maxMemory), v)
+
+	case ApplicationMsgpack:
+
+		if c.lars.msgpackCodec == nil {
+			return ErrUnsupportedMediaType
+		}
+
+		err = c.lars.msgpackCodec.Decode(io.LimitReader(c.request.Body, maxMemory), v)
+
 	case ApplicationForm:
 
 		if err = c.ParseForm(); err == nil {
@@ -388,5 +1651,10 @@ func (c *Ctx) Decode(includeFormQueryParams bool, maxMemory int64, v interface{}
 			}
 		}
 	}
+
+	if err != nil {
+		err = requestBodyError(err)
+	}
+
 	return
 }
@@ -2,15 +2,23 @@ package lars
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	. "gopkg.in/go-playground/assert.v1"
 )
@@ -147,6 +155,130 @@ func TestDecode(t *testing.T) {
 	Equal(t, test.MultiPartPosted, "value")
 }
 
+func TestMaxRequestBodySize(t *testing.T) {
+
+	type TestStruct struct {
+		Posted string
+	}
+
+	l := New()
+	l.SetMaxRequestBodySize(16)
+	l.Post("/decode", func(c Context) error {
+
+		test := new(TestStruct)
+
+		if err := c.Decode(false, 16<<10, test); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, test.Posted)
+	})
+	l.Post("/form", func(c Context) error {
+
+		if err := c.ParseForm(); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, c.Request().PostForm.Get("Posted"))
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(POST, "/decode", strings.NewReader(`{"Posted":"ok"}`))
+	r.Header.Set(ContentType, ApplicationJSON)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "ok")
+
+	r, _ = http.NewRequest(POST, "/decode", strings.NewReader(`{"Posted":"value too large"}`))
+	r.Header.Set(ContentType, ApplicationJSON)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+
+	r, _ = http.NewRequest(POST, "/form", strings.NewReader("Posted=value+too+large"))
+	r.Header.Set(ContentType, ApplicationForm)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+
+	code, _ := requestMultiPart(POST, "/decode", l)
+	Equal(t, code, http.StatusRequestEntityTooLarge)
+
+	// route limit replaces the global limit
+	l.Post("/upload", func(c Context) error {
+
+		test := new(TestStruct)
+
+		if err := c.Decode(false, 16<<10, test); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, test.Posted)
+	}).BodyLimit(32)
+
+	hf = l.Serve()
+
+	r, _ = http.NewRequest(POST, "/upload", strings.NewReader(`{"Posted":"value too large"}`))
+	r.Header.Set(ContentType, ApplicationJSON)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "value too large")
+
+	r, _ = http.NewRequest(POST, "/upload", strings.NewReader(`{"Posted":"value too large for the route"}`))
+	r.Header.Set(ContentType, ApplicationJSON)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+}
+
+func TestBodyLines(t *testing.T) {
+
+	l := New()
+	l.Post("/ingest", func(c Context) error {
+
+		var lines []string
+
+		err := c.BodyLines(func(line []byte) error {
+
+			if string(line) == "stop" {
+				return NewHTTPError(http.StatusBadRequest, "stopped")
+			}
+
+			lines = append(lines, string(line))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, lines)
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(POST, "/ingest", strings.NewReader("first\r\nsecond\n\nthird"))
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), `["first","second","","third"]`)
+
+	r, _ = http.NewRequest(POST, "/ingest", strings.NewReader("first\nstop\nthird"))
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusBadRequest)
+	Equal(t, w.Body.String(), `{"message":"stopped"}`)
+
+	l.SetMaxBodyLineLength(8)
+
+	r, _ = http.NewRequest(POST, "/ingest", strings.NewReader("short\nthis line is too long\n"))
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+}
+
 func TestStream(t *testing.T) {
 	l := New()
 
@@ -197,6 +329,93 @@ func TestStream(t *testing.T) {
 
 }
 
+type failingWriter struct {
+	*closeNotifyingRecorder
+	writes int
+	failAt int
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+
+	if w.writes++; w.writes >= w.failAt {
+		return 0, errors.New("broken pipe")
+	}
+
+	return w.closeNotifyingRecorder.Write(b)
+}
+
+func TestStreamErr(t *testing.T) {
+
+	var steps int
+	var streamErr error
+
+	l := New()
+	l.Get("/stream", func(c Context) {
+		c.Stream(func(w io.Writer) bool {
+			steps++
+			w.Write([]byte("a"))
+			return true
+		})
+	})
+	l.Get("/stream-err", func(c Context) {
+		streamErr = c.StreamErr(func(w io.Writer) (bool, error) {
+			steps++
+			_, err := w.Write([]byte("a"))
+			return true, err
+		})
+	})
+	l.Get("/step-err", func(c Context) {
+		streamErr = c.StreamErr(func(w io.Writer) (bool, error) {
+			if steps++; steps == 3 {
+				return true, errors.New("step failed")
+			}
+
+			w.Write([]byte("a"))
+			return true, nil
+		})
+	})
+	l.Get("/done", func(c Context) {
+		streamErr = c.StreamErr(func(w io.Writer) (bool, error) {
+			w.Write([]byte("a"))
+			steps++
+			return steps != 2, nil
+		})
+	})
+
+	serve := func(path string) *failingWriter {
+		r, _ := http.NewRequest(GET, path, nil)
+		w := &failingWriter{
+			closeNotifyingRecorder: &closeNotifyingRecorder{httptest.NewRecorder(), make(chan bool, 1)},
+			failAt:                 4,
+		}
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	// the step func ignores the write error but streaming still stops
+	w := serve("/stream")
+	Equal(t, steps, 4)
+	Equal(t, w.Body.String(), "aaa")
+
+	steps = 0
+	w = serve("/stream-err")
+	Equal(t, steps, 4)
+	Equal(t, w.Body.String(), "aaa")
+	Equal(t, streamErr.Error(), "broken pipe")
+
+	steps = 0
+	w = serve("/step-err")
+	Equal(t, steps, 3)
+	Equal(t, w.Body.String(), "aa")
+	Equal(t, streamErr.Error(), "step failed")
+
+	steps = 0
+	w = serve("/done")
+	Equal(t, steps, 2)
+	Equal(t, w.Body.String(), "aa")
+	Equal(t, streamErr, nil)
+}
+
 func HandlerForName(c Context) {
 	if _, err := c.Response().Write([]byte(c.HandlerName())); err != nil {
 		panic(err)
@@ -226,6 +445,186 @@ func TestQueryParams(t *testing.T) {
 	Equal(t, body, "test=true&test2=true")
 }
 
+func TestQueryMap(t *testing.T) {
+
+	l := New()
+	l.Get("/users", func(c Context) {
+		c.JSON(http.StatusOK, c.QueryMap("filter"))
+	})
+
+	code, body := request(GET, "/users?filter[status]=active&filter[role]=admin&filter[role]=user&filter[]=x&filters[a]=b&filter=c&sort[name]=asc", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, `{"role":"admin","status":"active"}`)
+
+	code, body = request(GET, "/users", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, `{}`)
+}
+
+func TestTypedParams(t *testing.T) {
+
+	l := New()
+	l.Get("/users/:id/:active/:uuid", func(c Context) error {
+
+		id, err := c.ParamInt("id")
+		if err != nil {
+			return err
+		}
+
+		id64, err := c.ParamInt64("id")
+		Equal(t, err, nil)
+		Equal(t, id64, int64(id))
+
+		active, err := c.ParamBool("active")
+		if err != nil {
+			return err
+		}
+
+		uuid, err := c.ParamUUID("uuid")
+		if err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, strconv.Itoa(id)+","+strconv.FormatBool(active)+","+uuid)
+	})
+	l.Get("/defaults/:id", func(c Context) error {
+
+		_, err := c.ParamInt("missing")
+		Equal(t, err.Error(), "code=400, message=invalid param 'missing', internal=strconv.Atoi: parsing \"\": invalid syntax")
+
+		_, err = c.ParamUUID("id")
+		Equal(t, err.Error(), "code=400, message=invalid param 'id', internal=\"abc\" is not a valid UUID")
+
+		Equal(t, c.ParamIntDefault("id", 7), 7)
+		Equal(t, c.ParamInt64Default("id", 8), int64(8))
+		Equal(t, c.ParamBoolDefault("id", true), true)
+
+		c.Response().WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	code, body := request(GET, "/users/13/true/6BA7B810-9DAD-11D1-80B4-00C04FD430C8", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "13,true,6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
+	code, body = request(GET, "/users/abc/true/6ba7b810-9dad-11d1-80b4-00c04fd430c8", l)
+	Equal(t, code, http.StatusBadRequest)
+	Equal(t, body, `{"message":"invalid param 'id'"}`)
+
+	code, body = request(GET, "/users/13/maybe/6ba7b810-9dad-11d1-80b4-00c04fd430c8", l)
+	Equal(t, code, http.StatusBadRequest)
+	Equal(t, body, `{"message":"invalid param 'active'"}`)
+
+	code, body = request(GET, "/users/13/true/6ba7b810", l)
+	Equal(t, code, http.StatusBadRequest)
+	Equal(t, body, `{"message":"invalid param 'uuid'"}`)
+
+	code, _ = request(GET, "/defaults/abc", l)
+	Equal(t, code, http.StatusOK)
+}
+
+func TestSafePath(t *testing.T) {
+
+	root := filepath.Join("var", "www")
+
+	l := New()
+	l.Get("/files/*filepath", func(c Context) error {
+
+		p, err := c.SafePath("filepath", root+string(filepath.Separator))
+		if err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, filepath.ToSlash(p))
+	})
+
+	code, body := request(GET, "/files/css/app.css", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "var/www/css/app.css")
+
+	code, body = request(GET, "/files/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "var/www")
+
+	code, body = request(GET, "/files/css/../js/app.js", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "var/www/js/app.js")
+
+	code, body = request(GET, "/files/..", l)
+	Equal(t, code, http.StatusBadRequest)
+	Equal(t, body, `{"message":"invalid param 'filepath'"}`)
+
+	code, _ = request(GET, "/files/css/../../../etc/passwd", l)
+	Equal(t, code, http.StatusBadRequest)
+
+	code, _ = request(GET, "/files/..%2fsecret", l)
+	Equal(t, code, http.StatusBadRequest)
+
+	code, body = request(GET, "/files/..secret", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "var/www/..secret")
+
+	c := NewContext(l)
+	c.params = Params{{Key: "filepath", Value: "a\x00b"}}
+
+	_, err := c.SafePath("filepath", root)
+	Equal(t, err.Error(), "code=400, message=invalid param 'filepath', internal=contains a NUL byte")
+
+	c.params = Params{{Key: "filepath", Value: "../www2/secret"}}
+
+	_, err = c.SafePath("filepath", root)
+	Equal(t, err.Error(), "code=400, message=invalid param 'filepath', internal=\"../www2/secret\" is outside of the root directory")
+}
+
+func TestTypedQueryParams(t *testing.T) {
+
+	l := New()
+	l.Get("/users", func(c Context) {
+
+		page, err := c.QueryInt("page")
+		Equal(t, err, nil)
+		Equal(t, page, 2)
+
+		_, err = c.QueryInt("missing")
+		NotEqual(t, err, nil)
+
+		_, err = c.QueryInt("name")
+		NotEqual(t, err, nil)
+
+		id, err := c.QueryInt64("id")
+		Equal(t, err, nil)
+		Equal(t, id, int64(9007199254740993))
+
+		active, err := c.QueryBool("active")
+		Equal(t, err, nil)
+		Equal(t, active, true)
+
+		_, err = c.QueryBool("name")
+		NotEqual(t, err, nil)
+
+		score, err := c.QueryFloat("score")
+		Equal(t, err, nil)
+		Equal(t, score, 4.5)
+
+		Equal(t, c.QueryParam("name"), "joeybloggs")
+		Equal(t, c.QueryParam("missing"), "")
+		Equal(t, c.QueryParamDefault("name", "default"), "joeybloggs")
+		Equal(t, c.QueryParamDefault("empty", "default"), "default")
+		Equal(t, c.QueryIntDefault("page", 1), 2)
+		Equal(t, c.QueryIntDefault("name", 1), 1)
+		Equal(t, c.QueryInt64Default("missing", 10), int64(10))
+		Equal(t, c.QueryBoolDefault("missing", true), true)
+		Equal(t, c.QueryBoolDefault("active", false), true)
+		Equal(t, c.QueryFloatDefault("name", 1.5), 1.5)
+		Equal(t, c.QueryFloatDefault("score", 1.5), 4.5)
+
+		c.Response().WriteHeader(http.StatusOK)
+	})
+
+	code, _ := request(GET, "/users?page=2&id=9007199254740993&active=true&score=4.5&name=joeybloggs&empty=", l)
+	Equal(t, code, http.StatusOK)
+}
+
 func TestNativeHandlersAndParseForm(t *testing.T) {
 
 	l := New()
@@ -461,80 +860,446 @@ func TestNativeHandlersAndParseMultiPartForm(t *testing.T) {
 	Equal(t, body, "invalid URL escape \"%%e\"")
 }
 
-func TestClientIP(t *testing.T) {
+func TestFormFileBytes(t *testing.T) {
+
 	l := New()
-	c := NewContext(l)
+	l.Post("/upload", func(c Context) error {
 
-	c.request, _ = http.NewRequest("POST", "/", nil)
+		b, typ, err := c.FormFileBytes("file")
+		if err != nil {
+			return err
+		}
 
-	c.Request().Header.Set("X-Real-IP", " 10.10.10.10  ")
-	c.Request().Header.Set("X-Forwarded-For", "  20.20.20.20, 30.30.30.30")
-	c.Request().RemoteAddr = "  40.40.40.40:42123 "
+		return c.Text(http.StatusOK, typ+" "+string(b))
+	})
+	l.Post("/missing", func(c Context) {
 
-	Equal(t, c.ClientIP(), "10.10.10.10")
+		_, _, err := c.FormFileBytes("avatar")
+		c.Text(http.StatusOK, err.Error())
+	})
 
-	c.Request().Header.Del("X-Real-IP")
-	Equal(t, c.ClientIP(), "20.20.20.20")
+	code, body := requestMultiPart(POST, "/upload", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "text/plain; charset=utf-8 FILE TEST DATA")
 
-	c.Request().Header.Set("X-Forwarded-For", "30.30.30.30  ")
-	Equal(t, c.ClientIP(), "30.30.30.30")
+	code, body = requestMultiPart(POST, "/missing", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, http.ErrMissingFile.Error())
 
-	c.Request().Header.Del("X-Forwarded-For")
-	Equal(t, c.ClientIP(), "40.40.40.40")
+	l.SetMaxFormFileBytes(4)
+
+	code, _ = requestMultiPart(POST, "/upload", l)
+	Equal(t, code, http.StatusRequestEntityTooLarge)
+
+	code, _ = request(POST, "/upload", l)
+	Equal(t, code, http.StatusInternalServerError)
 }
 
-func TestAttachment(t *testing.T) {
+func TestSaveUploadedFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "lars")
+	Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	dst := filepath.Join(dir, "upload.txt")
 
 	l := New()
+	l.Post("/upload", func(c Context) error {
 
-	l.Get("/dl", func(c Context) {
-		f, _ := os.Open("logo.png")
-		if err := c.Attachment(f, "logo.png"); err != nil {
-			panic(err)
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return err
 		}
-	})
 
-	l.Get("/dl-unknown-type", func(c Context) {
-		f, _ := os.Open("logo.png")
-		if err := c.Attachment(f, "logo"); err != nil {
-			panic(err)
+		// multipart form is only parsed once
+		_, err = c.FormFile("file")
+		Equal(t, err, nil)
+
+		if err = c.SaveUploadedFile(fh, dst); err != nil {
+			return err
 		}
+
+		return c.Text(http.StatusOK, fh.Filename)
 	})
+	l.Post("/missing", func(c Context) {
 
-	r, _ := http.NewRequest(GET, "/dl", nil)
-	w := &closeNotifyingRecorder{
-		httptest.NewRecorder(),
-		make(chan bool, 1),
-	}
-	hf := l.Serve()
-	hf.ServeHTTP(w, r)
+		_, err := c.FormFile("avatar")
+		c.Text(http.StatusOK, err.Error())
+	})
+	l.Post("/bad-dst", func(c Context) {
 
-	Equal(t, w.Code, http.StatusOK)
-	Equal(t, w.Header().Get(ContentDisposition), "attachment;filename=logo.png")
-	Equal(t, w.Header().Get(ContentType), "image/png")
-	Equal(t, w.Body.Len(), 3041)
+		fh, _ := c.FormFile("file")
+		err := c.SaveUploadedFile(fh, filepath.Join(dir, "missing", "upload.txt"))
+		c.Text(http.StatusOK, strconv.FormatBool(os.IsNotExist(err)))
+	})
 
-	r, _ = http.NewRequest(GET, "/dl-unknown-type", nil)
-	w = &closeNotifyingRecorder{
-		httptest.NewRecorder(),
-		make(chan bool, 1),
-	}
-	hf = l.Serve()
-	hf.ServeHTTP(w, r)
+	code, body := requestMultiPart(POST, "/upload", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "test.txt")
 
-	Equal(t, w.Code, http.StatusOK)
-	Equal(t, w.Header().Get(ContentDisposition), "attachment;filename=logo")
-	Equal(t, w.Header().Get(ContentType), "application/octet-stream")
-	Equal(t, w.Body.Len(), 3041)
+	b, err := ioutil.ReadFile(dst)
+	Equal(t, err, nil)
+	Equal(t, string(b), "FILE TEST DATA")
+
+	fi, err := os.Stat(dst)
+	Equal(t, err, nil)
+	Equal(t, fi.Mode().Perm(), os.FileMode(0600))
+
+	code, body = requestMultiPart(POST, "/missing", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, http.ErrMissingFile.Error())
+
+	code, body = requestMultiPart(POST, "/bad-dst", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "true")
+
+	code, _ = request(POST, "/upload", l)
+	Equal(t, code, http.StatusInternalServerError)
 }
 
-func TestInline(t *testing.T) {
+func TestMultipartReader(t *testing.T) {
 
 	l := New()
-	l.Get("/dl-inline", func(c Context) {
-		f, _ := os.Open("logo.png")
-		if err := c.Inline(f, "logo.png"); err != nil {
-			panic(err)
+	l.Post("/upload", func(c Context) error {
+
+		mr, err := c.MultipartReader()
+		if err != nil {
+			return err
+		}
+
+		var parts []string
+
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+
+			b, err := ioutil.ReadAll(part)
+			if err != nil {
+				return err
+			}
+
+			parts = append(parts, part.FormName()+"="+part.FileName()+":"+string(b))
+		}
+
+		return c.Text(http.StatusOK, strings.Join(parts, ","))
+	})
+	l.Post("/parsed", func(c Context) error {
+
+		if _, err := c.FormFile("file"); err != nil {
+			return err
+		}
+
+		_, err := c.MultipartReader()
+
+		return c.Text(http.StatusOK, strconv.FormatBool(err != nil))
+	})
+
+	code, body := requestMultiPart(POST, "/upload", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "file=test.txt:FILE TEST DATA,username=:joeybloggs")
+
+	code, body = requestMultiPart(POST, "/parsed", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "true")
+
+	// not a multipart request
+	code, _ = request(POST, "/upload", l)
+	Equal(t, code, http.StatusInternalServerError)
+}
+
+func TestClientIP(t *testing.T) {
+	l := New()
+	c := NewContext(l)
+
+	c.request, _ = http.NewRequest("POST", "/", nil)
+
+	c.Request().Header.Set("X-Real-IP", " 10.10.10.10  ")
+	c.Request().Header.Set("X-Forwarded-For", "  20.20.20.20, 30.30.30.30")
+	c.Request().RemoteAddr = "  40.40.40.40:42123 "
+
+	Equal(t, c.ClientIP(), "10.10.10.10")
+
+	c.Request().Header.Del("X-Real-IP")
+	Equal(t, c.ClientIP(), "20.20.20.20")
+
+	c.Request().Header.Set("X-Forwarded-For", "30.30.30.30  ")
+	Equal(t, c.ClientIP(), "30.30.30.30")
+
+	c.Request().Header.Del("X-Forwarded-For")
+	Equal(t, c.ClientIP(), "40.40.40.40")
+
+	// the headers are ignored without trusted proxies
+	c.Request().Header.Set("X-Real-IP", "10.10.10.10")
+	c.Request().Header.Set("X-Forwarded-For", "20.20.20.20")
+	Equal(t, c.SecureClientIP(), "40.40.40.40")
+
+	c.Request().RemoteAddr = "invalid"
+	Equal(t, c.SecureClientIP(), "invalid")
+}
+
+func TestClientIPTrustedProxies(t *testing.T) {
+
+	PanicMatches(t, func() { New().SetTrustedProxies("bad") }, "invalid trusted proxy 'bad'")
+	PanicMatches(t, func() { New().SetTrustedProxies("10.0.0.0/99") }, "invalid trusted proxy '10.0.0.0/99': invalid CIDR address: 10.0.0.0/99")
+
+	l := New()
+	l.SetTrustedProxies("10.0.0.0/8", "192.168.1.1", "::1")
+	c := NewContext(l)
+
+	c.request, _ = http.NewRequest("POST", "/", nil)
+
+	// not received from a trusted proxy, headers are ignored
+	c.Request().Header.Set("X-Real-IP", "1.1.1.1")
+	c.Request().Header.Set("X-Forwarded-For", "2.2.2.2")
+	c.Request().RemoteAddr = "40.40.40.40:42123"
+	Equal(t, c.ClientIP(), "40.40.40.40")
+
+	Equal(t, c.SecureClientIP(), "40.40.40.40")
+
+	c.Request().RemoteAddr = "10.1.1.1:42123"
+	Equal(t, c.ClientIP(), "1.1.1.1")
+	Equal(t, c.SecureClientIP(), "1.1.1.1")
+
+	c.Request().Header.Del("X-Real-IP")
+	Equal(t, c.ClientIP(), "2.2.2.2")
+
+	// spoofed leftmost value is skipped
+	c.Request().Header.Set("X-Forwarded-For", "6.6.6.6, 3.3.3.3, 10.2.2.2")
+	Equal(t, c.ClientIP(), "3.3.3.3")
+
+	c.Request().Header.Set("X-Forwarded-For", "6.6.6.6")
+	c.Request().Header.Add("X-Forwarded-For", "3.3.3.3, 192.168.1.1")
+	Equal(t, c.ClientIP(), "3.3.3.3")
+
+	c.Request().Header.Set("X-Forwarded-For", "10.3.3.3, 10.2.2.2")
+	Equal(t, c.ClientIP(), "10.3.3.3")
+
+	c.Request().Header.Del("X-Forwarded-For")
+	Equal(t, c.ClientIP(), "10.1.1.1")
+
+	c.Request().Header.Set("X-Forwarded-For", "2.2.2.2")
+	c.Request().RemoteAddr = "[::1]:42123"
+	Equal(t, c.ClientIP(), "2.2.2.2")
+
+	c.Request().RemoteAddr = "[::2]:42123"
+	Equal(t, c.ClientIP(), "::2")
+}
+
+func TestAttachment(t *testing.T) {
+
+	l := New()
+
+	l.Get("/dl", func(c Context) {
+		f, _ := os.Open("logo.png")
+		if err := c.Attachment(f, "logo.png"); err != nil {
+			panic(err)
+		}
+	})
+
+	l.Get("/dl-unknown-type", func(c Context) {
+		f, _ := os.Open("logo.png")
+		if err := c.Attachment(f, "logo"); err != nil {
+			panic(err)
+		}
+	})
+
+	r, _ := http.NewRequest(GET, "/dl", nil)
+	w := &closeNotifyingRecorder{
+		httptest.NewRecorder(),
+		make(chan bool, 1),
+	}
+	hf := l.Serve()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentDisposition), "attachment;filename=logo.png")
+	Equal(t, w.Header().Get(ContentType), "image/png")
+	Equal(t, w.Body.Len(), 3041)
+
+	r, _ = http.NewRequest(GET, "/dl-unknown-type", nil)
+	w = &closeNotifyingRecorder{
+		httptest.NewRecorder(),
+		make(chan bool, 1),
+	}
+	hf = l.Serve()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentDisposition), "attachment;filename=logo")
+	Equal(t, w.Header().Get(ContentType), "application/octet-stream")
+	Equal(t, w.Body.Len(), 3041)
+}
+
+func TestAttachmentRange(t *testing.T) {
+
+	l := New()
+	l.Get("/dl", func(c Context) {
+		f, _ := os.Open("logo.png")
+		defer f.Close()
+
+		if err := c.Attachment(f, "logo.png"); err != nil {
+			panic(err)
+		}
+	})
+	l.Get("/dl-inline", func(c Context) {
+		f, _ := os.Open("logo.png")
+		defer f.Close()
+
+		if err := c.Inline(f, "logo.png"); err != nil {
+			panic(err)
+		}
+	})
+	l.Get("/serve", func(c Context) {
+		c.ServeContent("file.txt", time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC), strings.NewReader("0123456789"))
+	})
+	l.Get("/dl-reader", func(c Context) error {
+		return c.Attachment(strings.NewReader("0123456789"), "file.txt")
+	})
+	l.Get("/dl-etag", func(c Context) error {
+		c.Response().Header().Set("ETag", `"v1"`)
+		return c.Inline(strings.NewReader("0123456789"), "file.txt")
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(GET, "/dl", nil)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("Accept-Ranges"), "bytes")
+	Equal(t, w.Header().Get(ContentLength), "3041")
+	Equal(t, w.Body.Len(), 3041)
+
+	r, _ = http.NewRequest(GET, "/dl", nil)
+	r.Header.Set("Range", "bytes=0-9")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Header().Get(ContentDisposition), "attachment;filename=logo.png")
+	Equal(t, w.Header().Get(ContentType), "image/png")
+	Equal(t, w.Header().Get("Content-Range"), "bytes 0-9/3041")
+	Equal(t, w.Body.Len(), 10)
+
+	r, _ = http.NewRequest(GET, "/dl-inline", nil)
+	r.Header.Set("Range", "bytes=3031-")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Header().Get(ContentDisposition), "inline;filename=logo.png")
+	Equal(t, w.Header().Get("Content-Range"), "bytes 3031-3040/3041")
+	Equal(t, w.Body.Len(), 10)
+
+	r, _ = http.NewRequest(GET, "/dl", nil)
+	r.Header.Set("Range", "bytes=5000-")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusRequestedRangeNotSatisfiable)
+	Equal(t, w.Header().Get("Content-Range"), "bytes */3041")
+
+	fi, _ := os.Stat("logo.png")
+	lastModified := fi.ModTime().UTC().Format(http.TimeFormat)
+
+	r, _ = http.NewRequest(GET, "/dl", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("Last-Modified"), lastModified)
+
+	// resuming the download of an unchanged file
+	r, _ = http.NewRequest(GET, "/dl", nil)
+	r.Header.Set("Range", "bytes=3000-")
+	r.Header.Set("If-Range", lastModified)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Header().Get("Content-Range"), "bytes 3000-3040/3041")
+	Equal(t, w.Body.Len(), 41)
+
+	// the file changed since, the full file is sent
+	r, _ = http.NewRequest(GET, "/dl", nil)
+	r.Header.Set("Range", "bytes=3000-")
+	r.Header.Set("If-Range", "Sat, 02 Jan 2016 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.Len(), 3041)
+
+	// readers without a modification time can't be validated, the full content is sent
+	r, _ = http.NewRequest(GET, "/dl-reader", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	r.Header.Set("If-Range", "Sat, 02 Jan 2016 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("Last-Modified"), "")
+	Equal(t, w.Body.String(), "0123456789")
+
+	r, _ = http.NewRequest(GET, "/dl-reader", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Body.String(), "234")
+
+	// validated using the ETag
+	r, _ = http.NewRequest(GET, "/dl-etag", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	r.Header.Set("If-Range", `"v1"`)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Header().Get("Content-Range"), "bytes 2-4/10")
+	Equal(t, w.Body.String(), "234")
+
+	r, _ = http.NewRequest(GET, "/dl-etag", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	r.Header.Set("If-Range", `"v0"`)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "0123456789")
+
+	r, _ = http.NewRequest(GET, "/serve", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Header().Get(ContentType), TextPlainCharsetUTF8)
+	Equal(t, w.Body.String(), "234")
+
+	r, _ = http.NewRequest(GET, "/serve", nil)
+	r.Header.Set("If-Modified-Since", "Sat, 02 Jan 2016 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusNotModified)
+}
+
+func TestInline(t *testing.T) {
+
+	l := New()
+	l.Get("/dl-inline", func(c Context) {
+		f, _ := os.Open("logo.png")
+		if err := c.Inline(f, "logo.png"); err != nil {
+			panic(err)
 		}
 	})
 
@@ -601,6 +1366,43 @@ func TestAcceptedLanguages(t *testing.T) {
 	languages = c.AcceptedLanguages(false)
 
 	Equal(t, languages, []string{})
+
+	c.Request().Header.Set(AcceptedLanguage, "en;q=0.5, fr;q=0.9")
+	Equal(t, c.AcceptedLanguages(false), []string{"fr", "en"})
+
+	// absent q defaults to 1.0, malformed q is treated as 1.0 and ties keep header order
+	c.Request().Header.Set(AcceptedLanguage, "de;q=0.1, en-US;q=bad, fr, ja;q=0.8,")
+	Equal(t, c.AcceptedLanguages(false), []string{"en-US", "fr", "ja", "de"})
+	Equal(t, c.AcceptedLanguages(true), []string{"en-us", "fr", "ja", "de"})
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	l := New()
+	c := NewContext(l)
+
+	c.request, _ = http.NewRequest("GET", "/", nil)
+
+	Equal(t, c.NegotiateLanguage("en", "fr"), "")
+
+	c.Request().Header.Set(AcceptedLanguage, "fr-CA;q=0.8, en-US, de;q=0.9")
+
+	Equal(t, c.NegotiateLanguage(), "")
+	Equal(t, c.NegotiateLanguage("fr", "de", "EN-us"), "EN-us")
+	Equal(t, c.NegotiateLanguage("fr", "de", "en"), "en")
+	Equal(t, c.NegotiateLanguage("fr", "de"), "de")
+	Equal(t, c.NegotiateLanguage("fr", "ja"), "fr")
+	Equal(t, c.NegotiateLanguage("ja"), "")
+
+	// regional variants of an accepted language
+	c.Request().Header.Set(AcceptedLanguage, "en, ja;q=0.5")
+	Equal(t, c.NegotiateLanguage("ja", "en-GB"), "en-GB")
+	Equal(t, c.NegotiateLanguage("ja", "eng"), "ja")
+
+	// wildcard and refused languages
+	c.Request().Header.Set(AcceptedLanguage, "ja, *;q=0.5, fr;q=0")
+	Equal(t, c.NegotiateLanguage("fr", "en"), "en")
+	Equal(t, c.NegotiateLanguage("fr", "ja"), "ja")
+	Equal(t, c.NegotiateLanguage("fr"), "")
 }
 
 type zombie struct {
@@ -608,6 +1410,55 @@ type zombie struct {
 	Name string `json:"name" xml:"name"`
 }
 
+func TestAcceptEncoding(t *testing.T) {
+	l := New()
+	c := NewContext(l)
+
+	c.request, _ = http.NewRequest("GET", "/", nil)
+
+	Equal(t, c.AcceptEncoding(Brotli, Gzip), "")
+
+	c.Request().Header.Set(AcceptEncoding, "gzip, deflate, br")
+	Equal(t, c.AcceptEncoding(Brotli, Gzip), Brotli)
+	Equal(t, c.AcceptEncoding(Gzip, Brotli), Gzip)
+	Equal(t, c.AcceptEncoding("zstd"), "")
+	Equal(t, c.AcceptEncoding(), "")
+
+	c.Request().Header.Set(AcceptEncoding, "gzip;q=1.0, br;q=0.8")
+	Equal(t, c.AcceptEncoding(Brotli, Gzip), Gzip)
+
+	c.Request().Header.Set(AcceptEncoding, "br;q=0, *;q=0.5")
+	Equal(t, c.AcceptEncoding(Brotli, Gzip), Gzip)
+
+	c.Request().Header.Set(AcceptEncoding, "identity")
+	Equal(t, c.AcceptEncoding(Brotli, Gzip), "")
+}
+
+func TestAcceptCharset(t *testing.T) {
+	l := New()
+	c := NewContext(l)
+
+	c.request, _ = http.NewRequest("POST", "/", nil)
+
+	Equal(t, c.AcceptCharset("iso-8859-1"), UTF8)
+
+	c.Request().Header.Set(AcceptCharset, "iso-8859-1;q=0.5, windows-1252;q=0.9, utf-8;q=0.1")
+
+	Equal(t, c.AcceptCharset(), UTF8)
+	Equal(t, c.AcceptCharset("utf-8", "ISO-8859-1"), "ISO-8859-1")
+	Equal(t, c.AcceptCharset("utf-8", "iso-8859-1", "windows-1252"), "windows-1252")
+	Equal(t, c.AcceptCharset("shift_jis"), UTF8)
+
+	c.Request().Header.Set(AcceptCharset, "utf-8;q=0, *;q=0.3")
+	Equal(t, c.AcceptCharset("utf-8", "iso-8859-1"), "iso-8859-1")
+
+	c.Request().Header.Set(AcceptCharset, "*")
+	Equal(t, c.AcceptCharset("utf-8", "iso-8859-1"), "utf-8")
+
+	c.Request().Header.Set(AcceptCharset, "utf-8;q=0, iso-8859-1;q=bad")
+	Equal(t, c.AcceptCharset("utf-8", "iso-8859-1"), "iso-8859-1")
+}
+
 func TestXML(t *testing.T) {
 	xmlData := `<zombie><id>1</id><name>Patient Zero</name></zombie>`
 
@@ -703,6 +1554,166 @@ func TestJSON(t *testing.T) {
 	Equal(t, w.Body.String(), "json: unsupported type: func()\n")
 }
 
+func TestTypedGet(t *testing.T) {
+
+	l := New()
+	c := NewContext(l)
+	c.request, _ = http.NewRequest(GET, "/", nil)
+
+	c.Set("name", "joeybloggs")
+	c.Set("age", 32)
+	c.Set("admin", true)
+
+	s, ok := c.GetString("name")
+	Equal(t, ok, true)
+	Equal(t, s, "joeybloggs")
+
+	i, ok := c.GetInt("age")
+	Equal(t, ok, true)
+	Equal(t, i, 32)
+
+	b, ok := c.GetBool("admin")
+	Equal(t, ok, true)
+	Equal(t, b, true)
+
+	// wrong type
+	s, ok = c.GetString("age")
+	Equal(t, ok, false)
+	Equal(t, s, "")
+
+	i, ok = c.GetInt("name")
+	Equal(t, ok, false)
+	Equal(t, i, 0)
+
+	b, ok = c.GetBool("name")
+	Equal(t, ok, false)
+	Equal(t, b, false)
+
+	// missing
+	s, ok = c.GetString("missing")
+	Equal(t, ok, false)
+	Equal(t, s, "")
+
+	i, ok = c.GetInt("missing")
+	Equal(t, ok, false)
+	Equal(t, i, 0)
+
+	b, ok = c.GetBool("missing")
+	Equal(t, ok, false)
+	Equal(t, b, false)
+}
+
+func TestIndent(t *testing.T) {
+
+	l := New()
+	l.Get("/json", func(c Context) {
+		if err := c.JSONIndent(http.StatusOK, zombie{1, "Patient Zero"}, "\t"); err != nil {
+			panic(err)
+		}
+	})
+	l.Get("/badjson", func(c Context) {
+		if err := c.JSONIndent(http.StatusOK, func() {}, "\t"); err != nil {
+			http.Error(c.Response(), err.Error(), http.StatusInternalServerError)
+		}
+	})
+	l.Get("/xml", func(c Context) {
+		if err := c.XMLIndent(http.StatusOK, zombie{1, "Patient Zero"}, "\t"); err != nil {
+			panic(err)
+		}
+	})
+	l.Get("/badxml", func(c Context) {
+		if err := c.XMLIndent(http.StatusOK, func() {}, "\t"); err != nil {
+			http.Error(c.Response(), err.Error(), http.StatusInternalServerError)
+		}
+	})
+	l.Get("/plain", func(c Context) {
+		if err := c.JSON(http.StatusOK, zombie{1, "Patient Zero"}); err != nil {
+			panic(err)
+		}
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(GET, "/json", nil)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), "{\n\t\"id\": 1,\n\t\"name\": \"Patient Zero\"\n}")
+
+	r, _ = http.NewRequest(GET, "/badjson", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusInternalServerError)
+	Equal(t, w.Body.String(), "json: unsupported type: func()\n")
+
+	r, _ = http.NewRequest(GET, "/xml", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationXMLCharsetUTF8)
+	Equal(t, w.Body.String(), xml.Header+"<zombie>\n\t<id>1</id>\n\t<name>Patient Zero</name>\n</zombie>")
+
+	r, _ = http.NewRequest(GET, "/badxml", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusInternalServerError)
+	Equal(t, w.Body.String(), "xml: unsupported type: func()\n")
+
+	r, _ = http.NewRequest(GET, "/plain", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Body.String(), `{"id":1,"name":"Patient Zero"}`)
+
+	l.SetJSONIndent(true)
+
+	r, _ = http.NewRequest(GET, "/plain", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "{\n  \"id\": 1,\n  \"name\": \"Patient Zero\"\n}")
+}
+
+func TestJSONRaw(t *testing.T) {
+	jsonData := `{"id":1,"name":"Patient Zero"}`
+
+	l := New()
+	l.Get("/json", func(c Context) {
+		if err := c.JSONRaw(http.StatusCreated, json.RawMessage(jsonData)); err != nil {
+			panic(err)
+		}
+	})
+	l.Get("/empty", func(c Context) {
+		if err := c.JSONRaw(http.StatusOK, nil); err != nil {
+			panic(err)
+		}
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(GET, "/json", nil)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), jsonData)
+
+	r, _ = http.NewRequest(GET, "/empty", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), "null")
+}
+
 func TestText(t *testing.T) {
 	txtData := `OMG I'm infected! #zombie`
 
@@ -748,3 +1759,298 @@ func TestCachedQueryParams(t *testing.T) {
 	Equal(t, val1, "val1")
 	Equal(t, val2, "val2")
 }
+
+func TestContext(t *testing.T) {
+
+	l := New()
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	c := NewContext(l)
+
+	var varParams []Param
+
+	// Parameter
+	param1 := Param{
+		Key:   "userID",
+		Value: "507f191e810c19729de860ea",
+	}
+
+	varParams = append(varParams, param1)
+	c.params = varParams
+	c.request = r
+
+	//Request
+	NotEqual(t, c.request, nil)
+
+	//Response
+	NotEqual(t, c.response, nil)
+
+	//Paramter by name
+	bsonValue := c.Param("userID")
+	NotEqual(t, len(bsonValue), 0)
+	Equal(t, "507f191e810c19729de860ea", bsonValue)
+
+	//Store
+	ctx := c.Context()
+	ctx = context.WithValue(ctx, "publicKey", "U|ydN3SX)B(hI8SV1R;(")
+	c.WithContext(ctx)
+
+	value, exists := c.Get("publicKey")
+
+	//Get
+	Equal(t, true, exists)
+	Equal(t, "U|ydN3SX)B(hI8SV1R;(", value)
+
+	c.WithValue("User", "Alice")
+	value, exists = c.Value("User").(string)
+	Equal(t, true, exists)
+	Equal(t, "Alice", value)
+
+	value, exists = c.Get("UserName")
+	NotEqual(t, true, exists)
+	NotEqual(t, "Alice", value)
+
+	c.Set("Information", []string{"Alice", "Bob", "40.712784", "-74.005941"})
+
+	value, exists = c.Get("Information")
+	Equal(t, true, exists)
+	vString := value.([]string)
+
+	Equal(t, "Alice", vString[0])
+	Equal(t, "Bob", vString[1])
+	Equal(t, "40.712784", vString[2])
+	Equal(t, "-74.005941", vString[3])
+
+	// Reset
+	c.RequestStart(w, r)
+
+	//Request
+	NotEqual(t, c.request, nil)
+
+	//Response
+	NotEqual(t, c.response, nil)
+
+	//Set
+	Equal(t, c.Value("test"), nil)
+
+	// Index
+	Equal(t, c.index, -1)
+
+	// Handlers
+	Equal(t, c.handlers, nil)
+
+	cancelFunc := c.WithCancel()
+	Equal(t, reflect.TypeOf(cancelFunc).String(), "context.CancelFunc")
+
+	dt := time.Now().Add(time.Minute)
+	cancelFunc = c.WithDeadline(dt)
+	Equal(t, reflect.TypeOf(cancelFunc).String(), "context.CancelFunc")
+
+	cancelFunc = c.WithTimeout(time.Minute)
+	Equal(t, reflect.TypeOf(cancelFunc).String(), "context.CancelFunc")
+
+	deadline, ok := c.Deadline()
+	Equal(t, ok, true)
+	Equal(t, deadline, dt)
+
+	dc := c.Done()
+	Equal(t, reflect.TypeOf(dc).String(), "<-chan struct {}")
+
+	err := c.Err()
+	Equal(t, err, nil)
+}
+
+func TestNativeContext(t *testing.T) {
+
+	var val1, val2 string
+
+	l := New()
+
+	l.Use(func(w http.ResponseWriter, r *http.Request) {
+		*r = *r.WithContext(context.WithValue(r.Context(), 0, "testval1"))
+	})
+	l.Use(func(w http.ResponseWriter, r *http.Request) {
+		*r = *r.WithContext(context.WithValue(r.Context(), 1, "testval2"))
+	})
+	l.Get("/users/:id", func(c Context) {
+		val1 = c.Request().Context().Value(0).(string)
+		val2 = c.Request().Context().Value(1).(string)
+	})
+
+	code, body := request(GET, "/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "")
+
+	Equal(t, val1, "testval1")
+	Equal(t, val2, "testval2")
+}
+
+func TestNativeContextUsingCtx(t *testing.T) {
+
+	var val1, val2 string
+
+	l := New()
+
+	l.Use(func(w http.ResponseWriter, r *http.Request) {
+		c := GetContext(w)
+		c.WithContext(context.WithValue(r.Context(), 0, "testval1"))
+	})
+	l.Use(func(w http.ResponseWriter, r *http.Request) {
+		c := GetContext(w)
+		*r = *c.Request().WithContext(context.WithValue(r.Context(), 1, "testval2"))
+	})
+	l.Get("/users/:id", func(c Context) {
+		val1 = c.Request().Context().Value(0).(string)
+		val2 = c.Request().Context().Value(1).(string)
+	})
+
+	code, body := request(GET, "/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "")
+
+	Equal(t, val1, "testval1")
+	Equal(t, val2, "testval2")
+}
+
+func TestRequestCancellation(t *testing.T) {
+
+	started := make(chan struct{})
+	result := make(chan error, 2)
+
+	l := New()
+	l.Get("/", func(c Context) {
+
+		cancel := c.WithTimeout(time.Minute)
+		defer cancel()
+
+		close(started)
+
+		<-c.Done()
+		result <- c.Err()
+		result <- c.Context().Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r = r.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	go l.Serve().ServeHTTP(w, r)
+
+	<-started
+	cancel()
+
+	Equal(t, <-result, context.Canceled)
+	Equal(t, <-result, context.Canceled)
+}
+
+func TestStore(t *testing.T) {
+
+	type ctxKey struct{}
+
+	var native, set, overwritten, viaValue, inRequest interface{}
+
+	l := New()
+	l.Use(func(w http.ResponseWriter, r *http.Request) {
+		*r = *r.WithContext(context.WithValue(r.Context(), ctxKey{}, "native"))
+	})
+	l.Get("/", func(c Context) {
+
+		native, _ = c.Get(ctxKey{})
+
+		c.Set("key", "value")
+		set, _ = c.Get("key")
+
+		c.Set("key", "overwritten")
+		overwritten, _ = c.Get("key")
+
+		viaValue = context.Context(c).Value("key")
+		inRequest = c.Request().Context().Value("key")
+	})
+
+	code, _ := request(GET, "/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, native, "native")
+	Equal(t, set, "value")
+	Equal(t, overwritten, "overwritten")
+	Equal(t, viaValue, "overwritten")
+	Equal(t, inRequest, "overwritten")
+
+	// values set by lars middleware are available to native handlers through the
+	// request's context, as a copy which isn't affected once the Context is reused
+	var nativeValue interface{}
+	var nativeCtx context.Context
+
+	l = New()
+	l.Use(func(c Context) {
+		c.Set("user", "joey")
+		c.Next()
+	})
+	l.Get("/native", func(w http.ResponseWriter, r *http.Request) {
+		nativeValue = r.Context().Value("user")
+		nativeCtx = r.Context()
+	})
+	l.Get("/other", func(c Context) {
+		c.Set("user", "other")
+	})
+
+	code, _ = request(GET, "/native", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, nativeValue, "joey")
+
+	code, _ = request(GET, "/other", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, nativeCtx.Value("user"), "joey")
+
+	c := NewContext(l)
+	r, _ := http.NewRequest(GET, "/", nil)
+	c.RequestStart(httptest.NewRecorder(), r)
+
+	// syncing the values repeatedly keeps a single layer on the request's context
+	for i := 0; i < 3; i++ {
+		c.Set(i, i)
+		c.Request()
+	}
+
+	sc, ok := c.Request().Context().(*storeContext)
+	Equal(t, ok, true)
+	Equal(t, len(sc.entries), 3)
+	Equal(t, sc.Context, context.Background())
+	Equal(t, c.Request().Context().Value(0), 0)
+	Equal(t, c.Request().Context().Value(2), 2)
+
+	// a context set in between is kept
+	*c.Request() = *c.Request().WithContext(context.WithValue(c.Request().Context(), ctxKey{}, "native"))
+	c.Set(3, 3)
+	Equal(t, c.Request().Context().Value(ctxKey{}), "native")
+	Equal(t, c.Request().Context().Value(0), 0)
+	Equal(t, c.Request().Context().Value(3), 3)
+
+	r, _ = http.NewRequest(GET, "/", nil)
+	c.RequestStart(httptest.NewRecorder(), r)
+
+	c.Set("a", 1)
+	c.Set("b", true)
+
+	v := new(int)
+	w := httptest.NewRecorder()
+
+	allocs := testing.AllocsPerRun(100, func() {
+
+		c.RequestStart(w, r)
+
+		c.Set("a", v)
+		c.Set("b", v)
+		c.Get("a")
+		c.GetBool("b")
+	})
+
+	Equal(t, allocs, float64(0))
+
+	// reset between requests
+	c.RequestStart(httptest.NewRecorder(), r)
+	_, exists := c.Get("a")
+	Equal(t, exists, false)
+	Equal(t, len(c.store), 0)
+}
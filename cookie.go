@@ -0,0 +1,51 @@
+package lars
+
+import "net/http"
+
+// CookieDefaults are the attributes applied to every cookie set using c.SetCookie, a
+// cookie may still enable Secure or HttpOnly itself and it's SameSite, when set, wins.
+type CookieDefaults struct {
+	// Secure restricts cookies to HTTPS requests
+	Secure bool
+
+	// HttpOnly hides cookies from client side scripts
+	HttpOnly bool
+
+	// SameSite is used for cookies which don't set their own
+	SameSite http.SameSite
+}
+
+// SetCookieDefaults sets the attributes applied to every cookie set using c.SetCookie,
+// including the session cookie, i.e. to make all cookies Secure in production.
+func (l *LARS) SetCookieDefaults(defaults CookieDefaults) {
+	l.cookieDefaults = defaults
+}
+
+// Cookie returns the named cookie sent with the request or http.ErrNoCookie
+// when it wasn't sent.
+func (c *Ctx) Cookie(name string) (*http.Cookie, error) {
+	return c.request.Cookie(name)
+}
+
+// Cookies returns all of the cookies sent with the request
+func (c *Ctx) Cookies() []*http.Cookie {
+	return c.request.Cookies()
+}
+
+// SetCookie adds a Set-Cookie header to the response, with the cookie defaults set
+// using SetCookieDefaults applied; cookie itself is not modified.
+// NOTE: must be called before the response is committed.
+func (c *Ctx) SetCookie(cookie *http.Cookie) {
+
+	ck := *cookie
+	d := c.lars.cookieDefaults
+
+	ck.Secure = ck.Secure || d.Secure
+	ck.HttpOnly = ck.HttpOnly || d.HttpOnly
+
+	if ck.SameSite == 0 {
+		ck.SameSite = d.SameSite
+	}
+
+	http.SetCookie(c.response, &ck)
+}
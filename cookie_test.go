@@ -0,0 +1,118 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestCookies(t *testing.T) {
+
+	l := New()
+	l.Get("/", func(c Context) {
+
+		var names string
+
+		for _, ck := range c.Cookies() {
+			names += ck.Name + ","
+		}
+
+		ck, err := c.Cookie("a")
+		if err != nil {
+			c.Text(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Text(http.StatusOK, names+ck.Value)
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(GET, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "a", Value: "1"})
+	r.AddCookie(&http.Cookie{Name: "b", Value: "2"})
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "a,b,1")
+
+	r, _ = http.NewRequest(GET, "/", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusBadRequest)
+	Equal(t, w.Body.String(), http.ErrNoCookie.Error())
+}
+
+func TestSetCookie(t *testing.T) {
+
+	serve := func(l *LARS, cookie *http.Cookie) *http.Cookie {
+
+		l.Get("/", func(c Context) {
+			c.SetCookie(cookie)
+		})
+
+		r, _ := http.NewRequest(GET, "/", nil)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+
+		return w.Result().Cookies()[0]
+	}
+
+	ck := serve(New(), &http.Cookie{Name: "a", Value: "1"})
+	Equal(t, ck.Value, "1")
+	Equal(t, ck.Secure, false)
+	Equal(t, ck.HttpOnly, false)
+	Equal(t, ck.SameSite, http.SameSite(0))
+
+	l := New()
+	l.SetCookieDefaults(CookieDefaults{Secure: true, HttpOnly: true, SameSite: http.SameSiteStrictMode})
+
+	cookie := &http.Cookie{Name: "a", Value: "1"}
+
+	ck = serve(l, cookie)
+	Equal(t, ck.Secure, true)
+	Equal(t, ck.HttpOnly, true)
+	Equal(t, ck.SameSite, http.SameSiteStrictMode)
+
+	// the cookie passed in isn't modified
+	Equal(t, cookie.Secure, false)
+
+	l = New()
+	l.SetCookieDefaults(CookieDefaults{SameSite: http.SameSiteStrictMode})
+
+	ck = serve(l, &http.Cookie{Name: "a", Value: "1", Secure: true, SameSite: http.SameSiteNoneMode})
+	Equal(t, ck.Secure, true)
+	Equal(t, ck.HttpOnly, false)
+	Equal(t, ck.SameSite, http.SameSiteNoneMode)
+
+	// applies to the session cookie as well
+	l = New()
+	l.SetCookieDefaults(CookieDefaults{Secure: true})
+	l.SetSessionStore(NewMemorySessionStore(), SessionOptions{})
+	l.Get("/", func(c Context) {
+		s, _ := c.Session()
+		s.Set("a", 1)
+	})
+
+	r, _ := http.NewRequest(GET, "/", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	ck = w.Result().Cookies()[0]
+	Equal(t, ck.Name, "lars_session")
+	Equal(t, ck.Secure, true)
+	Equal(t, ck.HttpOnly, true)
+}
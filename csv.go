@@ -0,0 +1,75 @@
+package lars
+
+import "encoding/csv"
+
+// csvFlushRows is the number of rows written between each flush to the client
+const csvFlushRows = 100
+
+// CSV streams a text/csv response with status code, writing the headers, when not
+// empty, followed by each row returned by rows until it returns false; the rows are
+// flushed to the client as they're written so large exports aren't held in memory.
+// Streaming stops, returning nil, when the client goes away and the first error
+// writing to the client is returned.
+//
+//	c.CSV(http.StatusOK, []string{"id", "name"}, func() ([]string, bool) {
+//		if !rows.Next() {
+//			return nil, false
+//		}
+//		...
+//		return []string{id, name}, true
+//	})
+func (c *Ctx) CSV(code int, headers []string, rows func() ([]string, bool)) error {
+
+	c.response.Header().Set(ContentType, TextCSVCharsetUTF8)
+	c.response.WriteHeader(code)
+
+	sw := &streamWriter{Response: c.response}
+	w := csv.NewWriter(sw)
+
+	flush := func() error {
+
+		if w.Flush(); w.Error() != nil {
+			return w.Error()
+		}
+
+		c.response.Flush()
+
+		return nil
+	}
+
+	if len(headers) > 0 {
+		if err := w.Write(headers); err != nil {
+			return err
+		}
+	}
+
+	done := c.request.Context().Done()
+
+	for n := 1; ; n++ {
+
+		row, ok := rows()
+		if !ok {
+			break
+		}
+
+		if err := w.Write(row); err != nil {
+			return err
+		}
+
+		if n%csvFlushRows != 0 {
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+	}
+
+	return flush()
+}
@@ -0,0 +1,92 @@
+package lars
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestCSV(t *testing.T) {
+
+	var cancel context.CancelFunc
+	var produced int
+
+	rows := func(n int) func() ([]string, bool) {
+
+		produced = 0
+
+		return func() ([]string, bool) {
+
+			if produced == n {
+				return nil, false
+			}
+
+			produced++
+
+			if produced == 150 && cancel != nil {
+				cancel()
+			}
+
+			return []string{strconv.Itoa(produced), "name, \"quoted\""}, true
+		}
+	}
+
+	l := New()
+	l.Get("/csv", func(c Context) error {
+		return c.CSV(http.StatusOK, []string{"id", "name"}, rows(3))
+	})
+	l.Get("/no-headers", func(c Context) error {
+		return c.CSV(http.StatusCreated, nil, rows(1))
+	})
+	l.Get("/large", func(c Context) error {
+		return c.CSV(http.StatusOK, nil, rows(1000))
+	})
+
+	r, _ := http.NewRequest(GET, "/csv", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), TextCSVCharsetUTF8)
+	Equal(t, w.Flushed, true)
+	Equal(t, w.Body.String(), "id,name\n1,\"name, \"\"quoted\"\"\"\n2,\"name, \"\"quoted\"\"\"\n3,\"name, \"\"quoted\"\"\"\n")
+
+	r, _ = http.NewRequest(GET, "/no-headers", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "1,\"name, \"\"quoted\"\"\"\n")
+
+	r, _ = http.NewRequest(GET, "/large", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, strings.Count(w.Body.String(), "\n"), 1000)
+
+	// the client goes away, streaming stops at the next flush
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	r, _ = http.NewRequest(GET, "/large", nil)
+	r = r.WithContext(ctx)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, produced, 200)
+	Equal(t, strings.Count(w.Body.String(), "\n"), 200)
+}
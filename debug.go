@@ -0,0 +1,100 @@
+package lars
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"time"
+)
+
+// debugMethodColors are the ANSI colors of the methods in the route table
+var debugMethodColors = map[string]string{
+	GET:     "\x1b[34m",
+	POST:    "\x1b[36m",
+	PUT:     "\x1b[33m",
+	PATCH:   "\x1b[32m",
+	DELETE:  "\x1b[31m",
+	HEAD:    "\x1b[35m",
+	OPTIONS: "\x1b[37m",
+}
+
+const debugColorReset = "\x1b[0m"
+
+// SetDebug enables or disables debug mode, meant for development only.
+// While enabled the route table, each route's method, path, handler name and number
+// of middleware, is logged by Serve and every request is logged along with the route
+// it matched, it's status and how long it took. The number of running goroutines is
+// also checked before and after every request and a warning, including the handler's
+// name, is logged when a handler leaves goroutines running; a common bug when a
+// goroutine holds onto the pooled Context after the request completes.
+// NOTE: concurrent requests may produce false positives. default false
+func (l *LARS) SetDebug(set bool) {
+	l.debug = set
+}
+
+// logRoutes logs the route table, the methods are colored when logging to a terminal.
+func (l *LARS) logRoutes() {
+
+	color := isTerminal(log.Writer())
+
+	log.Println("lars: running in debug mode, don't use it in production")
+
+	for _, r := range l.Routes() {
+
+		method := fmt.Sprintf("%-7s", r.Method)
+
+		if c, ok := debugMethodColors[r.Method]; ok && color {
+			method = c + method + debugColorReset
+		}
+
+		path := r.Path
+		if r.Host != blank {
+			path = r.Host + path
+		}
+
+		log.Printf("lars: %s %-30s --> %s (%d middleware)\n", method, path, r.HandlerName, r.Middleware)
+	}
+}
+
+// isTerminal returns whether w is a terminal
+func isTerminal(w interface{}) bool {
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// nextDebug runs the Context's handler chain, logging the request, and logs a
+// warning when the handlers leave goroutines running after returning.
+func (l *LARS) nextDebug(c *Ctx) {
+
+	before := runtime.NumGoroutine()
+	start := time.Now()
+
+	c.parent.Next()
+
+	elapsed := time.Since(start)
+
+	pattern, name := "<unmatched route>", c.HandlerName()
+
+	if c.route != nil {
+		pattern = c.route.path
+	}
+
+	log.Printf("lars: %s %s --> %s %d in %s\n", c.request.Method, c.request.URL.Path, pattern, c.response.Status(), elapsed)
+
+	if leaked := runtime.NumGoroutine() - before; leaked > 0 {
+
+		if name == blank {
+			name = "<unmatched route>"
+		}
+
+		log.Printf("lars: handler %s for %s %s left %d goroutine(s) running\n", name, c.request.Method, c.request.URL.Path, leaked)
+	}
+}
@@ -0,0 +1,113 @@
+package lars
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestDebugGoroutineLeak(t *testing.T) {
+
+	buff := new(bytes.Buffer)
+	log.SetOutput(buff)
+	defer log.SetOutput(os.Stderr)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	l := New()
+	l.SetDebug(true)
+	l.Get("/leak", func(c Context) {
+		go func() {
+			<-release
+		}()
+	})
+	l.Get("/ok", basicHandler)
+
+	hf := l.Serve()
+	buff.Reset()
+
+	serve := func(path string) int {
+		r, _ := http.NewRequest(GET, path, nil)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	Equal(t, serve("/ok"), http.StatusOK)
+	Equal(t, strings.Contains(buff.String(), "goroutine"), false)
+
+	buff.Reset()
+
+	Equal(t, serve("/leak"), http.StatusOK)
+	MatchRegex(t, buff.String(), "lars: handler github.com/go-playground/lars.TestDebugGoroutineLeak.func[0-9]+ for GET /leak left 1 goroutine\\(s\\) running\n$")
+
+	buff.Reset()
+	l.SetDebug(false)
+
+	Equal(t, serve("/leak"), http.StatusOK)
+	Equal(t, buff.String(), "")
+}
+
+func TestDebugRoutesAndRequests(t *testing.T) {
+
+	buff := new(bytes.Buffer)
+	log.SetOutput(buff)
+	defer log.SetOutput(os.Stderr)
+
+	l := New()
+	l.Use(func(c Context) { c.Next() })
+	l.Get("/users/:id", basicHandler)
+	l.Post("/users", func(c Context) {
+		c.Response().WriteHeader(http.StatusCreated)
+	})
+	l.Host("api.example.com").Delete("/users/:id", basicHandler)
+
+	l.Serve()
+	Equal(t, buff.String(), "")
+
+	l.SetDebug(true)
+
+	hf := l.Serve()
+
+	lines := strings.Split(strings.TrimSuffix(buff.String(), "\n"), "\n")
+	Equal(t, len(lines), 4)
+	MatchRegex(t, lines[0], "lars: running in debug mode, don't use it in production$")
+	MatchRegex(t, lines[1], "lars: POST    /users                         --> github.com/go-playground/lars.TestDebugRoutesAndRequests.func[0-9]+ \\(1 middleware\\)$")
+	MatchRegex(t, lines[2], "lars: GET     /users/:id                     --> github.com/go-playground/lars.init.func[0-9]+ \\(1 middleware\\)$")
+	MatchRegex(t, lines[3], "lars: DELETE  api.example.com/users/:id      --> github.com/go-playground/lars.init.func[0-9]+ \\(1 middleware\\)$")
+
+	serve := func(method, path string) {
+		buff.Reset()
+		r, _ := http.NewRequest(method, path, nil)
+		hf.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve(GET, "/users/7")
+	MatchRegex(t, buff.String(), "lars: GET /users/7 --> /users/:id 200 in [0-9.]+[nµm]?s\n$")
+
+	serve(POST, "/users")
+	MatchRegex(t, buff.String(), "lars: POST /users --> /users 201 in [0-9.]+[nµm]?s\n$")
+
+	serve(GET, "/missing")
+	MatchRegex(t, buff.String(), "lars: GET /missing --> <unmatched route> 404 in [0-9.]+[nµm]?s\n$")
+
+	Equal(t, isTerminal(buff), false)
+}
@@ -44,6 +44,25 @@ example param usage
 	// remaining path if you need to use it in a custom handler...
 	l.Get("/static/*", http.FileServer(http.Dir("static/")))
 
+	// catch-all params may also be named, c.Param("filepath") and
+	// c.Param(lars.WildcardParam) both return the remaining path;
+	// c.SafePath("filepath", root) joins it to root, rejecting ../ traversal
+	l.Get("/files/*filepath", FilesHandler)
+
+	// trailing params may be optional, matching /articles/2016, /articles/2016/05
+	// and /articles/2016/05/31; c.Param returns blank for those not matched
+	l.Get("/articles/:year/:month?/:day?", ArticlesHandler)
+
+	// params may be constrained by a regular expression or a named param type, the
+	// route only matches when satisfied so the same path may be registered more
+	// than once with different constraints; an unconstrained route is tried last
+	l.Get("/user/:id(\\d+)", UserByIDHandler)
+	l.Get("/user/:id", UserByNameHandler)
+	l.Get("/files/:name{uuid}", FileHandler)
+
+	// register a named param type, "int", "uuid", "alpha" and "alphanum" are built in
+	l.RegisterParamType("slug", IsSlugFunc)
+
 	NOTE: Since this router has only explicit matches, you can not register static routes
 	and parameters for the same path segment. For example you can not register the patterns
 	/user/new and /user/:user for the same request method at the same time. The routing of
@@ -68,6 +87,8 @@ example group definitions
 	user.Post("", ...)
 	user.Delete("/delete", ...)
 
+	// nested groups join their prefix to the parent's and run the parent's middleware
+	// before their own, here /user/:userid/contact-info/:ciid
 	contactInfo := user.Group("/contact-info/:ciid")
 	contactinfo.Delete("/delete", ...)
 
@@ -78,8 +99,22 @@ example group definitions
 	// creates a group for admin WITH NO MIDDLEWARE... more can be added using admin.Use()
 	admin := l.Group("/admin",nil)
 	admin.Use(SomeAdminSecurityMiddleware)
+	admin.Use(middleware.AllowIPs("10.0.0.0/8")) // c.SecureClientIP(), 403 for others
 	...
 
+	// bypass middleware for some requests without restructuring groups, SkipPaths
+	// or any func(lars.Context) bool
+	l.UseWithSkip(Logger, lars.SkipPaths("/health", "/assets/*"))
+
+	// creates a group whose routes are only matched for requests to the host, a
+	// wildcard subdomain is available using c.Param(lars.SubdomainParam); requests
+	// to hosts without their own routes use those registered without a host
+	api := l.Host("api.example.com")
+	api.Get("/users/:id", ...)
+
+	tenants := l.Host("*.tenants.example.com")
+	tenants.Get("/dashboard", ...)
+
 
 Custom Context - Avoid Type Casting - Custom Handlers
 
@@ -164,6 +199,32 @@ currently JSON, XML, FORM + Multipart Form's are support out of the box.
 		log.Println(err)
 	}
 
+	// or bind the request body, without URL params, using the binder registered for
+	// it's Content-Type, ErrUnsupportedMediaType is returned when there isn't one
+	if err := c.Bind(&user); err != nil {
+		return err
+	}
+
+	// or bind just the query params, or the form, using `form` tags; `default` tags fill
+	// in missing values and `layout` tags set the format times are parsed with
+	if err := c.BindQuery(&search); err != nil {
+		return err
+	}
+
+	// register, or replace, the binder for a media type
+	l.RegisterBinder("application/cbor", CBORBinderFunc)
+
+	// adapt a MessagePack library to MsgpackCodec for c.Msgpack, MsgpackOffer and
+	// binding "application/msgpack" request bodies
+	l.SetMsgpackCodec(msgpackCodec)
+
+	// likewise adapt a protocol buffers library to ProtobufCodec for c.Protobuf,
+	// ProtobufOffer and binding "application/protobuf" request bodies
+	l.SetProtobufCodec(protoCodec)
+
+	// and a YAML library to YAMLCodec for c.YAML, YAMLOffer and binding YAML request bodies
+	l.SetYAMLCodec(yamlCodec)
+
 
 Misc
 
@@ -175,18 +236,286 @@ misc examples and noteworthy features
 	// like l.Use() does.
 	l.Get(/"home", AdditionalHandler, HomeHandler)
 
+	// register handlers for non standard methods such as WebDAV's PROPFIND, any
+	// method that is a valid RFC 7230 token is accepted
+	l.Handle("PROPFIND", "/dav/*", DavHandler)
+
+	// serve static files through the router, and it's middleware, directories serve
+	// their index.html and paths are cleaned so they can't escape the directory
+	l.Static("/assets", "public/assets")
+	l.StaticFile("/favicon.ico", "public/favicon.ico")
+
+	// serve a single page application, browsers navigating to client-side routes,
+	// i.e. /users/7, get dist/index.html while routes such as /api/... still win
+	l.SPA("/", "dist", "")
+
+	// or serve files embedded using go:embed, tagged with an ETag generated from their
+	// content as embedded files have no modification time
+	l.StaticFS("/assets", http.FS(assets))
+
+	// static files' precompressed siblings, i.e. app.css.br or app.css.gz, are served
+	// to clients accepting them; fingerprinted files may be cached for a year
+	l.SetStaticImmutable(lars.IsFingerprinted)
+
+	// registering a route returns it's *Route, flag noisy routes such as health checks
+	// to be skipped by access logging middleware, which can check c.Route().IsSilent()
+	l.Get("/health", HealthHandler).Silent()
+
+	// attach metadata to a route, generic middleware such as authorization can read the
+	// matched route's using c.RouteMeta("auth")
+	l.Delete("/users/:id", DeleteUserHandler).Meta("auth", "admin")
+
+	// limit the size of request bodies, exceeding it responds 413; middleware.BodyLimit
+	// limits those of a group and a route's limit replaces SetMaxRequestBodySize's
+	l.SetMaxRequestBodySize(1 << 20)
+	l.Post("/upload", UploadHandler).BodyLimit(100 << 20)
+
+	// list every registered route along with the names of the middleware and handlers
+	// in it's chain, in the order they run
+	for _, r := range l.Routes() {
+		fmt.Println(r.Method, r.Path, r.HandlerName, r.Middleware)
+	}
+
+	// serve the net/http/pprof profiling endpoints through the router, behind the
+	// middleware, instead of on a second listener
+	l.EnablePprof("/debug/pprof", AdminOnly)
+
+	// routes can be registered and removed while serving, i.e. tenant routes loaded
+	// from a database, requests already routed finish as normal
+	tenants.Get("/acme/reports", ReportsHandler)
+	tenants.Remove(lars.GET, "/acme/reports")
+
+	// delegate a subtree to any http.Handler, behind the router's middleware, the prefix
+	// is stripped so /legacy/users is served by the mux as /users
+	l.Mount("/legacy", legacyMux)
+
 	// set custom 404 ( not Found ) handler
 	l.Register404(404Handler)
 
 	// Redirect to or from ending slash if route not found, default is true
 	l.SetRedirectTrailingSlash(true)
 
+	// status codes used for the trailing slash redirects of GET and all other requests,
+	// default is 301 and 307
+	l.SetRedirectCode(http.StatusPermanentRedirect, http.StatusPermanentRedirect)
+
 	// Handle 405 ( Method Not allowed ), default is false
 	l.SetHandle405MethodNotAllowed(false)
 
-	// automatically handle OPTION requests; manually configured
-	// OPTION handlers take precedence. default true
+	// set custom 405 ( Method Not allowed ) handler, the Allow header is populated
+	// with the allowed methods before it's run
+	l.Register405(405Handler)
+
+	// groups can override the 404 and 405 handlers for requests below their prefix, run
+	// after the group's middleware; the group with the longest matching prefix is chosen
+	api.Register404(JSON404Handler)
+	api.Register405(JSON405Handler)
+
+	// respond in the format most acceptable to the client according to it's Accept
+	// header, the 406 handlers are run when none are; c.Accepts(lars.ApplicationJSON,
+	// lars.TextHTML) returns the most acceptable media type for manual checks
+	c.Negotiate(http.StatusOK, lars.JSONOffer(user), lars.XMLOffer(user), lars.HTMLOffer("users/show", user))
+
+	// pick the best supported language according to the Accept-Language header, en-US
+	// falls back to en, blank when none are acceptable
+	lang := c.NegotiateLanguage("en", "fr", "de")
+
+	// translate responses, the locale is detected from the lang query param, cookie
+	// and then the Accept-Language header; c.T falls back to the default locale
+	l.AddCatalog("en", lars.Catalog{"greeting": "Hello %s"})
+	l.AddCatalog("fr", lars.Catalog{"greeting": "Bonjour %s"})
+	l.Use(lars.DetectLocale(lars.LocaleConfig{QueryParam: "lang", Cookie: "lang"}))
+	msg := c.T("greeting", user.Name)
+
+	// set custom 406 ( Not Acceptable ) handler, run by c.NotAcceptable() when content
+	// negotiation can't satisfy the client's Accept header
+	l.Register406(406Handler)
+
+	// automatically handle OPTION requests for paths with registered handlers with a
+	// 204, the Allow header lists their methods; manually configured OPTION handlers
+	// take precedence, the responses can be cached for max-age. default false
 	l.SetAutomaticallyHandleOPTIONS(set bool)
+	l.SetAutomaticOPTIONSMaxAge(time.Hour)
+
+	// handlers and middleware may also return an error, func(lars.Context) error, which
+	// is passed to the registered error handler; the default renders an HTTPError's
+	// Code + Message as JSON, or text when preferred by the client, and responds 500 for
+	// any other error. Internal errors are logged but never sent to the client
+	l.Get("/user/:id", func(c lars.Context) error {
+		return lars.NewHTTPError(http.StatusNotFound, "user not found").SetInternal(err)
+	})
+	l.SetErrorHandler(ErrorHandlerFunc)
+
+	// set default headers written on every response, handlers can still override
+	// them and a blank value removes the header
+	l.SetResponseHeaders(map[string]string{"Server": "lars", "X-Powered-By": ""})
+
+	// run a server owned by LARS and gracefully shut it down, in-flight requests still
+	// running after the drain timeout have their contexts cancelled; handlers observe
+	// this through c.Done()
+	l.SetDrainTimeout(time.Second * 10)
+	go l.RunServer(":3007")
+	...
+	l.Shutdown(ctx)
+
+	// or let LARS gracefully shut the server down once SIGINT or SIGTERM is received,
+	// in-flight requests are given up to the shutdown timeout to complete. default 30s
+	l.SetShutdownTimeout(time.Second * 20)
+
+	// fail readiness for a while before the server stops accepting requests, giving load
+	// balancers time to stop routing to it. default 0
+	l.SetShutdownDelay(time.Second * 5)
+	log.Fatal(l.Run(":3007")) // or l.RunTLS(":443", "cert.pem", "key.pem")
+
+	// signal the app isn't ready to serve traffic, all requests except those to the
+	// allowlisted paths are answered with 503 until SetReady(true) is called
+	l.SetReadyAllowlist("/health")
+	l.SetReady(false)
+
+	// serve liveness and readiness checks as JSON at /livez and /readyz, 503 when any
+	// fail; readiness also fails while not ready and once Shutdown begins
+	l.AddReadinessCheck("db", func(ctx context.Context) error { return db.PingContext(ctx) })
+	l.ServeHealth(lars.HealthConfig{CacheFor: time.Second * 5})
+
+	// buffer log entries on the Context, they're flushed together once the request's
+	// handlers complete so they aren't interleaved with other requests' output
+	c.Log("info", "user loaded", "id", user.ID)
+	l.SetLogSink(LogSinkFunc)
+
+	// enable debug mode during development, logs the route table when serving begins
+	// and every request with the route it matched and how long it took; also warns
+	// when a handler leaves goroutines running after the request completes. default false
+	l.SetDebug(true)
+
+	// only honor X-Real-IP and X-Forwarded-For in c.ClientIP() when the request comes
+	// from one of these proxies, by default the headers are trusted blindly; while
+	// c.SecureClientIP(), used by the IP filter and rate limit middleware, ignores them
+	// unless trusted proxies are configured
+	l.SetTrustedProxies("10.0.0.0/8", "127.0.0.1")
+
+	// buffer responses until the request completes so middleware can still change the
+	// status and a handler returning an error has it's partial response discarded;
+	// middleware can discard it themselves to write an error page
+	l.SetBufferedResponse(true)
+	c.Response().Reset()
+
+	// set headers at the last safe moment, just before the header is written, and
+	// record the final status and size once the request completes
+	c.Response().Before(func() { c.Response().Header().Set("Server-Timing", timing()) })
+	c.Response().After(func() { record(c.Response().Status(), c.Response().Size()) })
+
+	// response helpers, such as c.JSON or c.Attachment, write bodies up to this size with
+	// a Content-Length in a single write and stream larger ones chunked. default 32KB
+	l.SetStreamThreshold(64 << 10)
+
+	// render HTML templates using c.Render(http.StatusOK, "users/show", data), the built
+	// in HTMLRenderer renders templates/users/show.html within the layout together with
+	// the partials; Reload reparses templates on every render during development
+	l.SetRenderer(&lars.HTMLRenderer{
+		Dir:      "templates",
+		Layout:   "layouts/main",
+		Partials: "partials",
+		Funcs:    template.FuncMap{"upper": strings.ToUpper},
+		Reload:   dev,
+	})
+
+	// read and set cookies, the defaults are applied to every cookie set using c.SetCookie,
+	// a cookie can still enable Secure and HttpOnly itself or choose it's own SameSite
+	l.SetCookieDefaults(lars.CookieDefaults{Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	c.SetCookie(&http.Cookie{Name: "theme", Value: "dark", MaxAge: 3600})
+	theme, err := c.Cookie("theme")
+
+	// keep sessions server-side, or signed in the cookie itself using NewCookieSessionStore,
+	// c.Session() loads it on first access and it's saved, when modified, just before the
+	// response is committed; call Regenerate() on login to prevent session fixation
+	l.SetSessionStore(lars.NewMemorySessionStore(), lars.SessionOptions{Secure: true})
+	s, err := c.Session()
+	s.Set("user", user.ID)
+
+	// upgrade using any websocket library by implementing WebSocketUpgrader, gorilla's
+	// is adapted by lars.GorillaUpgrader; c.WebSocketConn() returns the connection
+	l.Get("/ws", func(c lars.Context) error {
+		return c.UpgradeWith(upgrader, func(conn lars.WebSocketConn) { ... })
+	})
+
+	// register websocket connections with a Hub to broadcast to all of them or the rooms
+	// they've joined, Serve blocks reading messages until the connection is closed
+	hub := lars.NewHub()
+	hub.OnConnect = func(conn *lars.HubConn) { conn.Join(conn.Context().Param("room")) }
+	hub.OnMessage = func(conn *lars.HubConn, typ int, data []byte) {
+		hub.BroadcastRoom(conn.Context().Param("room"), typ, data)
+	}
+	l.Get("/chat/:room", func(c lars.Context) error { return hub.Serve(c, upgrader) })
+
+	// stream Server-Sent Events, clients reconnecting send the ID of the last event they
+	// received; the heartbeat comments stop once the handler returns
+	stream := c.SSE()
+	stream.Heartbeat(time.Second * 15)
+	stream.Send("update", "42", string(data))
+	lastID := stream.LastEventID()
+
+	// document routes and generate an OpenAPI 3 document from them, optionally served
+	l.Get("/users/:id{int}", GetUser).Summary("Get a user").Tags("users").Response(http.StatusOK, User{})
+	doc := l.OpenAPI(lars.OpenAPIInfo{Title: "Users", Version: "1.0.0"})
+	l.ServeOpenAPI("/openapi.json", lars.OpenAPIInfo{Title: "Users", Version: "1.0.0"})
+
+	// name routes to build their URLs and redirect to them, redirects can be restricted
+	// to an allowlist of hosts guarding against open redirects
+	l.Get("/users/:id", UserHandler).Name("user")
+	l.SetRedirectAllowlist("example.com")
+	url, err := l.URL("user", "7")
+	c.Redirect(http.StatusSeeOther, "https://example.com/login")
+	c.RedirectToRoute("user", "7")
+
+	// parse params, returning a 400 Bad Request *HTTPError when they're invalid
+	id, err := c.ParamInt64("id")
+	if err != nil {
+		return err
+	}
+
+	// save an uploaded file, or stream the parts of large uploads as they're received
+	// instead of parsing them into memory and temporary files
+	fh, err := c.FormFile("avatar")
+	err = c.SaveUploadedFile(fh, "/uploads/avatar.png")
+	mr, err := c.MultipartReader()
+
+	// stream a CSV export row by row, flushing as it goes, until rows returns false
+	c.CSV(http.StatusOK, []string{"id", "name"}, nextRow)
+
+	// encode large payloads directly to the response instead of marshaling them first,
+	// or stream a JSON array item by item until next returns false
+	c.JSONStream(http.StatusOK, report)
+	c.XMLStream(http.StatusOK, report)
+	c.JSONArrayStream(http.StatusOK, nextUser)
+
+	// push assets referenced by the page over HTTP/2, a no-op over HTTP/1.1
+	c.Push("/static/app.css", nil)
+
+	// tag content with an ETag, generated from the content when blank, answering
+	// requests which already have it with a 304; middleware.ETag tags whole responses
+	c.ServeContentConditional("report.csv", "", modtime, f)
+
+	// read or generate an X-Request-ID, set on the response and included in c.Log's
+	// entries, middleware.ULID or any func() string can generate the IDs
+	l.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{Generator: middleware.ULID}))
+	id := c.RequestID()
+
+	// fail fast with 503 once a route is consistently erroring, each route has it's
+	// own circuit which is probed again after the open timeout
+	l.Use(middleware.CircuitBreaker(middleware.CircuitBreakerConfig{OnStateChange: alert}))
+
+	// collect Prometheus metrics labeled by method, route pattern and status class,
+	// served at /metrics
+	metrics := middleware.NewMetrics(middleware.MetricsConfig{})
+	l.Use(metrics.Middleware())
+	metrics.Mount(l, "/metrics")
+
+	// pretty-print the output of c.JSON, useful during development. default false
+	l.SetJSONIndent(true)
+
+	// swap encoding/json for another implementation, used by the JSON response helpers,
+	// Bind and Decode, or stop HTML characters being escaped; c.JSONPretty always indents
+	l.SetJSONCodec(lars.StdJSONCodec{DisableHTMLEscape: true})
 
 	// register custom context
 	l.RegisterContext(ContextFunc)
@@ -202,6 +531,19 @@ misc examples and noteworthy features
 	// using nosurf CSRF middleware
 	l.Use(nosurf.NewPure(lars.NativeChainHandler))
 
+	// standard func(http.Handler) http.Handler and negroni style middleware can be passed
+	// to Use directly, or wrapped explicitly, calling next continues the chain with the
+	// request and writer passed to it
+	l.Use(handlers.ProxyHeaders)
+	l.Use(lars.WrapNegroni(negroniMiddleware))
+
+	// instrument routing, the hook is notified of every lookup's outcome i.e. found, not
+	// found, method not allowed or redirect, and how long it took
+	l.SetMatchHook(lars.MatchHookFunc(func(c lars.Context, info lars.MatchInfo) {}))
+
+	// handlers and middleware can be tested without a server using the larstest package
+	rec := larstest.NewRequest(lars.GET, "/users/7").Param("id", "7").Run(getUser)
+
 	// Context has 2 methods of which you should be aware of ParseForm and
 	// ParseMulipartForm, they just call the default http functions but provide one more
 	// additional feature, they copy the URL params to the request Forms variables, just
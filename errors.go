@@ -0,0 +1,132 @@
+package lars
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// HTTPError represents an error that occurred while handling a request
+// and carries the http status code and message to respond with.
+type HTTPError struct {
+	Code    int
+	Message interface{}
+
+	// Internal is the underlying error, it's logged but never sent to the client
+	Internal error
+}
+
+// ErrFormFileTooLarge is returned when an uploaded file exceeds
+// the limit set using SetMaxFormFileBytes
+var ErrFormFileTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge, "form file too large")
+
+// ErrRequestEntityTooLarge is returned when reading a request body
+// exceeding the limit set using SetMaxRequestBodySize
+var ErrRequestEntityTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge)
+
+// ErrUnsupportedMediaType is returned by Bind when no BinderFunc is
+// registered for the request's Content-Type
+var ErrUnsupportedMediaType = NewHTTPError(http.StatusUnsupportedMediaType)
+
+// ErrBodyLineTooLong is returned by BodyLines when a line exceeds
+// the limit set using SetMaxBodyLineLength
+var ErrBodyLineTooLong = NewHTTPError(http.StatusRequestEntityTooLarge, "request body line too long")
+
+// ErrNoRenderer is returned by Render when no Renderer
+// was set using SetRenderer
+var ErrNoRenderer = errors.New("no Renderer set, see SetRenderer")
+
+// ErrNoProtobufCodec is returned by Protobuf when no ProtobufCodec
+// was set using SetProtobufCodec
+var ErrNoProtobufCodec = errors.New("no ProtobufCodec set, see SetProtobufCodec")
+
+// ErrNoYAMLCodec is returned by YAML when no YAMLCodec
+// was set using SetYAMLCodec
+var ErrNoYAMLCodec = errors.New("no YAMLCodec set, see SetYAMLCodec")
+
+// ErrNoMsgpackCodec is returned by Msgpack when no MsgpackCodec
+// was set using SetMsgpackCodec
+var ErrNoMsgpackCodec = errors.New("no MsgpackCodec set, see SetMsgpackCodec")
+
+// ErrorHandlerFunc is the function called when a handler or middleware
+// returns an error
+type ErrorHandlerFunc func(err error, c Context)
+
+// NewHTTPError creates a new HTTPError instance, if no message is provided
+// the http status text of the code is used
+func NewHTTPError(code int, message ...interface{}) *HTTPError {
+
+	he := &HTTPError{Code: code, Message: http.StatusText(code)}
+
+	if len(message) > 0 {
+		he.Message = message[0]
+	}
+
+	return he
+}
+
+// Error returns the string representation of the HTTPError
+func (he *HTTPError) Error() string {
+
+	if he.Internal != nil {
+		return fmt.Sprintf("code=%d, message=%v, internal=%v", he.Code, he.Message, he.Internal)
+	}
+
+	return fmt.Sprintf("code=%d, message=%v", he.Code, he.Message)
+}
+
+// SetInternal sets the underlying error and returns the HTTPError i.e.
+// return lars.NewHTTPError(http.StatusBadGateway).SetInternal(err)
+func (he *HTTPError) SetInternal(err error) *HTTPError {
+	he.Internal = err
+	return he
+}
+
+// Unwrap returns the Internal error, for use with errors.Is and errors.As
+func (he *HTTPError) Unwrap() error {
+	return he.Internal
+}
+
+// errRequestBodyTooLarge is the message of the error returned by a http.MaxBytesReader
+// when the limit is exceeded, compared against rather than it's type as
+// http.MaxBytesError was only added in Go 1.19
+const errRequestBodyTooLarge = "http: request body too large"
+
+// requestBodyError converts the error returned by a http.MaxBytesReader
+// when the request body size limit is exceeded to ErrRequestEntityTooLarge
+func requestBodyError(err error) error {
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if e.Error() == errRequestBodyTooLarge {
+			return ErrRequestEntityTooLarge
+		}
+	}
+
+	return err
+}
+
+// defaultErrorHandler renders HTTPError's, including wrapped ones, Code + Message as
+// JSON, or as text when the client prefers it, and falls back to 500 Internal Server
+// Error for any other error. Errors which aren't sent to the client are logged.
+func defaultErrorHandler(err error, c Context) {
+
+	var he *HTTPError
+
+	if !errors.As(err, &he) {
+		he = NewHTTPError(http.StatusInternalServerError)
+		c.Log("error", "request failed", "error", err)
+	} else if he.Internal != nil {
+		c.Log("error", "request failed", "error", he)
+	}
+
+	if c.Response().Committed() {
+		return
+	}
+
+	if c.Accepts(ApplicationJSON, TextPlain) == TextPlain {
+		c.Text(he.Code, fmt.Sprint(he.Message))
+		return
+	}
+
+	c.JSON(he.Code, map[string]interface{}{"message": he.Message})
+}
@@ -0,0 +1,177 @@
+package lars
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestHTTPError(t *testing.T) {
+
+	he := NewHTTPError(http.StatusNotFound)
+	Equal(t, he.Code, http.StatusNotFound)
+	Equal(t, he.Message, http.StatusText(http.StatusNotFound))
+	Equal(t, he.Error(), "code=404, message=Not Found")
+
+	he = NewHTTPError(http.StatusBadRequest, "invalid id")
+	Equal(t, he.Message, "invalid id")
+}
+
+func TestErrorHandler(t *testing.T) {
+
+	l := New()
+	l.Get("/http-error", func(c Context) error {
+		return NewHTTPError(http.StatusBadRequest, "invalid id")
+	})
+	l.Get("/error", func(c Context) error {
+		return errors.New("database down")
+	})
+	l.Get("/ok", func(c Context) error {
+		return c.Text(http.StatusOK, "ok")
+	})
+	l.Get("/committed", func(c Context) error {
+		c.Text(http.StatusOK, "partial")
+		return errors.New("too late")
+	})
+
+	mw := func(c Context) error {
+		if c.Request().URL.Query().Get("deny") != "" {
+			return NewHTTPError(http.StatusForbidden)
+		}
+		c.Next()
+		return nil
+	}
+
+	l.Get("/mw", mw, func(c Context) error {
+		return c.Text(http.StatusOK, "passed")
+	})
+
+	code, body := request(GET, "/http-error", l)
+	Equal(t, code, http.StatusBadRequest)
+	Equal(t, body, `{"message":"invalid id"}`)
+
+	code, body = request(GET, "/error", l)
+	Equal(t, code, http.StatusInternalServerError)
+	Equal(t, body, `{"message":"Internal Server Error"}`)
+
+	code, body = request(GET, "/ok", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "ok")
+
+	code, body = request(GET, "/committed", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "partial")
+
+	code, body = request(GET, "/mw", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "passed")
+
+	code, body = request(GET, "/mw?deny=true", l)
+	Equal(t, code, http.StatusForbidden)
+	Equal(t, body, `{"message":"Forbidden"}`)
+
+	var handled error
+
+	l.SetErrorHandler(func(err error, c Context) {
+		handled = err
+		c.Response().WriteHeader(http.StatusTeapot)
+	})
+
+	r, _ := http.NewRequest(GET, "/error", nil)
+	w := httptest.NewRecorder()
+	l.serveHTTP(w, r)
+	Equal(t, w.Code, http.StatusTeapot)
+	Equal(t, handled.Error(), "database down")
+}
+
+func TestHTTPErrorInternal(t *testing.T) {
+
+	internal := errors.New("connection refused")
+
+	he := NewHTTPError(http.StatusBadGateway).SetInternal(internal)
+	Equal(t, he.Internal, internal)
+	Equal(t, he.Error(), "code=502, message=Bad Gateway, internal=connection refused")
+	Equal(t, errors.Is(he, internal), true)
+
+	var logged []LogEntry
+
+	l := New()
+	l.SetLogSink(func(c Context, entries []LogEntry) {
+		logged = append(logged, entries...)
+	})
+	l.Get("/internal", func(c Context) error {
+		return NewHTTPError(http.StatusBadGateway, "upstream unavailable").SetInternal(internal)
+	})
+	l.Get("/wrapped", func(c Context) error {
+		return fmt.Errorf("loading user: %w", NewHTTPError(http.StatusNotFound, "user not found"))
+	})
+	l.Get("/error", func(c Context) error {
+		return internal
+	})
+
+	code, body := request(GET, "/internal", l)
+	Equal(t, code, http.StatusBadGateway)
+	Equal(t, body, `{"message":"upstream unavailable"}`)
+	Equal(t, len(logged), 1)
+	Equal(t, logged[0].Fields[1].(error).Error(), "code=502, message=upstream unavailable, internal=connection refused")
+
+	code, body = request(GET, "/wrapped", l)
+	Equal(t, code, http.StatusNotFound)
+	Equal(t, body, `{"message":"user not found"}`)
+	Equal(t, len(logged), 1)
+
+	code, body = request(GET, "/error", l)
+	Equal(t, code, http.StatusInternalServerError)
+	Equal(t, body, `{"message":"Internal Server Error"}`)
+	Equal(t, len(logged), 2)
+	Equal(t, logged[1].Fields[1], internal)
+
+	// negotiated
+	r, _ := http.NewRequest(GET, "/internal", nil)
+	r.Header.Set(Accept, "text/plain")
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusBadGateway)
+	Equal(t, w.Header().Get(ContentType), TextPlainCharsetUTF8)
+	Equal(t, w.Body.String(), "upstream unavailable")
+
+	r, _ = http.NewRequest(GET, "/internal", nil)
+	r.Header.Set(Accept, "text/html, application/json;q=0.9, text/plain;q=0.8")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+
+	// nothing acceptable still gets the error
+	r, _ = http.NewRequest(GET, "/internal", nil)
+	r.Header.Set(Accept, "image/png")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusBadGateway)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+}
+
+func TestRequestBodyError(t *testing.T) {
+
+	tooLarge := errors.New("http: request body too large")
+	other := errors.New("unexpected EOF")
+
+	Equal(t, requestBodyError(tooLarge), ErrRequestEntityTooLarge)
+	Equal(t, requestBodyError(fmt.Errorf("decoding: %w", tooLarge)), ErrRequestEntityTooLarge)
+	Equal(t, requestBodyError(other), other)
+	Equal(t, requestBodyError(nil), nil)
+}
@@ -0,0 +1,50 @@
+package lars
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// GenerateETag returns a strong ETag for the content b, made up of it's length
+// and FNV-1a hash i.e. "1f4-a0c9b1e2f3d4c5b6"
+func GenerateETag(b []byte) string {
+	h := fnv.New64a()
+	h.Write(b)
+	return formatETag(int64(len(b)), h.Sum(nil))
+}
+
+func formatETag(size int64, sum []byte) string {
+	return `"` + strconv.FormatInt(size, 16) + "-" + hex.EncodeToString(sum) + `"`
+}
+
+// ServeContentConditional replies to the request like ServeContent, tagging the content
+// with etag, quoted i.e. `"v1"` or `W/"v1"`, or when blank an ETag generated from the
+// content; requests whose If-None-Match matches the ETag, or If-Modified-Since isn't
+// before modtime, are answered with a 304 Not Modified without the content. Generating
+// the ETag reads all of the content, an error is returned when it can't be read or seeked.
+func (c *Ctx) ServeContentConditional(name, etag string, modtime time.Time, content io.ReadSeeker) error {
+
+	if etag == blank {
+
+		h := fnv.New64a()
+
+		size, err := io.Copy(h, content)
+		if err != nil {
+			return err
+		}
+
+		if _, err = content.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		etag = formatETag(size, h.Sum(nil))
+	}
+
+	c.response.Header().Set(ETag, etag)
+	c.ServeContent(name, modtime, content)
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package lars
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+type failingSeeker struct {
+	io.ReadSeeker
+}
+
+func (f failingSeeker) Seek(offset int64, whence int) (int64, error) {
+	return 0, errors.New("seek failed")
+}
+
+func TestGenerateETag(t *testing.T) {
+	Equal(t, GenerateETag([]byte("lars")), `"4-0453d3ad905613b5"`)
+	Equal(t, GenerateETag(nil), `"0-cbf29ce484222325"`)
+	NotEqual(t, GenerateETag([]byte("lars")), GenerateETag([]byte("LARS")))
+}
+
+func TestServeContentConditional(t *testing.T) {
+
+	modtime := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	l := New()
+	l.Get("/generated", func(c Context) error {
+		return c.ServeContentConditional("file.txt", blank, modtime, strings.NewReader("0123456789"))
+	})
+	l.Get("/provided", func(c Context) error {
+		return c.ServeContentConditional("file.txt", `"v1"`, time.Time{}, strings.NewReader("0123456789"))
+	})
+	l.Get("/error", func(c Context) error {
+		return c.ServeContentConditional("file.txt", blank, time.Time{}, failingSeeker{strings.NewReader("0123456789")})
+	})
+
+	tag := GenerateETag([]byte("0123456789"))
+
+	r, _ := http.NewRequest(GET, "/generated", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ETag), tag)
+	Equal(t, w.Header().Get(LastModified), "Sat, 02 Jan 2016 03:04:05 GMT")
+	Equal(t, w.Header().Get(ContentType), TextPlainCharsetUTF8)
+	Equal(t, w.Body.String(), "0123456789")
+
+	r, _ = http.NewRequest(GET, "/generated", nil)
+	r.Header.Set(IfNoneMatch, tag)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusNotModified)
+	Equal(t, w.Body.Len(), 0)
+
+	r, _ = http.NewRequest(GET, "/generated", nil)
+	r.Header.Set(IfModifiedSince, "Sat, 02 Jan 2016 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusNotModified)
+
+	r, _ = http.NewRequest(GET, "/provided", nil)
+	r.Header.Set(IfNoneMatch, `"v1"`)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusNotModified)
+	Equal(t, w.Header().Get(ETag), `"v1"`)
+
+	r, _ = http.NewRequest(GET, "/provided", nil)
+	r.Header.Set(IfNoneMatch, `"v0"`)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "0123456789")
+
+	r, _ = http.NewRequest(GET, "/error", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusInternalServerError)
+}
@@ -38,6 +38,7 @@ const (
 )
 
 // LoggingAndRecovery handle HTTP request logging + recovery
+// NOTE: requests matching a route flagged using Silent() are recovered but not logged
 func LoggingAndRecovery(c lars.Context) {
 
 	t1 := time.Now()
@@ -54,6 +55,11 @@ func LoggingAndRecovery(c lars.Context) {
 
 	c.Next()
 
+	// routes flagged using Silent(), such as health checks, are not logged
+	if route := c.Route(); route != nil && route.IsSilent() {
+		return
+	}
+
 	var color string
 
 	res := c.Response()
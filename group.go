@@ -1,6 +1,7 @@
 package lars
 
 import (
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -11,34 +12,61 @@ import (
 type IRouteGroup interface {
 	IRoutes
 	Group(prefix string, middleware ...Handler) IRouteGroup
+	Host(pattern string, middleware ...Handler) IRouteGroup
+	Register404(notFound ...Handler)
+	Register405(notAllowed ...Handler)
 }
 
 // IRoutes interface for routes
 type IRoutes interface {
 	Use(...Handler)
+	UseWithSkip(Handler, ...SkipFunc)
 	Any(string, ...Handler)
-	Get(string, ...Handler)
-	Post(string, ...Handler)
-	Delete(string, ...Handler)
-	Patch(string, ...Handler)
-	Put(string, ...Handler)
-	Options(string, ...Handler)
-	Head(string, ...Handler)
-	Connect(string, ...Handler)
-	Trace(string, ...Handler)
-	WebSocket(websocket.Upgrader, string, Handler)
+	Get(string, ...Handler) *Route
+	Post(string, ...Handler) *Route
+	Delete(string, ...Handler) *Route
+	Patch(string, ...Handler) *Route
+	Put(string, ...Handler) *Route
+	Options(string, ...Handler) *Route
+	Head(string, ...Handler) *Route
+	Connect(string, ...Handler) *Route
+	Trace(string, ...Handler) *Route
+	Handle(string, string, ...Handler) *Route
+	Remove(string, string) bool
+	Static(string, string)
+	StaticFile(string, string)
+	StaticFS(string, http.FileSystem)
+	StaticFileFS(string, string, http.FileSystem)
+	SPA(string, string, string)
+	SPAFS(string, http.FileSystem, string)
+	Mount(string, http.Handler)
+	WebSocket(websocket.Upgrader, string, Handler) *Route
 }
 
 // routeGroup struct containing all fields and methods for use.
 type routeGroup struct {
 	prefix     string
 	middleware HandlersChain
-	lars       *LARS
+	// middlewareNames are the names of the middleware, before wrapping, in the same order
+	middlewareNames []string
+	lars            *LARS
+	// host is the host the group's routes are registered for, nil for all hosts
+	host *vhost
+	// http404 and http405 override the router's for requests below the group's prefix,
+	// notFound and notAllowed are the group's middleware followed by them
+	http404    HandlersChain
+	http405    HandlersChain
+	notFound   HandlersChain
+	notAllowed HandlersChain
 }
 
 var _ IRouteGroup = &routeGroup{}
 
-func (g *routeGroup) handle(method string, path string, handlers []Handler) {
+func (g *routeGroup) handle(method string, path string, handlers []Handler) *Route {
+
+	if !validMethod(method) {
+		panic("Invalid method '" + method + "' for path:" + path)
+	}
 
 	if len(handlers) == 0 {
 		panic("No handler mapped to path:" + path)
@@ -49,92 +77,188 @@ func (g *routeGroup) handle(method string, path string, handlers []Handler) {
 	}
 
 	chain := make(HandlersChain, len(handlers))
-	name := ""
+	names := make([]string, len(g.middlewareNames), len(g.middlewareNames)+len(handlers))
+	copy(names, g.middlewareNames)
+
+	var name string
 
 	for i, h := range handlers {
+		chain[i], name = g.lars.wrapHandlerWithName(h)
+		names = append(names, name)
+	}
 
-		if i == len(handlers)-1 {
-			chain[i], name = g.lars.wrapHandlerWithName(h)
-		} else {
-			chain[i] = g.lars.wrapHandler(h)
-		}
+	g.lars.routesMu.Lock()
+	defer g.lars.routesMu.Unlock()
+
+	trees := g.lars.trees
+	if g.host != nil {
+		trees = g.host.trees
 	}
 
-	tree := g.lars.trees[method]
+	tree := trees[method]
 	if tree == nil {
 		tree = new(node)
-		g.lars.trees[method] = tree
+		trees[method] = tree
 	}
 
 	combined := make(HandlersChain, len(g.middleware)+len(chain))
 	copy(combined, g.middleware)
 	copy(combined[len(g.middleware):], chain)
 
-	pCount := tree.add(g.prefix+path, name, combined)
+	route := &Route{
+		lars:        g.lars,
+		method:      method,
+		path:        joinPaths(g.prefix, path),
+		handlerName: name,
+		chainNames:  names,
+	}
+
+	if route.path == blank {
+		route.path = basePath
+	}
+
+	var pCount uint8
+
+	// a route with optional params is added for each number of them matched, sharing
+	// the constraints of the longest, those of unmatched params are skipped
+	for _, p := range optionalPaths(route.path) {
+
+		p, route.constraints = g.lars.parseConstraints(p)
+		route.catchAll = strings.IndexByte(p, wildByte) != -1
+
+		if n := tree.add(p, route, combined); n >= pCount {
+			pCount = n
+		}
+	}
+
 	pCount++
 
+	if g.host != nil {
+
+		route.host = g.host.pattern
+
+		// the subdomain precedes the URL params
+		if g.host.suffix != blank {
+
+			for i := range route.constraints {
+				route.constraints[i].index++
+			}
+
+			pCount++
+		}
+	}
+
 	if pCount > g.lars.mostParams {
 		g.lars.mostParams = pCount
 	}
+
+	return route
+}
+
+// Remove removes the route registered for method and path, the path as it was passed
+// to Get, Post etc. including any param constraints, returning whether it was found.
+// Routes can be registered and removed while serving, i.e. to load tenant specific
+// routes from a database, requests already routed to it finish as normal.
+func (g *routeGroup) Remove(method string, path string) bool {
+
+	path = joinPaths(g.prefix, path)
+	if path == blank {
+		path = basePath
+	}
+
+	l := g.lars
+
+	l.routesMu.Lock()
+	defer l.routesMu.Unlock()
+
+	trees := l.trees
+	if g.host != nil {
+		trees = g.host.trees
+	}
+
+	tree := trees[method]
+	if tree == nil {
+		return false
+	}
+
+	route := tree.remove(path)
+	if route == nil {
+		return false
+	}
+
+	if tree = tree.rebuild(); tree != nil {
+		trees[method] = tree
+	} else {
+		delete(trees, method)
+	}
+
+	if route.name != blank {
+		delete(l.namedRoutes, route.name)
+	}
+
+	return true
 }
 
 // Use adds a middleware handler to the group middleware chain.
 func (g *routeGroup) Use(m ...Handler) {
 	for _, h := range m {
-		g.middleware = append(g.middleware, g.lars.wrapHandler(h))
+		chain, name := g.lars.wrapHandlerWithName(h)
+		g.middleware = append(g.middleware, chain)
+		g.middlewareNames = append(g.middlewareNames, name)
 	}
 }
 
 // Connect adds a CONNECT route & handler to the router.
-func (g *routeGroup) Connect(path string, h ...Handler) {
-	g.handle(CONNECT, path, h)
+func (g *routeGroup) Connect(path string, h ...Handler) *Route {
+	return g.handle(CONNECT, path, h)
 }
 
 // Delete adds a DELETE route & handler to the router.
-func (g *routeGroup) Delete(path string, h ...Handler) {
-	g.handle(DELETE, path, h)
+func (g *routeGroup) Delete(path string, h ...Handler) *Route {
+	return g.handle(DELETE, path, h)
 }
 
 // Get adds a GET route & handler to the router.
-func (g *routeGroup) Get(path string, h ...Handler) {
-	g.handle(GET, path, h)
+func (g *routeGroup) Get(path string, h ...Handler) *Route {
+	return g.handle(GET, path, h)
 }
 
 // Head adds a HEAD route & handler to the router.
-func (g *routeGroup) Head(path string, h ...Handler) {
-	g.handle(HEAD, path, h)
+func (g *routeGroup) Head(path string, h ...Handler) *Route {
+	return g.handle(HEAD, path, h)
 }
 
 // Options adds an OPTIONS route & handler to the router.
-func (g *routeGroup) Options(path string, h ...Handler) {
-	g.handle(OPTIONS, path, h)
+func (g *routeGroup) Options(path string, h ...Handler) *Route {
+	return g.handle(OPTIONS, path, h)
 }
 
 // Patch adds a PATCH route & handler to the router.
-func (g *routeGroup) Patch(path string, h ...Handler) {
-	g.handle(PATCH, path, h)
+func (g *routeGroup) Patch(path string, h ...Handler) *Route {
+	return g.handle(PATCH, path, h)
 }
 
 // Post adds a POST route & handler to the router.
-func (g *routeGroup) Post(path string, h ...Handler) {
-	g.handle(POST, path, h)
+func (g *routeGroup) Post(path string, h ...Handler) *Route {
+	return g.handle(POST, path, h)
 }
 
 // Put adds a PUT route & handler to the router.
-func (g *routeGroup) Put(path string, h ...Handler) {
-	g.handle(PUT, path, h)
+func (g *routeGroup) Put(path string, h ...Handler) *Route {
+	return g.handle(PUT, path, h)
 }
 
 // Trace adds a TRACE route & handler to the router.
-func (g *routeGroup) Trace(path string, h ...Handler) {
-	g.handle(TRACE, path, h)
+func (g *routeGroup) Trace(path string, h ...Handler) *Route {
+	return g.handle(TRACE, path, h)
 }
 
 // Handle allows for any method to be registered with the given
 // route & handler. Allows for non standard methods to be used
-// like CalDavs PROPFIND and so forth.
-func (g *routeGroup) Handle(method string, path string, h ...Handler) {
-	g.handle(method, path, h)
+// like CalDavs PROPFIND and so forth, the method must be a valid
+// token as defined by RFC 7230.
+func (g *routeGroup) Handle(method string, path string, h ...Handler) *Route {
+	return g.handle(method, path, h)
 }
 
 // Any adds a route & handler to the router for all HTTP methods.
@@ -158,10 +282,10 @@ func (g *routeGroup) Match(methods []string, path string, h ...Handler) {
 }
 
 // WebSocket adds a websocket route
-func (g *routeGroup) WebSocket(upgrader websocket.Upgrader, path string, h Handler) {
+func (g *routeGroup) WebSocket(upgrader websocket.Upgrader, path string, h Handler) *Route {
 
 	handler := g.lars.wrapHandler(h)
-	g.Get(path, func(c Context) {
+	return g.Get(path, func(c Context) {
 
 		ctx := c.BaseContext()
 		var err error
@@ -176,31 +300,145 @@ func (g *routeGroup) WebSocket(upgrader websocket.Upgrader, path string, h Handl
 	}, handler)
 }
 
-// Group creates a new sub router with prefix. It inherits all properties from
-// the parent. Passing middleware overrides parent middleware but still keeps
-// the root level middleware intact.
+// Group creates a new sub router with prefix, joined to the parent's prefix.
+// It inherits all properties from the parent including it's middleware chain,
+// any middleware passed is run after the parent's. Passing nil as the only
+// middleware creates a group with no middleware at all.
 func (g *routeGroup) Group(prefix string, middleware ...Handler) IRouteGroup {
 
 	rg := &routeGroup{
-		prefix: g.prefix + prefix,
+		prefix: joinPaths(g.prefix, prefix),
 		lars:   g.lars,
+		host:   g.host,
 	}
 
-	if len(middleware) == 0 {
-		rg.middleware = make(HandlersChain, len(g.middleware)+len(middleware))
-		copy(rg.middleware, g.middleware)
-
-		return rg
-	}
-
-	if middleware[0] == nil {
+	if len(middleware) > 0 && middleware[0] == nil {
 		rg.middleware = make(HandlersChain, 0)
 		return rg
 	}
 
-	rg.middleware = make(HandlersChain, len(middleware))
-	copy(rg.middleware, g.lars.middleware)
+	rg.middleware = make(HandlersChain, len(g.middleware), len(g.middleware)+len(middleware))
+	copy(rg.middleware, g.middleware)
+
+	rg.middlewareNames = make([]string, len(g.middlewareNames), len(g.middlewareNames)+len(middleware))
+	copy(rg.middlewareNames, g.middlewareNames)
+
 	rg.Use(middleware...)
 
 	return rg
 }
+
+// Register404 overrides the not found handlers for requests below the group's prefix,
+// i.e. JSON errors under /api, the group's middleware is run before them; when groups
+// are nested the one with the longest matching prefix is chosen.
+func (g *routeGroup) Register404(notFound ...Handler) {
+
+	chain := make(HandlersChain, len(notFound))
+
+	for i, h := range notFound {
+		chain[i] = g.lars.wrapHandler(h)
+	}
+
+	g.lars.routesMu.Lock()
+	defer g.lars.routesMu.Unlock()
+
+	g.http404 = chain
+	g.buildErrorChains()
+	g.lars.addErrorGroup(g)
+}
+
+// Register405 overrides the method not allowed handlers for requests below the group's
+// prefix, the group's middleware is run before them; when groups are nested the one with
+// the longest matching prefix is chosen.
+// NOTE: the Allow header of the response is populated the same as for l.Register405
+func (g *routeGroup) Register405(notAllowed ...Handler) {
+
+	chain := make(HandlersChain, len(notAllowed))
+
+	for i, h := range notAllowed {
+		chain[i] = g.lars.wrapHandler(h)
+	}
+
+	g.lars.routesMu.Lock()
+	defer g.lars.routesMu.Unlock()
+
+	g.http405 = chain
+	g.buildErrorChains()
+	g.lars.addErrorGroup(g)
+}
+
+// buildErrorChains builds the group's not found and method not allowed chains, of it's
+// middleware followed by the handlers, so they can be registered while serving; they're
+// built again by Serve to include any middleware added since.
+func (g *routeGroup) buildErrorChains() {
+
+	if g.http404 != nil {
+		g.notFound = make(HandlersChain, len(g.middleware)+len(g.http404))
+		copy(g.notFound, g.middleware)
+		copy(g.notFound[len(g.middleware):], g.http404)
+	}
+
+	if g.http405 != nil {
+		g.notAllowed = make(HandlersChain, len(g.middleware)+len(g.http405))
+		copy(g.notAllowed, g.middleware)
+		copy(g.notAllowed[len(g.middleware):], g.http405)
+	}
+}
+
+func (l *LARS) addErrorGroup(g *routeGroup) {
+
+	for _, eg := range l.errorGroups {
+		if eg == g {
+			return
+		}
+	}
+
+	l.errorGroups = append(l.errorGroups, g)
+}
+
+// errorGroup returns the group, registered for the host, with the longest prefix matching
+// path that has it's own 405 handlers when notAllowed or otherwise 404 handlers; nil
+// when there's none.
+func (l *LARS) errorGroup(h *vhost, path string, notAllowed bool) (group *routeGroup) {
+
+	longest := -1
+
+	for _, g := range l.errorGroups {
+
+		if g.host != h || (notAllowed && g.http405 == nil) || (!notAllowed && g.http404 == nil) {
+			continue
+		}
+
+		if n := matchPrefix(g.prefix, path); n > longest {
+			group, longest = g, n
+		}
+	}
+
+	return
+}
+
+// matchPrefix returns the number of segments of the group prefix matching the start of
+// path, params match any segment, or -1 when it doesn't match.
+func matchPrefix(prefix, path string) int {
+
+	prefix = strings.Trim(prefix, basePath)
+	if prefix == blank {
+		return 0
+	}
+
+	segments := strings.Split(prefix, basePath)
+	parts := strings.SplitN(strings.TrimPrefix(path, basePath), basePath, len(segments)+1)
+
+	for i, s := range segments {
+
+		if s[0] == wildByte {
+			return len(segments)
+		}
+
+		if i >= len(parts) || parts[i] == blank || (s[0] != paramByte && s != parts[i]) {
+			return -1
+		}
+	}
+
+	return len(segments)
+}
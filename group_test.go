@@ -22,6 +22,139 @@ import (
 // go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
 //
 
+func TestWebSocketUpgrade(t *testing.T) {
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: WebSocketOrigins("http://localhost", "https://example.com"),
+	}
+
+	l := New()
+	l.Get("/ws", func(c Context) error {
+		return c.Upgrade(upgrader, func(conn *websocket.Conn) {
+
+			Equal(t, c.WebSocket() == conn, true)
+
+			messageType, b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if err = conn.WriteMessage(messageType, b); err != nil {
+				panic(err)
+			}
+		})
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	url := fmt.Sprintf("ws://%s/ws", server.Listener.Addr().String())
+
+	header := make(http.Header, 0)
+	header.Set(Origin, "https://EXAMPLE.com")
+
+	ws, _, err := websocket.DefaultDialer.Dial(url, header)
+	Equal(t, err, nil)
+
+	defer ws.Close()
+
+	err = ws.WriteMessage(websocket.TextMessage, []byte("upgraded"))
+	Equal(t, err, nil)
+
+	typ, b, err := ws.ReadMessage()
+	Equal(t, err, nil)
+	Equal(t, typ, websocket.TextMessage)
+	Equal(t, string(b), "upgraded")
+
+	header.Set(Origin, "https://evil.com")
+
+	wsBad, res, err := websocket.DefaultDialer.Dial(url, header)
+	NotEqual(t, err, nil)
+	Equal(t, wsBad, nil)
+	Equal(t, res.StatusCode, http.StatusForbidden)
+
+	wsNoOrigin, _, err := websocket.DefaultDialer.Dial(url, nil)
+	Equal(t, err, nil)
+	wsNoOrigin.Close()
+}
+
+func TestNestedGroups(t *testing.T) {
+
+	var order []string
+
+	mw := func(name string) HandlerFunc {
+		return func(c Context) {
+			order = append(order, name)
+			c.Next()
+		}
+	}
+
+	fn := func(c Context) {
+		c.Text(http.StatusOK, c.Request().URL.Path+" "+c.Param("id")+c.Param("uid"))
+	}
+
+	l := New()
+	l.Use(mw("root"))
+
+	admin := l.Group("/admin/", mw("admin"))
+	users := admin.Group("/users", mw("users"), mw("users2"))
+	users.Get("", fn)
+	users.Get("/id/:uid", fn)
+
+	empty := users.Group("")
+	empty.Get("/empty", fn)
+
+	noMiddleware := users.Group("/none", nil)
+	noMiddleware.Get("", fn)
+
+	accounts := l.Group("/accounts/:id").Group("/users/", mw("accounts"))
+	accounts.Get(":uid", fn)
+
+	code, body := request(GET, "/admin/users", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/admin/users ")
+	Equal(t, order, []string{"root", "admin", "users", "users2"})
+
+	order = nil
+	code, body = request(GET, "/admin/users/id/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/admin/users/id/13 13")
+	Equal(t, order, []string{"root", "admin", "users", "users2"})
+
+	order = nil
+	code, body = request(GET, "/admin/users/empty", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/admin/users/empty ")
+	Equal(t, order, []string{"root", "admin", "users", "users2"})
+
+	order = nil
+	code, body = request(GET, "/admin/users/none", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/admin/users/none ")
+	Equal(t, len(order), 0)
+
+	order = nil
+	code, body = request(GET, "/accounts/1/users/2", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/accounts/1/users/2 12")
+	Equal(t, order, []string{"root", "accounts"})
+
+	// a prefix ending in a slash with a route starting with one
+	settings := l.Group("/settings/")
+	settings.Get("/profile", fn)
+
+	order = nil
+	code, body = request(GET, "/settings/profile", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/settings/profile ")
+	Equal(t, order, []string{"root"})
+
+	Equal(t, settings.Remove(GET, "/profile"), true)
+
+	code, _ = request(GET, "/settings/profile", l)
+	Equal(t, code, http.StatusNotFound)
+}
+
 func TestWebsockets(t *testing.T) {
 
 	origin := "http://localhost"
@@ -81,3 +214,97 @@ func TestWebsockets(t *testing.T) {
 	Equal(t, wsBad, nil)
 	Equal(t, res.StatusCode, http.StatusForbidden)
 }
+
+func TestGroupNotFoundAndMethodNotAllowed(t *testing.T) {
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.Get("/home", basicHandler)
+
+	api := l.Group("/api", func(c Context) {
+		c.Response().Header().Set("X-API", "true")
+		c.Next()
+	})
+	api.Get("/users", basicHandler)
+	api.Register404(func(c Context) {
+		c.JSON(http.StatusNotFound, map[string]string{"message": "api not found"})
+	})
+	api.Register405(func(c Context) {
+		c.JSON(http.StatusMethodNotAllowed, map[string]string{"message": "api method not allowed"})
+	})
+
+	v2 := api.Group("/v2/:tenant")
+	v2.Get("/users", basicHandler)
+	v2.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "v2 not found "+c.Request().URL.Path)
+	})
+
+	hf := l.Serve()
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(GET, "/missing")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "Not Found\n")
+
+	w = serve(GET, "/apis")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "Not Found\n")
+
+	w = serve(GET, "/api/missing")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Header().Get("X-API"), "true")
+	Equal(t, w.Body.String(), `{"message":"api not found"}`)
+
+	w = serve(GET, "/api")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), `{"message":"api not found"}`)
+
+	w = serve(GET, "/api/v2/acme/missing")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Header().Get("X-API"), "true")
+	Equal(t, w.Body.String(), "v2 not found /api/v2/acme/missing")
+
+	w = serve(GET, "/api/v2")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), `{"message":"api not found"}`)
+
+	// v2 has no 405 handlers of it's own
+	w = serve(POST, "/api/v2/acme/users")
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Header().Get(Allow), GET)
+	Equal(t, w.Header().Get("X-API"), "true")
+	Equal(t, w.Body.String(), `{"message":"api method not allowed"}`)
+
+	w = serve(POST, "/home")
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Body.String(), "")
+
+	// registered while serving
+	admin := l.Group("/admin", func(c Context) {
+		c.Response().Header().Set("X-Admin", "true")
+		c.Next()
+	})
+	admin.Get("/users", basicHandler)
+	admin.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "admin not found")
+	})
+	admin.Register405(func(c Context) {
+		c.Text(http.StatusMethodNotAllowed, "admin method not allowed")
+	})
+
+	w = serve(GET, "/admin/missing")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Header().Get("X-Admin"), "true")
+	Equal(t, w.Body.String(), "admin not found")
+
+	w = serve(POST, "/admin/users")
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Header().Get("X-Admin"), "true")
+	Equal(t, w.Body.String(), "admin method not allowed")
+}
@@ -0,0 +1,302 @@
+package lars
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	healthUp   = "up"
+	healthDown = "down"
+
+	defaultLivenessPath   = "/livez"
+	defaultReadinessPath  = "/readyz"
+	defaultHealthTimeout  = 5 * time.Second
+	shutdownHealthCheck   = "shutdown"
+	notReadyHealthCheck   = "ready"
+	healthShuttingDownErr = "shutting down"
+	healthNotReadyErr     = "not ready"
+)
+
+// HealthCheck checks a dependency of the app, such as a database connection, returning
+// an error when it's unhealthy; ctx is done once the check's timeout elapses.
+type HealthCheck func(ctx context.Context) error
+
+// HealthConfig configures the health check endpoints registered using ServeHealth
+type HealthConfig struct {
+	// LivenessPath is the path the liveness checks are served at. default "/livez"
+	LivenessPath string
+
+	// ReadinessPath is the path the readiness checks are served at. default "/readyz"
+	ReadinessPath string
+
+	// Timeout is the maximum amount of time a check may take. default 5 seconds
+	Timeout time.Duration
+
+	// CacheFor is how long the result of a check is reused for, protecting dependencies
+	// from frequent probes. default 0 (checks run on every request)
+	CacheFor time.Duration
+
+	// Interval, when set, runs the checks periodically in the background, stopped by
+	// Shutdown, and the endpoints report the latest results. default 0 (disabled)
+	Interval time.Duration
+}
+
+// HealthReport is the JSON body of the health check endpoints
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the result of a single health check
+type HealthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+
+	// result is the last result of the check, checked at
+	result  HealthCheckResult
+	checked time.Time
+}
+
+// healthChecks are the registered liveness and readiness checks
+type healthChecks struct {
+	mu        sync.Mutex
+	config    HealthConfig
+	liveness  []*namedCheck
+	readiness []*namedCheck
+	paths     map[string]struct{}
+	stop      chan struct{}
+}
+
+// AddLivenessCheck registers a named check reporting whether the app is alive, a
+// failing liveness check usually gets the app restarted; only add checks the app can't
+// recover from by itself. Must be added before serving begins.
+func (l *LARS) AddLivenessCheck(name string, check HealthCheck) {
+	l.health.liveness = append(l.health.liveness, &namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck registers a named check reporting whether the app can serve traffic
+// i.e. it's database is reachable. Must be added before serving begins.
+func (l *LARS) AddReadinessCheck(name string, check HealthCheck) {
+	l.health.readiness = append(l.health.readiness, &namedCheck{name: name, check: check})
+}
+
+// ServeHealth registers GET routes serving the aggregated results of the liveness and
+// readiness checks as a JSON HealthReport, responding 503 Service Unavailable when any
+// check fails. The readiness endpoint also fails while SetReady(false) is in effect and
+// once Shutdown has begun, so load balancers drain traffic; both endpoints are still
+// routed during those times and are flagged Silent.
+func (l *LARS) ServeHealth(config HealthConfig) {
+
+	if config.LivenessPath == blank {
+		config.LivenessPath = defaultLivenessPath
+	}
+
+	if config.ReadinessPath == blank {
+		config.ReadinessPath = defaultReadinessPath
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = defaultHealthTimeout
+	}
+
+	h := &l.health
+	h.config = config
+	h.paths = map[string]struct{}{
+		config.LivenessPath:  {},
+		config.ReadinessPath: {},
+	}
+
+	l.Get(config.LivenessPath, func(c Context) error {
+		return l.healthReport(c, h.liveness, false)
+	}).Silent()
+
+	l.Get(config.ReadinessPath, func(c Context) error {
+		return l.healthReport(c, h.readiness, true)
+	}).Silent()
+
+	if config.Interval > 0 && h.stop == nil {
+
+		h.stop = make(chan struct{})
+
+		go h.runPeriodically(append(append([]*namedCheck(nil), h.liveness...), h.readiness...), h.stop)
+	}
+}
+
+func (l *LARS) healthReport(c Context, checks []*namedCheck, readiness bool) error {
+
+	report := l.health.run(c.Request().Context(), checks)
+
+	if readiness {
+
+		if atomic.LoadInt32(&l.inFlight.draining) == 1 {
+			report.fail(shutdownHealthCheck, healthShuttingDownErr)
+		} else if atomic.LoadInt32(&l.notReady) == 1 {
+			report.fail(notReadyHealthCheck, healthNotReadyErr)
+		}
+	}
+
+	code := http.StatusOK
+
+	if report.Status == healthDown {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.Response().Header().Set(CacheControl, "no-store")
+
+	return c.JSON(code, report)
+}
+
+func (r *HealthReport) fail(name, err string) {
+
+	if r.Checks == nil {
+		r.Checks = make(map[string]HealthCheckResult)
+	}
+
+	r.Status = healthDown
+	r.Checks[name] = HealthCheckResult{Status: healthDown, Error: err}
+}
+
+// isHealthPath returns whether path is one of the health check endpoints, which are
+// routed even when the app isn't ready or is shutting down.
+func (h *healthChecks) isHealthPath(path string) bool {
+	_, ok := h.paths[path]
+	return ok
+}
+
+// run runs the checks concurrently, reusing results that are still fresh, and
+// aggregates the results.
+func (h *healthChecks) run(ctx context.Context, checks []*namedCheck) HealthReport {
+
+	report := HealthReport{Status: healthUp}
+
+	if len(checks) == 0 {
+		return report
+	}
+
+	report.Checks = make(map[string]HealthCheckResult, len(checks))
+
+	// results which are reused aren't bound to the probe's request, a client going away
+	// would otherwise be reported to every prober until the result is stale
+	if h.config.CacheFor > 0 || h.config.Interval > 0 {
+		ctx = context.Background()
+	}
+
+	var wg sync.WaitGroup
+
+	for _, nc := range checks {
+
+		h.mu.Lock()
+		fresh := !nc.checked.IsZero() && (h.config.Interval > 0 || time.Since(nc.checked) < h.config.CacheFor)
+		h.mu.Unlock()
+
+		if fresh {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(nc *namedCheck) {
+			defer wg.Done()
+			h.check(ctx, nc)
+		}(nc)
+	}
+
+	wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, nc := range checks {
+
+		report.Checks[nc.name] = nc.result
+
+		if nc.result.Status == healthDown {
+			report.Status = healthDown
+		}
+	}
+
+	return report
+}
+
+// check runs a single check, within the configured timeout, and stores it's result.
+func (h *healthChecks) check(ctx context.Context, nc *namedCheck) {
+
+	ctx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- nc.check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := HealthCheckResult{Status: healthUp}
+
+	if err != nil {
+		result = HealthCheckResult{Status: healthDown, Error: err.Error()}
+	}
+
+	h.mu.Lock()
+	nc.result = result
+	nc.checked = time.Now()
+	h.mu.Unlock()
+}
+
+// runPeriodically runs the checks every interval until stopped.
+func (h *healthChecks) runPeriodically(checks []*namedCheck, stop <-chan struct{}) {
+
+	ticker := time.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+
+	for {
+
+		var wg sync.WaitGroup
+
+		for _, nc := range checks {
+
+			wg.Add(1)
+
+			go func(nc *namedCheck) {
+				defer wg.Done()
+				h.check(context.Background(), nc)
+			}(nc)
+		}
+
+		wg.Wait()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopPeriodic stops the checks being run periodically
+func (h *healthChecks) stopPeriodic() {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stop != nil {
+		close(h.stop)
+		h.stop = nil
+	}
+}
@@ -0,0 +1,212 @@
+package lars
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestHealth(t *testing.T) {
+
+	var dbCalls int32
+	var dbErr atomic.Value
+	dbErr.Store("")
+
+	l := New()
+	l.AddLivenessCheck("goroutines", func(ctx context.Context) error { return nil })
+	l.AddReadinessCheck("db", func(ctx context.Context) error {
+
+		atomic.AddInt32(&dbCalls, 1)
+
+		if err := dbErr.Load().(string); err != "" {
+			return errors.New(err)
+		}
+
+		return nil
+	})
+	l.AddReadinessCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	l.ServeHealth(HealthConfig{Timeout: time.Millisecond * 10})
+	l.Get("/home", basicHandler)
+
+	hf := l.Serve()
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/livez")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(CacheControl), "no-store")
+	Equal(t, w.Body.String(), `{"status":"up","checks":{"goroutines":{"status":"up"}}}`)
+
+	// the slow check times out
+	w = serve("/readyz")
+	Equal(t, w.Code, http.StatusServiceUnavailable)
+	Equal(t, w.Body.String(), `{"status":"down","checks":{"db":{"status":"up"},"slow":{"status":"down","error":"context deadline exceeded"}}}`)
+
+	dbErr.Store("connection refused")
+
+	w = serve("/readyz")
+	Equal(t, w.Code, http.StatusServiceUnavailable)
+	Equal(t, w.Body.String(), `{"status":"down","checks":{"db":{"status":"down","error":"connection refused"},"slow":{"status":"down","error":"context deadline exceeded"}}}`)
+	Equal(t, atomic.LoadInt32(&dbCalls), int32(2))
+
+	// not ready, only the health checks are routed
+	l.SetReady(false)
+
+	Equal(t, serve("/home").Code, http.StatusServiceUnavailable)
+	Equal(t, serve("/livez").Code, http.StatusOK)
+
+	w = serve("/readyz")
+	Equal(t, w.Code, http.StatusServiceUnavailable)
+	MatchRegex(t, w.Body.String(), `"ready":\{"status":"down","error":"not ready"\}`)
+
+	l.SetReady(true)
+
+	// shutting down
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	Equal(t, l.Shutdown(ctx), nil)
+
+	Equal(t, serve("/home").Code, http.StatusServiceUnavailable)
+	Equal(t, serve("/livez").Code, http.StatusOK)
+
+	w = serve("/readyz")
+	Equal(t, w.Code, http.StatusServiceUnavailable)
+	MatchRegex(t, w.Body.String(), `"shutdown":\{"status":"down","error":"shutting down"\}`)
+}
+
+func TestHealthCachedAndPeriodic(t *testing.T) {
+
+	var calls int32
+
+	check := func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	l := New()
+	l.AddReadinessCheck("db", check)
+	l.ServeHealth(HealthConfig{LivenessPath: "/health/live", ReadinessPath: "/health/ready", CacheFor: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		code, body := request(GET, "/health/ready", l)
+		Equal(t, code, http.StatusOK)
+		Equal(t, body, `{"status":"up","checks":{"db":{"status":"up"}}}`)
+	}
+
+	Equal(t, atomic.LoadInt32(&calls), int32(1))
+
+	code, body := request(GET, "/health/live", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, `{"status":"up"}`)
+
+	atomic.StoreInt32(&calls, 0)
+
+	l = New()
+	l.AddReadinessCheck("db", check)
+	l.ServeHealth(HealthConfig{Interval: time.Millisecond * 5})
+
+	time.Sleep(time.Millisecond * 30)
+
+	code, _ = request(GET, "/readyz", l)
+	Equal(t, code, http.StatusOK)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	Equal(t, l.Shutdown(ctx), nil)
+
+	n := atomic.LoadInt32(&calls)
+	Equal(t, n > 1, true)
+
+	time.Sleep(time.Millisecond * 20)
+	Equal(t, atomic.LoadInt32(&calls) <= n+1, true)
+}
+
+func TestHealthShutdownDelay(t *testing.T) {
+
+	l := New()
+	l.SetShutdownDelay(time.Millisecond * 200)
+	l.ServeHealth(HealthConfig{})
+	l.Get("/home", basicHandler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan error)
+
+	go func() {
+		done <- l.Shutdown(ctx)
+	}()
+
+	time.Sleep(time.Millisecond * 50)
+
+	// readiness fails while requests are still served
+	code, body := request(GET, "/readyz", l)
+	Equal(t, code, http.StatusServiceUnavailable)
+	MatchRegex(t, body, `"shutdown":\{"status":"down","error":"shutting down"\}`)
+
+	code, _ = request(GET, "/home", l)
+	Equal(t, code, http.StatusOK)
+
+	Equal(t, <-done, nil)
+
+	code, _ = request(GET, "/home", l)
+	Equal(t, code, http.StatusServiceUnavailable)
+}
+
+func TestHealthCachedProbeCancelled(t *testing.T) {
+
+	l := New()
+	l.AddReadinessCheck("db", func(ctx context.Context) error {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond * 20):
+			return nil
+		}
+	})
+	l.ServeHealth(HealthConfig{CacheFor: time.Hour})
+
+	hf := l.Serve()
+
+	// the prober goes away before the check completes
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r, _ := http.NewRequest(GET, "/readyz", nil)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r.WithContext(ctx))
+
+	Equal(t, w.Code, http.StatusOK)
+
+	code, body := request(GET, "/readyz", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, `{"status":"up","checks":{"db":{"status":"up"}}}`)
+}
@@ -0,0 +1,103 @@
+package lars
+
+import (
+	"sort"
+	"strings"
+)
+
+// vhost contains the route trees of the routes registered for a host
+type vhost struct {
+	pattern string
+	// suffix is the part of a wildcard pattern following the *, i.e. ".example.com"
+	suffix string
+	trees  map[string]*node
+}
+
+// Host creates a new sub router whose routes are only matched for requests to
+// the given host, the port is ignored and matching is case insensitive. A
+// pattern beginning with "*." matches any single subdomain, which is available
+// as c.Param(lars.SubdomainParam), i.e. "*.tenants.example.com"; exact hosts
+// take precedence over wildcards, and the longest wildcard wins.
+//
+// Requests matching a host are routed using only it's routes, all other requests
+// use the routes registered without a host. The returned group behaves as any
+// other group, including inheriting the current middleware, and calling Host
+// again with the same pattern adds to the same routes.
+func (g *routeGroup) Host(pattern string, middleware ...Handler) IRouteGroup {
+
+	pattern = strings.ToLower(pattern)
+
+	if pattern == blank || strings.IndexByte(pattern, wildByte) > 0 || strings.Count(pattern, "*") > 1 || (pattern[0] == wildByte && !strings.HasPrefix(pattern, "*.")) {
+		panic("invalid host pattern '" + pattern + "'")
+	}
+
+	l := g.lars
+
+	l.routesMu.Lock()
+	defer l.routesMu.Unlock()
+
+	h, ok := l.hosts[pattern]
+	if !ok {
+
+		h = &vhost{
+			pattern: pattern,
+			trees:   make(map[string]*node),
+		}
+
+		if l.hosts == nil {
+			l.hosts = make(map[string]*vhost)
+		}
+
+		l.hosts[pattern] = h
+
+		if pattern[0] == wildByte {
+			h.suffix = pattern[1:]
+			l.wildcardHosts = append(l.wildcardHosts, h)
+			sort.Sort(vhostsBySuffix(l.wildcardHosts))
+		}
+	}
+
+	rg := g.Group(blank, middleware...).(*routeGroup)
+	rg.host = h
+
+	return rg
+}
+
+// matchHost returns the registered host and subdomain for the request's host,
+// nil is returned, for the routes registered without a host, when the host
+// doesn't match any registered using Host.
+func (l *LARS) matchHost(host string) (h *vhost, subdomain string, wildcard bool) {
+
+	if len(l.hosts) == 0 {
+		return nil, blank, false
+	}
+
+	// strip the port, taking care of IPv6 literals i.e. [::1]:8080
+	if i := strings.LastIndexByte(host, paramByte); i != -1 && strings.IndexByte(host[i:], ']') == -1 {
+		host = host[:i]
+	}
+
+	host = strings.ToLower(host)
+
+	if h, ok := l.hosts[host]; ok && h.suffix == blank {
+		return h, blank, false
+	}
+
+	for _, h := range l.wildcardHosts {
+
+		if len(host) > len(h.suffix) && strings.HasSuffix(host, h.suffix) {
+
+			if sub := host[:len(host)-len(h.suffix)]; strings.IndexByte(sub, '.') == -1 {
+				return h, sub, true
+			}
+		}
+	}
+
+	return nil, blank, false
+}
+
+type vhostsBySuffix []*vhost
+
+func (v vhostsBySuffix) Len() int           { return len(v) }
+func (v vhostsBySuffix) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v vhostsBySuffix) Less(i, j int) bool { return len(v[i].suffix) > len(v[j].suffix) }
@@ -0,0 +1,131 @@
+package lars
+
+import (
+	"net/http"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestHost(t *testing.T) {
+
+	text := func(s string) HandlerFunc {
+		return func(c Context) {
+			c.Text(http.StatusOK, s+" "+c.Param(SubdomainParam)+" "+c.Param("id"))
+		}
+	}
+
+	l := New()
+	l.Use(func(c Context) {
+		c.Response().Header().Set("X-Global", "1")
+		c.Next()
+	})
+	l.Get("/users/:id", text("default"))
+
+	api := l.Host("API.example.com")
+	api.Get("/users/:id", text("api"))
+	api.Group("/v2").Get("/users/:id", text("api-v2"))
+
+	// same host adds to the same routes
+	l.Host("api.example.com").Post("/users", text("api-post"))
+
+	tenants := l.Host("*.tenants.example.com")
+	tenants.Get(`/users/:id(\d+)`, text("tenant"))
+
+	l.Host("*.eu.tenants.example.com").Get("/users/:id", text("eu-tenant"))
+
+	code, body := request(GET, "http://api.example.com/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "api  13")
+
+	code, body = request(GET, "http://Api.Example.com:8080/v2/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "api-v2  13")
+
+	code, body = request(POST, "http://api.example.com/users", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "api-post  ")
+
+	code, body = request(GET, "http://acme.tenants.example.com/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "tenant acme 13")
+
+	// constraints account for the subdomain param
+	code, _ = request(GET, "http://acme.tenants.example.com/users/joeybloggs", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "http://acme.eu.tenants.example.com/users/joeybloggs", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "eu-tenant acme joeybloggs")
+
+	// only a single subdomain is matched
+	code, body = request(GET, "http://a.b.tenants.example.com/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "default  13")
+
+	code, body = request(GET, "http://tenants.example.com/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "default  13")
+
+	code, body = request(GET, "http://[::1]:8080/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "default  13")
+
+	code, body = request(GET, "/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "default  13")
+
+	// a matched host only uses it's own routes
+	code, _ = request(GET, "http://api.example.com/v2/users", l)
+	Equal(t, code, http.StatusNotFound)
+
+	routes := l.Routes()
+	Equal(t, len(routes), 6)
+	Equal(t, routes[0].Host, "")
+	Equal(t, routes[1].Host, "*.eu.tenants.example.com")
+	Equal(t, routes[2].Host, "*.tenants.example.com")
+	Equal(t, routes[2].Path, `/users/:id(\d+)`)
+	Equal(t, routes[3].Host, "api.example.com")
+	Equal(t, routes[3].Method, POST)
+	Equal(t, routes[5].Path, "/v2/users/:id")
+
+	PanicMatches(t, func() { l.Host("") }, "invalid host pattern ''")
+	PanicMatches(t, func() { l.Host("api.*.example.com") }, "invalid host pattern 'api.*.example.com'")
+	PanicMatches(t, func() { l.Host("*example.com") }, "invalid host pattern '*example.com'")
+	PanicMatches(t, func() { l.Host("*.*.example.com") }, "invalid host pattern '*.*.example.com'")
+}
+
+func TestHostMethodNotAllowedAndOptions(t *testing.T) {
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.SetAutomaticallyHandleOPTIONS(true)
+	l.Post("/users", basicHandler)
+	l.Host("*.example.com").Put(`/users/:id(\d+)`, basicHandler)
+
+	code, _ := request(GET, "http://acme.example.com/users/1", l)
+	Equal(t, code, http.StatusMethodNotAllowed)
+
+	code, _ = request(GET, "http://acme.example.com/users/a", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(OPTIONS, "http://acme.example.com/users/1", l)
+	Equal(t, code, http.StatusNoContent)
+
+	code, _ = request(GET, "http://acme.example.com/users", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(GET, "/users", l)
+	Equal(t, code, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,299 @@
+package lars
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultHubSendBuffer   = 256
+	defaultHubWriteTimeout = 10 * time.Second
+)
+
+// ErrHubConnClosed is returned when sending to a HubConn which is closed, or was
+// closed because it couldn't keep up with the messages sent to it
+var ErrHubConnClosed = errors.New("websocket connection closed")
+
+// Hub keeps track of websocket connections, and the rooms they've joined, so
+// messages can be broadcast to all of them, a room or sent to one connection.
+type Hub struct {
+	// OnConnect is called once a connection is registered, before any of it's
+	// messages are read
+	OnConnect func(conn *HubConn)
+
+	// OnMessage is called with every message read from a connection
+	OnMessage func(conn *HubConn, messageType int, data []byte)
+
+	// OnDisconnect is called once a connection is closed and has been removed
+	// from the hub and it's rooms
+	OnDisconnect func(conn *HubConn)
+
+	// SendBuffer is the number of messages queued per connection, a connection
+	// whose queue is full is too slow and gets closed. default 256
+	SendBuffer int
+
+	// WriteTimeout is the maximum time writing a message may take. default 10s
+	WriteTimeout time.Duration
+
+	mu    sync.RWMutex
+	conns map[*HubConn]struct{}
+	rooms map[string]map[*HubConn]struct{}
+}
+
+// HubConn is a websocket connection registered with a Hub, it's safe for concurrent use
+type HubConn struct {
+	hub    *Hub
+	ws     *websocket.Conn
+	ctx    Context
+	send   chan hubMessage
+	done   chan struct{}
+	rooms  map[string]struct{}
+	closed bool
+	mu     sync.Mutex
+}
+
+type hubMessage struct {
+	messageType int
+	data        []byte
+}
+
+// NewHub returns a new, empty, Hub
+func NewHub() *Hub {
+	return &Hub{
+		conns: make(map[*HubConn]struct{}),
+		rooms: make(map[string]map[*HubConn]struct{}),
+	}
+}
+
+// Serve upgrades the request to a websocket connection, using c.Upgrade, and registers
+// it with the hub; it blocks, reading the connection's messages and passing them to
+// OnMessage, until the connection is closed.
+func (h *Hub) Serve(c Context, upgrader websocket.Upgrader) error {
+	return c.Upgrade(upgrader, func(ws *websocket.Conn) {
+
+		size := h.SendBuffer
+		if size <= 0 {
+			size = defaultHubSendBuffer
+		}
+
+		conn := &HubConn{
+			hub:   h,
+			ws:    ws,
+			ctx:   c,
+			send:  make(chan hubMessage, size),
+			done:  make(chan struct{}),
+			rooms: make(map[string]struct{}),
+		}
+
+		h.mu.Lock()
+		h.conns[conn] = struct{}{}
+		h.mu.Unlock()
+
+		go conn.writeLoop()
+
+		if h.OnConnect != nil {
+			h.OnConnect(conn)
+		}
+
+		for {
+			typ, data, err := ws.ReadMessage()
+			if err != nil {
+				break
+			}
+
+			if h.OnMessage != nil {
+				h.OnMessage(conn, typ, data)
+			}
+		}
+
+		h.unregister(conn)
+
+		// the connection is closed by Upgrade once this returns, wait for
+		// the writes in progress to finish
+		<-conn.done
+
+		if h.OnDisconnect != nil {
+			h.OnDisconnect(conn)
+		}
+	})
+}
+
+// unregister removes the connection from the hub and it's rooms and stops it's writes
+func (h *Hub) unregister(conn *HubConn) {
+
+	h.mu.Lock()
+
+	delete(h.conns, conn)
+
+	for room := range conn.rooms {
+		h.leave(conn, room)
+	}
+
+	h.mu.Unlock()
+
+	conn.mu.Lock()
+
+	if !conn.closed {
+		conn.closed = true
+		close(conn.send)
+	}
+
+	conn.mu.Unlock()
+}
+
+// leave removes the connection from room, the hub's lock must be held
+func (h *Hub) leave(conn *HubConn, room string) {
+
+	delete(conn.rooms, room)
+
+	if members, ok := h.rooms[room]; ok {
+
+		delete(members, conn)
+
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Broadcast sends the message to every connection
+func (h *Hub) Broadcast(messageType int, data []byte) {
+
+	h.mu.RLock()
+	conns := make([]*HubConn, 0, len(h.conns))
+
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+
+	h.mu.RUnlock()
+
+	for _, conn := range conns {
+		conn.Send(messageType, data)
+	}
+}
+
+// BroadcastRoom sends the message to every connection which joined room
+func (h *Hub) BroadcastRoom(room string, messageType int, data []byte) {
+
+	h.mu.RLock()
+	conns := make([]*HubConn, 0, len(h.rooms[room]))
+
+	for conn := range h.rooms[room] {
+		conns = append(conns, conn)
+	}
+
+	h.mu.RUnlock()
+
+	for _, conn := range conns {
+		conn.Send(messageType, data)
+	}
+}
+
+// Len returns the number of connections registered with the hub
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// RoomLen returns the number of connections which joined room
+func (h *Hub) RoomLen(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Context returns the Context of the request the connection was upgraded from, it's
+// valid until the connection is closed.
+func (conn *HubConn) Context() Context {
+	return conn.ctx
+}
+
+// Conn returns the underlying websocket connection, it must not be written to directly
+func (conn *HubConn) Conn() *websocket.Conn {
+	return conn.ws
+}
+
+// Join adds the connection to room
+func (conn *HubConn) Join(room string) {
+
+	h := conn.hub
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// closed connections are no longer registered
+	if _, ok := h.conns[conn]; !ok {
+		return
+	}
+
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[*HubConn]struct{})
+		h.rooms[room] = members
+	}
+
+	members[conn] = struct{}{}
+	conn.rooms[room] = struct{}{}
+}
+
+// Leave removes the connection from room
+func (conn *HubConn) Leave(room string) {
+	conn.hub.mu.Lock()
+	conn.hub.leave(conn, room)
+	conn.hub.mu.Unlock()
+}
+
+// Send queues the message to be sent to the connection, when the connection's queue
+// is full it's closed as it can't keep up.
+func (conn *HubConn) Send(messageType int, data []byte) error {
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	if conn.closed {
+		return ErrHubConnClosed
+	}
+
+	select {
+	case conn.send <- hubMessage{messageType: messageType, data: data}:
+		return nil
+	default:
+		conn.ws.Close()
+		return ErrHubConnClosed
+	}
+}
+
+// Close sends a close message and closes the connection
+func (conn *HubConn) Close() error {
+
+	conn.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, blank), time.Now().Add(time.Second))
+
+	return conn.ws.Close()
+}
+
+// writeLoop writes the queued messages until the queue is closed or a write fails
+func (conn *HubConn) writeLoop() {
+
+	defer close(conn.done)
+
+	timeout := conn.hub.WriteTimeout
+	if timeout <= 0 {
+		timeout = defaultHubWriteTimeout
+	}
+
+	for msg := range conn.send {
+
+		conn.ws.SetWriteDeadline(time.Now().Add(timeout))
+
+		if err := conn.ws.WriteMessage(msg.messageType, msg.data); err != nil {
+			// unblocks the read loop, which unregisters the connection
+			conn.ws.Close()
+			return
+		}
+	}
+}
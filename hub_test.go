@@ -0,0 +1,160 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestHub(t *testing.T) {
+
+	connected := make(chan *HubConn, 3)
+	disconnected := make(chan *HubConn, 3)
+
+	hub := NewHub()
+	hub.OnConnect = func(conn *HubConn) {
+		conn.Join(conn.Context().Param("room"))
+		connected <- conn
+	}
+	hub.OnMessage = func(conn *HubConn, messageType int, data []byte) {
+
+		msg := string(data)
+
+		switch {
+		case msg == "leave":
+			conn.Leave(conn.Context().Param("room"))
+			conn.Send(messageType, []byte("left"))
+		case msg == "close":
+			conn.Close()
+		case strings.HasPrefix(msg, "all:"):
+			hub.Broadcast(messageType, data)
+		default:
+			hub.BroadcastRoom(conn.Context().Param("room"), messageType, data)
+		}
+	}
+	hub.OnDisconnect = func(conn *HubConn) {
+		Equal(t, conn.Send(websocket.TextMessage, []byte("gone")), ErrHubConnClosed)
+		disconnected <- conn
+	}
+
+	l := New()
+	l.Get("/rooms/:room", func(c Context) error {
+		return hub.Serve(c, websocket.Upgrader{})
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/rooms/"
+
+	dial := func(room string) *websocket.Conn {
+		ws, _, err := websocket.DefaultDialer.Dial(url+room, nil)
+		Equal(t, err, nil)
+		<-connected
+		return ws
+	}
+
+	read := func(ws *websocket.Conn) string {
+		ws.SetReadDeadline(time.Now().Add(time.Second * 5))
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return err.Error()
+		}
+		return string(data)
+	}
+
+	a := dial("go")
+	b := dial("go")
+	c := dial("rust")
+
+	Equal(t, hub.Len(), 3)
+	Equal(t, hub.RoomLen("go"), 2)
+	Equal(t, hub.RoomLen("rust"), 1)
+
+	a.WriteMessage(websocket.TextMessage, []byte("hello gophers"))
+	Equal(t, read(a), "hello gophers")
+	Equal(t, read(b), "hello gophers")
+
+	c.WriteMessage(websocket.TextMessage, []byte("all:hello everyone"))
+	Equal(t, read(a), "all:hello everyone")
+	Equal(t, read(b), "all:hello everyone")
+	Equal(t, read(c), "all:hello everyone")
+
+	b.WriteMessage(websocket.TextMessage, []byte("leave"))
+	Equal(t, read(b), "left")
+	Equal(t, hub.RoomLen("go"), 1)
+
+	a.WriteMessage(websocket.TextMessage, []byte("just me"))
+	Equal(t, read(a), "just me")
+
+	// client closes
+	b.Close()
+	<-disconnected
+	Equal(t, hub.Len(), 2)
+
+	// server closes
+	c.WriteMessage(websocket.TextMessage, []byte("close"))
+	_, _, err := c.ReadMessage()
+	Equal(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), true)
+	<-disconnected
+	Equal(t, hub.Len(), 1)
+	Equal(t, hub.RoomLen("rust"), 0)
+
+	a.Close()
+	<-disconnected
+	Equal(t, hub.Len(), 0)
+	Equal(t, hub.RoomLen("go"), 0)
+}
+
+func TestHubSlowConnection(t *testing.T) {
+
+	done := make(chan error, 1)
+
+	hub := NewHub()
+	hub.SendBuffer = 1
+	hub.OnConnect = func(conn *HubConn) {
+
+		var err error
+
+		// the queue fills up as nothing is written until OnConnect returns
+		for i := 0; i < 3 && err == nil; i++ {
+			err = conn.Send(websocket.TextMessage, []byte("message"))
+		}
+
+		done <- err
+	}
+
+	l := New()
+	l.Get("/", func(c Context) error {
+		return hub.Serve(c, websocket.Upgrader{})
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	Equal(t, err, nil)
+	defer ws.Close()
+
+	Equal(t, <-done, ErrHubConnClosed)
+
+	// upgrade failures are returned
+	code, _ := request(GET, server.URL+"/", l)
+	Equal(t, code, http.StatusBadRequest)
+}
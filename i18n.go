@@ -0,0 +1,167 @@
+package lars
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Catalog maps translation keys to the messages of a locale, messages with args
+// are fmt.Sprintf formats i.e. "greeting": "Hello %s"
+type Catalog map[string]string
+
+// LocaleConfig configures the DetectLocale middleware, blank disables the source
+type LocaleConfig struct {
+	// QueryParam is the query param checked first for the locale i.e. "lang"
+	QueryParam string
+
+	// Cookie is the name of the cookie checked when the query param isn't set
+	Cookie string
+}
+
+// AddCatalog registers the translation catalog of locale, i.e. "en" or "en-GB", used
+// by c.T; adding a catalog for an already registered locale merges the messages.
+// The first locale registered is the default locale, see SetDefaultLocale.
+// NOTE: catalogs must be registered before serving begins.
+func (l *LARS) AddCatalog(locale string, messages Catalog) {
+
+	if l.catalogs == nil {
+		l.catalogs = make(map[string]Catalog)
+	}
+
+	key := strings.ToLower(locale)
+
+	catalog, ok := l.catalogs[key]
+	if !ok {
+		catalog = make(Catalog, len(messages))
+		l.catalogs[key] = catalog
+		l.locales = append(l.locales, locale)
+	}
+
+	for k, v := range messages {
+		catalog[k] = v
+	}
+
+	if l.defaultLocale == blank {
+		l.defaultLocale = locale
+	}
+}
+
+// SetDefaultLocale sets the locale used when one isn't detected, or set using
+// c.SetLocale, and whose messages are used when the request's locale has no
+// translation for a key. default the first locale registered using AddCatalog
+func (l *LARS) SetDefaultLocale(locale string) {
+	l.defaultLocale = locale
+}
+
+// Locales returns the locales, in the order they were registered, that have a
+// translation catalog.
+func (l *LARS) Locales() []string {
+	return append([]string(nil), l.locales...)
+}
+
+// matchLocale returns the registered locale matching tag, trying it's prefixes
+// when there's no catalog for it i.e. en-US falls back to en; blank when none match.
+func (l *LARS) matchLocale(tag string) string {
+
+	for tag != blank {
+
+		for _, locale := range l.locales {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+
+		i := strings.LastIndexByte(tag, '-')
+		if i == -1 {
+			break
+		}
+
+		tag = tag[:i]
+	}
+
+	return blank
+}
+
+// DetectLocale returns a middleware which sets the request's locale, used by c.T,
+// to the first registered locale found in the configured query param, cookie and
+// then Accept-Language header; the default locale is used when none match.
+func DetectLocale(config LocaleConfig) HandlerFunc {
+
+	return func(c Context) {
+
+		ctx := c.BaseContext()
+		l := ctx.lars
+
+		var locale string
+
+		if config.QueryParam != blank {
+			locale = l.matchLocale(c.QueryParam(config.QueryParam))
+		}
+
+		if locale == blank && config.Cookie != blank {
+			if cookie, err := c.Cookie(config.Cookie); err == nil {
+				locale = l.matchLocale(cookie.Value)
+			}
+		}
+
+		if locale == blank {
+			locale = c.NegotiateLanguage(l.locales...)
+		}
+
+		if locale != blank {
+			c.SetLocale(locale)
+		}
+
+		c.Next()
+	}
+}
+
+// Locale returns the request's locale, set using SetLocale or the DetectLocale
+// middleware, falling back to the default locale.
+func (c *Ctx) Locale() string {
+
+	if c.locale == blank {
+		return c.lars.defaultLocale
+	}
+
+	return c.locale
+}
+
+// SetLocale sets the request's locale used by T.
+func (c *Ctx) SetLocale(locale string) {
+	c.locale = locale
+}
+
+// T returns the translation of key in the request's locale, formatting it with args
+// using fmt.Sprintf when provided; the prefixes of the locale, i.e. en for en-US, and
+// then the default locale are tried when it has none and the key is returned when
+// no catalog has a translation.
+func (c *Ctx) T(key string, args ...interface{}) string {
+
+	l := c.lars
+	msg, ok := blank, false
+
+	for tag := strings.ToLower(c.Locale()); tag != blank && !ok; {
+
+		msg, ok = l.catalogs[tag][key]
+
+		i := strings.LastIndexByte(tag, '-')
+		if i == -1 {
+			break
+		}
+
+		tag = tag[:i]
+	}
+
+	if !ok {
+		if msg, ok = l.catalogs[strings.ToLower(l.defaultLocale)][key]; !ok {
+			return key
+		}
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+
+	return msg
+}
@@ -0,0 +1,70 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestTranslations(t *testing.T) {
+
+	l := New()
+	l.AddCatalog("en", Catalog{"greeting": "Hello %s", "bye": "Goodbye", "only": "English only"})
+	l.AddCatalog("en-GB", Catalog{"bye": "Cheerio"})
+	l.AddCatalog("fr", Catalog{"greeting": "Bonjour %s"})
+	l.AddCatalog("fr", Catalog{"bye": "Au revoir"})
+
+	Equal(t, l.Locales(), []string{"en", "en-GB", "fr"})
+
+	l.Use(DetectLocale(LocaleConfig{QueryParam: "lang", Cookie: "lang"}))
+	l.Get("/", func(c Context) {
+		c.Text(http.StatusOK, c.Locale()+":"+c.T("greeting", "Joey")+","+c.T("bye")+","+c.T("only")+","+c.T("missing"))
+	})
+
+	hf := l.Serve()
+
+	serve := func(query, cookie, header string) string {
+		r, _ := http.NewRequest(GET, "/"+query, nil)
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "lang", Value: cookie})
+		}
+		if header != "" {
+			r.Header.Set(AcceptedLanguage, header)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+
+	Equal(t, serve("", "", ""), "en:Hello Joey,Goodbye,English only,missing")
+	Equal(t, serve("", "", "de, fr;q=0.5"), "fr:Bonjour Joey,Au revoir,English only,missing")
+	Equal(t, serve("", "", "en-gb"), "en-GB:Hello Joey,Cheerio,English only,missing")
+	Equal(t, serve("", "fr", "en"), "fr:Bonjour Joey,Au revoir,English only,missing")
+	Equal(t, serve("", "de", "fr"), "fr:Bonjour Joey,Au revoir,English only,missing")
+	Equal(t, serve("?lang=en-GB", "fr", "fr"), "en-GB:Hello Joey,Cheerio,English only,missing")
+	Equal(t, serve("?lang=fr-CA", "", ""), "fr:Bonjour Joey,Au revoir,English only,missing")
+
+	// regional locale without a catalog falls back to it's language
+	c := NewContext(l)
+	c.SetLocale("en-AU")
+	Equal(t, c.T("bye"), "Goodbye")
+
+	l.SetDefaultLocale("fr")
+	c.SetLocale("")
+	Equal(t, c.Locale(), "fr")
+	Equal(t, c.T("bye"), "Au revoir")
+	Equal(t, c.T("only"), "only")
+}
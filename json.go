@@ -0,0 +1,102 @@
+package lars
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// JSONCodec marshals responses and decodes request bodies as JSON, it's used by the
+// JSON response helpers, Bind and Decode so encoding/json can be swapped for a faster
+// implementation such as jsoniter, go-json or sonic; it must be safe for concurrent use.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	MarshalIndent(v interface{}, prefix, indent string) ([]byte, error)
+	Decode(r io.Reader, v interface{}) error
+}
+
+// JSONEncoder may be implemented by a JSONCodec to encode directly to a writer, it's
+// used by JSONStream which otherwise marshals the value first.
+type JSONEncoder interface {
+	Encode(w io.Writer, v interface{}) error
+}
+
+// StdJSONCodec is the default JSONCodec, using encoding/json
+type StdJSONCodec struct {
+	// DisableHTMLEscape stops <, > and & in strings being escaped, which is only
+	// needed when the JSON is embedded in HTML
+	DisableHTMLEscape bool
+}
+
+var (
+	_ JSONCodec   = StdJSONCodec{}
+	_ JSONEncoder = StdJSONCodec{}
+)
+
+// Marshal returns the JSON encoding of v
+func (s StdJSONCodec) Marshal(v interface{}) ([]byte, error) {
+
+	if !s.DisableHTMLEscape {
+		return json.Marshal(v)
+	}
+
+	return s.encode(v, blank, blank)
+}
+
+// MarshalIndent returns the JSON encoding of v, indented
+func (s StdJSONCodec) MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+
+	if !s.DisableHTMLEscape {
+		return json.MarshalIndent(v, prefix, indent)
+	}
+
+	return s.encode(v, prefix, indent)
+}
+
+// Decode decodes the JSON value read from r into v
+func (s StdJSONCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// Encode writes the JSON encoding of v, followed by a newline, to w
+func (s StdJSONCodec) Encode(w io.Writer, v interface{}) error {
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!s.DisableHTMLEscape)
+
+	return enc.Encode(v)
+}
+
+// encode marshals v without escaping HTML, which is only possible using an Encoder
+func (s StdJSONCodec) encode(v interface{}, prefix, indent string) ([]byte, error) {
+
+	var buff bytes.Buffer
+
+	enc := json.NewEncoder(&buff)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, indent)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	// the Encoder terminates each value with a newline, Marshal doesn't
+	return bytes.TrimSuffix(buff.Bytes(), []byte{'\n'}), nil
+}
+
+// SetJSONCodec sets the JSONCodec used by the JSON response helpers, Bind and Decode.
+// default StdJSONCodec{}
+func (l *LARS) SetJSONCodec(codec JSONCodec) {
+	l.jsonCodec = codec
+}
+
+// jsonCodec returns the JSONCodec to use, the default when the Context
+// isn't attached to a LARS instance
+func (c *Ctx) jsonCodec() JSONCodec {
+
+	if c.lars == nil || c.lars.jsonCodec == nil {
+		return StdJSONCodec{}
+	}
+
+	return c.lars.jsonCodec
+}
@@ -0,0 +1,141 @@
+package lars
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+// countingCodec counts it's calls, delegating to the StdJSONCodec
+type countingCodec struct {
+	StdJSONCodec
+	marshals int
+	decodes  int
+}
+
+func (cc *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	cc.marshals++
+	return cc.StdJSONCodec.Marshal(v)
+}
+
+func (cc *countingCodec) MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	cc.marshals++
+	return cc.StdJSONCodec.MarshalIndent(v, prefix, indent)
+}
+
+func (cc *countingCodec) Decode(r io.Reader, v interface{}) error {
+	cc.decodes++
+	return cc.StdJSONCodec.Decode(r, v)
+}
+
+func TestStdJSONCodec(t *testing.T) {
+
+	v := map[string]string{"html": "<b>&</b>"}
+
+	b, err := StdJSONCodec{}.Marshal(v)
+	Equal(t, err, nil)
+	Equal(t, string(b), `{"html":"\u003cb\u003e\u0026\u003c/b\u003e"}`)
+
+	codec := StdJSONCodec{DisableHTMLEscape: true}
+
+	b, err = codec.Marshal(v)
+	Equal(t, err, nil)
+	Equal(t, string(b), `{"html":"<b>&</b>"}`)
+
+	b, err = codec.MarshalIndent(v, blank, "  ")
+	Equal(t, err, nil)
+	Equal(t, string(b), "{\n  \"html\": \"<b>&</b>\"\n}")
+
+	b, err = StdJSONCodec{}.MarshalIndent(v, blank, "  ")
+	Equal(t, err, nil)
+	Equal(t, string(b), "{\n  \"html\": \"\\u003cb\\u003e\\u0026\\u003c/b\\u003e\"\n}")
+
+	_, err = codec.Marshal(make(chan int))
+	NotEqual(t, err, nil)
+
+	var decoded map[string]string
+	Equal(t, codec.Decode(strings.NewReader(`{"a":"b"}`), &decoded), nil)
+	Equal(t, decoded["a"], "b")
+}
+
+func TestJSONCodec(t *testing.T) {
+
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	codec := &countingCodec{StdJSONCodec: StdJSONCodec{DisableHTMLEscape: true}}
+
+	l := New()
+	l.SetJSONCodec(codec)
+	l.Get("/json", func(c Context) error {
+		return c.JSON(http.StatusOK, user{Name: "<lars>"})
+	})
+	l.Get("/pretty", func(c Context) error {
+		return c.JSONPretty(http.StatusOK, user{Name: "lars"})
+	})
+	l.Get("/jsonp", func(c Context) error {
+		return c.JSONP(http.StatusOK, user{Name: "lars"}, "cb")
+	})
+	l.Post("/bind", func(c Context) error {
+
+		var u user
+
+		if err := c.Bind(&u); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, u.Name)
+	})
+	l.Post("/decode", func(c Context) error {
+
+		var u user
+
+		if err := c.Decode(false, 1024, &u); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, u.Name)
+	})
+
+	code, body := request(GET, "/json", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, `{"name":"<lars>"}`)
+	Equal(t, codec.marshals, 1)
+
+	code, body = request(GET, "/pretty", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "{\n  \"name\": \"lars\"\n}")
+	Equal(t, codec.marshals, 2)
+
+	code, body = request(GET, "/jsonp", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, `cb({"name":"lars"});`)
+	Equal(t, codec.marshals, 3)
+
+	for _, path := range []string{"/bind", "/decode"} {
+		r, _ := http.NewRequest(POST, path, strings.NewReader(`{"name":"joey"}`))
+		r.Header.Set(ContentType, ApplicationJSON)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		Equal(t, w.Code, http.StatusOK)
+		Equal(t, w.Body.String(), "joey")
+	}
+
+	Equal(t, codec.decodes, 2)
+}
@@ -1,11 +1,18 @@
 package lars
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
+	"net"
 	"net/http"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-playground/form"
 )
@@ -43,11 +50,19 @@ const (
 	ApplicationXMLCharsetUTF8        = ApplicationXML + "; " + CharsetUTF8
 	ApplicationForm                  = "application/x-www-form-urlencoded"
 	ApplicationProtobuf              = "application/protobuf"
+	ApplicationXProtobuf             = "application/x-protobuf"
 	ApplicationMsgpack               = "application/msgpack"
+	ApplicationYAML                  = "application/yaml"
+	ApplicationXYAML                 = "application/x-yaml"
+	TextYAML                         = "text/yaml"
 	TextHTML                         = "text/html"
 	TextHTMLCharsetUTF8              = TextHTML + "; " + CharsetUTF8
 	TextPlain                        = "text/plain"
 	TextPlainCharsetUTF8             = TextPlain + "; " + CharsetUTF8
+	TextXML                          = "text/xml"
+	TextEventStream                  = "text/event-stream"
+	TextCSV                          = "text/csv"
+	TextCSVCharsetUTF8               = TextCSV + "; " + CharsetUTF8
 	MultipartForm                    = "multipart/form-data"
 	OctetStream                      = "application/octet-stream"
 
@@ -56,31 +71,53 @@ const (
 	//---------
 
 	CharsetUTF8 = "charset=utf-8"
+	UTF8        = "utf-8"
 
 	//---------
 	// Headers
 	//---------
 
+	Accept             = "Accept"
 	AcceptedLanguage   = "Accept-Language"
+	AcceptCharset      = "Accept-Charset"
 	AcceptEncoding     = "Accept-Encoding"
 	Authorization      = "Authorization"
+	CacheControl       = "Cache-Control"
 	ContentDisposition = "Content-Disposition"
 	ContentEncoding    = "Content-Encoding"
 	ContentLength      = "Content-Length"
 	ContentType        = "Content-Type"
+	ETag               = "ETag"
+	IfModifiedSince    = "If-Modified-Since"
+	IfNoneMatch        = "If-None-Match"
+	LastEventID        = "Last-Event-ID"
+	LastModified       = "Last-Modified"
 	Location           = "Location"
 	Upgrade            = "Upgrade"
 	Vary               = "Vary"
 	WWWAuthenticate    = "WWW-Authenticate"
 	XForwardedFor      = "X-Forwarded-For"
 	XRealIP            = "X-Real-Ip"
+	XRequestID         = "X-Request-ID"
 	Allow              = "Allow"
 	Origin             = "Origin"
 
-	Gzip = "gzip"
+	Gzip   = "gzip"
+	Brotli = "br"
+	Zstd   = "zstd"
 
 	WildcardParam = "*wildcard"
 
+	// SubdomainParam is the param holding the subdomain matched by a wildcard Host
+	SubdomainParam = "*subdomain"
+
+	defaultMultipartMemory   = 32 << 20 // 32 MB, same as the http package default
+	defaultMaxFormFileBytes  = 10 << 20 // 10 MB
+	defaultMaxBodyLineLength = 64 << 10 // 64 KB
+	defaultIndent            = "  "
+	defaultStreamThreshold   = 32 << 10 // 32 KB
+	defaultShutdownTimeout   = 30 * time.Second
+
 	basePath = "/"
 	blank    = ""
 
@@ -116,6 +153,11 @@ type LARS struct {
 	routeGroup
 	trees map[string]*node
 
+	// hosts are the routes registered using Host keyed by host pattern, with
+	// wildcardHosts holding the wildcard patterns longest first
+	hosts         map[string]*vhost
+	wildcardHosts []*vhost
+
 	// function that gets called to create the context object... is total overridable using RegisterContext
 	contextFunc ContextFunc
 
@@ -123,17 +165,138 @@ type LARS struct {
 
 	http404 HandlersChain // 404 Not Found
 	http405 HandlersChain // 405 Method Not Allowed
+	http406 HandlersChain // 406 Not Acceptable
 
 	automaticOPTIONS HandlersChain
 	notFound         HandlersChain
 
+	// errorGroups are the groups with their own 404 or 405 handlers
+	errorGroups []*routeGroup
+
 	customHandlersFuncs customHandlers
 
+	// paramTypes are the named param types registered using RegisterParamType
+	paramTypes map[string]ParamTypeFunc
+
+	// binders are the BinderFunc's used by Bind, keyed by media type
+	binders map[string]BinderFunc
+
+	// renderer is the Renderer used by Render
+	renderer Renderer
+
+	// sessionStore keeps the sessions accessed using Session, identified by
+	// the cookie configured by sessionOptions
+	sessionStore   SessionStore
+	sessionOptions SessionOptions
+
+	// catalogs are the translation catalogs used by T keyed by lowercase locale,
+	// locales in the order they were registered
+	catalogs      map[string]Catalog
+	locales       []string
+	defaultLocale string
+
+	// cookieDefaults are the attributes applied to cookies set using SetCookie
+	cookieDefaults CookieDefaults
+
+	// errorHandler is called when a handler or middleware returns an error
+	errorHandler ErrorHandlerFunc
+
+	// logSink receives the log entries buffered on the Context using Log
+	logSink LogSink
+
+	// maxRequestBodySize is the maximum size of an incoming request body
+	maxRequestBodySize int64
+
+	// maxFormFileBytes is the maximum size of a file read into memory
+	// using FormFileBytes
+	maxFormFileBytes int64
+
+	// inFlight keeps track of the requests currently being handled
+	// so they can be drained and cancelled during Shutdown
+	inFlight inFlightRequests
+
+	// server is the *http.Server started using RunServer
+	server   *http.Server
+	serverMu sync.Mutex
+
+	// drainTimeout is the maximum time in-flight requests are given to
+	// finish during Shutdown before their contexts are cancelled
+	drainTimeout time.Duration
+
+	// shutdownTimeout is the deadline of the Shutdown triggered by a signal
+	// when running the server using Run or RunTLS
+	shutdownTimeout time.Duration
+
+	// shutdownDelay is how long Shutdown fails readiness checks, while still serving
+	// requests, before the server stops accepting them
+	shutdownDelay time.Duration
+
+	// maxBodyLineLength is the maximum length of a single line read using BodyLines
+	maxBodyLineLength int
+
+	// notReady is set, atomically, while the app has signaled it is not ready to
+	// serve traffic; all requests, except readyAllowlist paths, are answered with 503
+	notReady       int32
+	readyAllowlist map[string]struct{}
+
+	// health are the liveness and readiness checks served using ServeHealth
+	health healthChecks
+
+	// debug enables development diagnostics such as goroutine leak warnings
+	debug bool
+
+	// matchHook is notified of the outcome of every route lookup, nil when not set
+	matchHook MatchHook
+
+	// responseHeaders are the default headers applied to every response
+	// just before it is committed
+	responseHeaders map[string]string
+
+	// redirectPermanentCode and redirectTemporaryCode are the status codes used
+	// when redirecting GET and all other requests to fix a trailing slash
+	redirectPermanentCode int
+	redirectTemporaryCode int
+
+	// redirectAllowlist are the hosts c.Redirect may redirect to, nil for any host
+	redirectAllowlist map[string]struct{}
+
+	// namedRoutes are the routes named using Name, used to build their URLs
+	namedRoutes map[string]*Route
+
+	// trustedProxies are the networks whose forwarding headers ClientIP honors
+	trustedProxies []*net.IPNet
+
+	// bufferedResponse buffers responses until the request completes
+	bufferedResponse bool
+
+	// streamThreshold is the maximum size of a response body written by the response
+	// helpers with a Content-Length, larger bodies are streamed
+	streamThreshold int
+
+	// jsonIndent makes JSON pretty-print it's output, mainly used during development
+	jsonIndent bool
+
+	// jsonCodec marshals and decodes JSON
+	jsonCodec JSONCodec
+
+	// msgpackCodec marshals and decodes MessagePack, nil when unsupported
+	msgpackCodec MsgpackCodec
+
+	// protobufCodec marshals and unmarshals protocol buffers, nil when unsupported
+	protobufCodec ProtobufCodec
+
+	// yamlCodec marshals and decodes YAML, nil when unsupported
+	yamlCodec YAMLCodec
+
 	// mostParams used to keep track of the most amount of
 	// params in any URL and this will set the default capacity
 	// of eachContext Params
 	mostParams uint8
 
+	// routesMu guards the route trees so routes can be registered and removed
+	// while serving
+	routesMu sync.RWMutex
+
 	// Enables automatic redirection if the current route can't be matched but a
 	// handler for the path with (without) the trailing slash exists.
 	// For example if /foo/ is requested but a route only exists for /foo, the
@@ -152,6 +315,12 @@ type LARS struct {
 	// if enabled automatically handles OPTION requests; manually configured OPTION
 	// handlers take presidence. default true
 	automaticallyHandleOPTIONS bool
+
+	// automaticOPTIONSMaxAge is the Cache-Control max-age of automatic OPTIONS responses
+	automaticOPTIONSMaxAge time.Duration
+
+	// staticImmutable reports whether a static file is fingerprinted, see SetStaticImmutable
+	staticImmutable func(name string) bool
 }
 
 // RouteMap contains a single routes full path
@@ -172,8 +341,17 @@ var (
 		c.Response().WriteHeader(http.StatusMethodNotAllowed)
 	}
 
-	automaticOPTIONSHandler = func(c Context) {
-		c.Response().WriteHeader(http.StatusOK)
+	default406Handler = func(c Context) {
+		http.Error(c.Response(), http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+	}
+
+	jsonNull  = []byte("null")
+	xmlHeader = []byte(xml.Header)
+
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
 	}
 
 	formDecoder     *form.Decoder
@@ -194,6 +372,17 @@ func New() *LARS {
 		mostParams:                 0,
 		http404:                    []HandlerFunc{default404Handler},
 		http405:                    []HandlerFunc{methodNotAllowedHandler},
+		http406:                    []HandlerFunc{default406Handler},
+		errorHandler:               defaultErrorHandler,
+		binders:                    defaultBinders(),
+		jsonCodec:                  StdJSONCodec{},
+		logSink:                    defaultLogSink,
+		streamThreshold:            defaultStreamThreshold,
+		shutdownTimeout:            defaultShutdownTimeout,
+		redirectPermanentCode:      http.StatusMovedPermanently,
+		redirectTemporaryCode:      http.StatusTemporaryRedirect,
+		maxFormFileBytes:           defaultMaxFormFileBytes,
+		maxBodyLineLength:          defaultMaxBodyLineLength,
 		redirectTrailingSlash:      true,
 		handleMethodNotAllowed:     false,
 		automaticallyHandleOPTIONS: false,
@@ -262,13 +451,212 @@ func (l *LARS) Register404(notFound ...Handler) {
 	l.http404 = chain
 }
 
+// Register405 alows for overriding of the method not allowed handler function.
+// NOTE: the Allow header of the response is populated with the methods allowed
+// for the requested path before the handlers are run, access them using
+// c.Response().Header()[lars.Allow]
+func (l *LARS) Register405(notAllowed ...Handler) {
+
+	chain := make(HandlersChain, len(notAllowed))
+
+	for i, h := range notAllowed {
+		chain[i] = l.wrapHandler(h)
+	}
+
+	l.http405 = chain
+}
+
+// Register406 alows for overriding of the not acceptable handler function.
+// NOTE: the handlers are run by c.NotAcceptable() which is called when content
+// negotiation can't satisfy the client's Accept header
+func (l *LARS) Register406(notAcceptable ...Handler) {
+
+	chain := make(HandlersChain, len(notAcceptable))
+
+	for i, h := range notAcceptable {
+		chain[i] = l.wrapHandler(h)
+	}
+
+	l.http406 = chain
+}
+
+// SetErrorHandler registers the function called when a handler or middleware
+// of type func(Context) error returns an error, the default handler renders
+// an HTTPError's Code + Message as JSON and responds 500 for any other error.
+func (l *LARS) SetErrorHandler(fn ErrorHandlerFunc) {
+	l.errorHandler = fn
+}
+
+// SetMaxRequestBodySize sets the maximum size, in bytes, of an incoming request
+// body; reading beyond the limit fails and Decode, ParseForm and ParseMultipartForm
+// return ErrRequestEntityTooLarge. This is the total body cap and is independent
+// of ParseMultipartForm's maxMemory which only limits what is kept in memory;
+// routes can override it using Route.BodyLimit. default 0 (unlimited)
+func (l *LARS) SetMaxRequestBodySize(n int64) {
+	l.maxRequestBodySize = n
+}
+
+// SetMaxFormFileBytes sets the maximum size, in bytes, of an uploaded file
+// read into memory using FormFileBytes. default 10 MB
+func (l *LARS) SetMaxFormFileBytes(n int64) {
+	l.maxFormFileBytes = n
+}
+
+// SetDrainTimeout sets the maximum amount of time in-flight requests are given
+// to finish during Shutdown before their contexts are cancelled; handlers observe
+// the cancellation through the Context's Done() channel. Must be set before
+// serving begins as each request's context is only made cancellable when enabled.
+// default 0 (disabled), in-flight requests are waited on until Shutdown's context is done
+func (l *LARS) SetDrainTimeout(d time.Duration) {
+	l.drainTimeout = d
+}
+
+// SetShutdownTimeout sets the deadline of the graceful shutdown triggered by SIGINT
+// or SIGTERM when running the server using Run or RunTLS; Run returns once it elapses
+// even if requests are still in-flight, which are only cancelled when a drain timeout
+// is set. Use SetDrainTimeout, shorter than this, to cancel them and leave the handlers
+// time to clean up. default 30 seconds
+func (l *LARS) SetShutdownTimeout(d time.Duration) {
+	l.shutdownTimeout = d
+}
+
+// SetShutdownDelay sets how long Shutdown, including the one triggered by a signal when
+// running the server using Run or RunTLS, fails the readiness checks served using
+// ServeHealth, while still serving requests as normal, before the server stops
+// accepting them; giving load balancers time to notice and stop routing to it. It
+// should be longer than the load balancer's readiness check interval and is part of
+// the shutdown timeout. default 0
+func (l *LARS) SetShutdownDelay(d time.Duration) {
+	l.shutdownDelay = d
+}
+
+// SetMaxBodyLineLength sets the maximum length, in bytes, of a single line
+// read from the request body using BodyLines. default 64 KB
+func (l *LARS) SetMaxBodyLineLength(n int) {
+	l.maxBodyLineLength = n
+}
+
+// SetReady signals whether the app is ready to serve traffic, while not ready all
+// requests except those whose path is in the allowlist, set using SetReadyAllowlist,
+// are answered with 503 Service Unavailable. Useful to avoid serving traffic before
+// caches and connections are warmed up; safe to call while serving. default true
+func (l *LARS) SetReady(ready bool) {
+
+	if ready {
+		atomic.StoreInt32(&l.notReady, 0)
+		return
+	}
+
+	atomic.StoreInt32(&l.notReady, 1)
+}
+
+// SetReadyAllowlist sets the exact request paths, such as health checks, that are
+// still routed while the app is not ready. Must be set before serving begins.
+func (l *LARS) SetReadyAllowlist(paths ...string) {
+
+	l.readyAllowlist = make(map[string]struct{}, len(paths))
+
+	for _, p := range paths {
+		l.readyAllowlist[p] = struct{}{}
+	}
+}
+
+// SetResponseHeaders sets default headers that are written on every response
+// just before it is committed. Headers already set by a handler take precedence
+// over the defaults and a blank value removes the header from the response
+// i.e. map[string]string{"Server": "lars", "X-Powered-By": ""}
+func (l *LARS) SetResponseHeaders(headers map[string]string) {
+	l.responseHeaders = headers
+}
+
+// SetTrustedProxies sets the proxies, as CIDRs or single IPs, that ClientIP trusts
+// i.e. "10.0.0.0/8", "127.0.0.1". Once set the X-Real-IP and X-Forwarded-For headers
+// are only honored when the request's RemoteAddr is a trusted proxy, X-Forwarded-For
+// is walked right to left skipping trusted proxies to find the client.
+// NOTE: panics if a proxy is not a valid CIDR or IP
+func (l *LARS) SetTrustedProxies(proxies ...string) {
+	l.trustedProxies = ParseNetworks("invalid trusted proxy", proxies...)
+}
+
+// isTrustedProxy returns whether the provided ip is within one of the trusted proxy networks
+func (l *LARS) isTrustedProxy(ip string) bool {
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range l.trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetBufferedResponse tells lars whether to buffer each response, status and body,
+// in memory until the request completes or it's flushed, allowing the response to
+// be rolled back; when a handler returns an error the buffered response is discarded
+// before the error handler is run. Streaming, using Flush or Stream, commits the
+// buffered response and everything afterwards is sent directly.
+// NOTE: middleware replacing the response's writer, such as Gzip, must restore the
+// original writer once the chain returns, doing so commits the buffered response
+// through their writer. default false
+func (l *LARS) SetBufferedResponse(set bool) {
+	l.bufferedResponse = set
+}
+
+// SetStreamThreshold sets the maximum size of a response body, written using the
+// response helpers such as JSON or Attachment, that is buffered and written with a
+// Content-Length in a single write; larger bodies, or those of unknown size exceeding
+// it, are streamed using chunked encoding. A threshold <= 0 always streams. default 32KB
+func (l *LARS) SetStreamThreshold(n int) {
+	l.streamThreshold = n
+}
+
+// SetJSONIndent tells lars whether the JSON response helper should
+// pretty-print it's output, useful during development. default false
+func (l *LARS) SetJSONIndent(set bool) {
+	l.jsonIndent = set
+}
+
 // SetAutomaticallyHandleOPTIONS tells lars whether to
-// automatically handle OPTION requests; manually configured
-// OPTION handlers take precedence. default true
+// automatically handle OPTION requests for paths with registered
+// handlers, responding 204 No Content with the Allow header listing
+// their methods; manually configured OPTION handlers take precedence.
+// default false
 func (l *LARS) SetAutomaticallyHandleOPTIONS(set bool) {
 	l.automaticallyHandleOPTIONS = set
 }
 
+// SetAutomaticOPTIONSMaxAge sets how long clients and caches may reuse the
+// automatic OPTIONS responses, sent as Cache-Control max-age; routes are
+// expected not to change while serving. default 0 (not cached)
+func (l *LARS) SetAutomaticOPTIONSMaxAge(d time.Duration) {
+	l.automaticOPTIONSMaxAge = d
+}
+
+// automaticOPTIONSHandler returns the handler responding to automatic OPTIONS
+// requests once the Allow header has been populated.
+func (l *LARS) automaticOPTIONSHandler() HandlerFunc {
+
+	var cacheControl string
+
+	if l.automaticOPTIONSMaxAge > 0 {
+		cacheControl = "max-age=" + strconv.FormatInt(int64(l.automaticOPTIONSMaxAge/time.Second), 10)
+	}
+
+	return func(c Context) {
+
+		if cacheControl != blank {
+			c.Response().Header().Set(CacheControl, cacheControl)
+		}
+
+		c.Response().WriteHeader(http.StatusNoContent)
+	}
+}
+
 // SetRedirectTrailingSlash tells lars whether to try
 // and fix a URL by trying to find it
 // lowercase -> with or without slash -> 404
@@ -276,6 +664,25 @@ func (l *LARS) SetRedirectTrailingSlash(set bool) {
 	l.redirectTrailingSlash = set
 }
 
+// SetRedirectCode sets the status codes used when redirecting to fix a trailing
+// slash; permanent is used for GET requests and temporary for all other methods,
+// which should be a method preserving code. default 301 and 307
+// NOTE: panics if either code is not one of 301, 302, 303, 307 or 308
+func (l *LARS) SetRedirectCode(permanent, temporary int) {
+
+	for _, code := range []int{permanent, temporary} {
+
+		switch code {
+		case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		default:
+			panic(fmt.Sprintf("invalid redirect code %d", code))
+		}
+	}
+
+	l.redirectPermanentCode = permanent
+	l.redirectTemporaryCode = temporary
+}
+
 // SetHandle405MethodNotAllowed tells lars whether to
 // handle the http 405 Method Not Allowed status code
 func (l *LARS) SetHandle405MethodNotAllowed(set bool) {
@@ -293,10 +700,22 @@ func (l *LARS) Serve() http.Handler {
 	copy(l.notFound, l.middleware)
 	copy(l.notFound[len(l.middleware):], l.http404)
 
+	l.routesMu.Lock()
+
+	for _, g := range l.errorGroups {
+		g.buildErrorChains()
+	}
+
+	l.routesMu.Unlock()
+
 	if l.automaticallyHandleOPTIONS {
 		l.automaticOPTIONS = make(HandlersChain, len(l.middleware)+1)
 		copy(l.automaticOPTIONS, l.middleware)
-		copy(l.automaticOPTIONS[len(l.middleware):], []HandlerFunc{automaticOPTIONSHandler})
+		copy(l.automaticOPTIONS[len(l.middleware):], []HandlerFunc{l.automaticOPTIONSHandler()})
+	}
+
+	if l.debug {
+		l.logRoutes()
 	}
 
 	return http.HandlerFunc(l.serveHTTP)
@@ -305,15 +724,84 @@ func (l *LARS) Serve() http.Handler {
 // Conforms to the http.Handler interface.
 func (l *LARS) serveHTTP(w http.ResponseWriter, r *http.Request) {
 
+	if atomic.LoadInt32(&l.inFlight.shuttingDown) == 1 && !l.health.isHealthPath(r.URL.Path) {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if atomic.LoadInt32(&l.notReady) == 1 && !l.health.isHealthPath(r.URL.Path) {
+		if _, ok := l.readyAllowlist[r.URL.Path]; !ok {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
 	c := l.pool.Get().(*Ctx)
 
 	c.parent.RequestStart(w, r)
 
-	if root := l.trees[r.Method]; root != nil {
+	cancellable := l.drainTimeout > 0
 
-		if c.handlers, c.params, c.handlerName = root.find(r.URL.Path, c.params); c.handlers == nil {
+	if cancellable {
+		l.inFlight.add(c, c.parent.WithCancel())
+	} else {
+		l.inFlight.add(c, nil)
+	}
 
-			c.params = c.params[0:0]
+	// deferred so a panicking handler doesn't leave the request in-flight, which would
+	// hold up Shutdown until it's deadline; the Context is only returned to the pool
+	// once removed as the cancels are keyed by it.
+	defer func() {
+		l.inFlight.remove(c, cancellable)
+		l.pool.Put(c)
+	}()
+
+	// checked again now the request is in-flight, Shutdown may have begun since the
+	// check above and not have seen it when waiting on the in-flight requests
+	if atomic.LoadInt32(&l.inFlight.shuttingDown) == 1 && !l.health.isHealthPath(r.URL.Path) {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		c.parent.RequestEnd()
+		return
+	}
+
+	// routes may be registered and removed while serving, the lock is held until the
+	// request has been routed
+	l.routesMu.RLock()
+
+	// a route with more params may have been registered since the Context was created
+	if cap(c.params) < int(l.mostParams) {
+		c.params = make(Params, 0, l.mostParams)
+	}
+
+	if l.bufferedResponse {
+		c.response.startBuffering()
+	}
+
+	var start time.Time
+	result := MatchNotFound
+
+	if l.matchHook != nil {
+		start = time.Now()
+	}
+
+	h, subdomain, wildcard := l.matchHost(r.Host)
+	trees := l.trees
+	hostParams := 0
+
+	if h != nil {
+		trees = h.trees
+	}
+
+	if wildcard {
+		c.params = append(c.params, Param{Key: SubdomainParam, Value: subdomain})
+		hostParams = 1
+	}
+
+	if root := trees[r.Method]; root != nil {
+
+		if c.handlers, c.params, c.route = root.find(r.URL.Path, c.params); c.handlers == nil {
+
+			c.params = c.params[:hostParams]
 
 			if l.redirectTrailingSlash && len(r.URL.Path) > 1 {
 
@@ -327,6 +815,7 @@ func (l *LARS) serveHTTP(w http.ResponseWriter, r *http.Request) {
 						r.URL.Path = lc
 						c.handlers = l.redirect(r.Method, r.URL.String())
 						r.URL.Path = orig
+						result = MatchRedirect
 						goto END
 					}
 				}
@@ -341,74 +830,136 @@ func (l *LARS) serveHTTP(w http.ResponseWriter, r *http.Request) {
 					r.URL.Path = lc
 					c.handlers = l.redirect(r.Method, r.URL.String())
 					r.URL.Path = orig
+					result = MatchRedirect
 					goto END
 				}
 			}
 
 		} else {
+			result = MatchFound
 			goto END
 		}
 	}
 
 	if l.automaticallyHandleOPTIONS && r.Method == OPTIONS {
-		l.getOptions(c)
-		goto END
+
+		if l.getOptions(c, trees) {
+			result = MatchOptions
+			goto END
+		}
 	}
 
 	if l.handleMethodNotAllowed {
 
-		if l.checkMethodNotAllowed(c) {
+		if l.checkMethodNotAllowed(c, h, trees) {
+			result = MatchMethodNotAllowed
 			goto END
 		}
 	}
 
 	// not found
-	c.handlers = l.notFound
+	if g := l.errorGroup(h, r.URL.Path, false); g != nil {
+		c.handlers = g.notFound
+	} else {
+		c.handlers = l.notFound
+	}
 
 END:
 
-	c.parent.Next()
-	c.parent.RequestEnd()
+	// read while locked as the route's setters may still be running
+	limit := l.maxRequestBodySize
+
+	if c.route != nil && c.route.maxBodySize > 0 {
+		limit = c.route.maxBodySize
+	}
+
+	l.routesMu.RUnlock()
 
-	l.pool.Put(c)
+	if l.matchHook != nil {
+		l.notifyMatch(c, start, result)
+	}
+
+	if r.Body != nil && limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+
+	if l.debug {
+		l.nextDebug(c)
+	} else {
+		c.parent.Next()
+	}
+
+	if c.sse != nil {
+		c.sse.close()
+	}
+
+	if l.bufferedResponse {
+		c.response.endBuffering()
+	}
+
+	c.response.runAfter()
+
+	// the response may not have been committed yet if nothing was written
+	c.saveSession()
+
+	l.flushLogs(c)
+
+	c.parent.RequestEnd()
 }
 
-func (l *LARS) getOptions(c *Ctx) {
+// getOptions populates the Allow header with the methods registered for the
+// requested path and returns whether any were found, in which case the
+// automatic OPTIONS handlers are set to be run.
+func (l *LARS) getOptions(c *Ctx, trees map[string]*node) (found bool) {
+
+	methods := make([]string, 0, len(trees))
 
 	if c.request.URL.Path == "*" { // check server-wide OPTIONS
 
-		for m := range l.trees {
+		for m := range trees {
 
 			if m == OPTIONS {
 				continue
 			}
 
-			c.response.Header().Add(Allow, m)
+			methods = append(methods, m)
 		}
 
 	} else {
-		for m, tree := range l.trees {
+		for m, tree := range trees {
 
 			if m == c.request.Method || m == OPTIONS {
 				continue
 			}
 
 			if c.handlers, _, _ = tree.find(c.request.URL.Path, c.params); c.handlers != nil {
-				c.response.Header().Add(Allow, m)
+				methods = append(methods, m)
 			}
 		}
 
 	}
 
+	if len(methods) == 0 {
+		c.handlers = nil
+		return
+	}
+
+	// sorted so the responses of a path are identical and can be cached
+	sort.Strings(methods)
+
+	for _, m := range methods {
+		c.response.Header().Add(Allow, m)
+	}
+
 	c.response.Header().Add(Allow, OPTIONS)
 	c.handlers = l.automaticOPTIONS
 
-	return
+	return true
 }
 
-func (l *LARS) checkMethodNotAllowed(c *Ctx) (found bool) {
+func (l *LARS) checkMethodNotAllowed(c *Ctx, h *vhost, trees map[string]*node) (found bool) {
 
-	for m, tree := range l.trees {
+	for m, tree := range trees {
 
 		if m != c.request.Method {
 			if c.handlers, _, _ = tree.find(c.request.URL.Path, c.params); c.handlers != nil {
@@ -420,8 +971,75 @@ func (l *LARS) checkMethodNotAllowed(c *Ctx) (found bool) {
 	}
 
 	if found {
-		c.handlers = l.http405
+		if g := l.errorGroup(h, c.request.URL.Path, true); g != nil {
+			c.handlers = g.notAllowed
+		} else {
+			c.handlers = l.http405
+		}
 	}
 
 	return
 }
+
+// inFlightRequests tracks the requests currently being handled
+type inFlightRequests struct {
+	count        int64
+	shuttingDown int32
+	// draining is set once Shutdown begins, before shuttingDown, failing readiness
+	// checks while requests are still served for the shutdown delay
+	draining int32
+	mu       sync.Mutex
+	cancels  map[*Ctx]func()
+}
+
+func (f *inFlightRequests) add(c *Ctx, cancel func()) {
+
+	atomic.AddInt64(&f.count, 1)
+
+	if cancel == nil {
+		return
+	}
+
+	f.mu.Lock()
+
+	if f.cancels == nil {
+		f.cancels = make(map[*Ctx]func())
+	}
+
+	f.cancels[c] = cancel
+	f.mu.Unlock()
+}
+
+func (f *inFlightRequests) remove(c *Ctx, cancellable bool) {
+
+	if cancellable {
+
+		f.mu.Lock()
+
+		if cancel, ok := f.cancels[c]; ok {
+			delete(f.cancels, c)
+			cancel()
+		}
+
+		f.mu.Unlock()
+	}
+
+	atomic.AddInt64(&f.count, -1)
+}
+
+func (f *inFlightRequests) len() int64 {
+	return atomic.LoadInt64(&f.count)
+}
+
+// cancelAll cancels the contexts of all in-flight requests
+func (f *inFlightRequests) cancelAll() {
+
+	f.mu.Lock()
+
+	for c, cancel := range f.cancels {
+		delete(f.cancels, c)
+		cancel()
+	}
+
+	f.mu.Unlock()
+}
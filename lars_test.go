@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "gopkg.in/go-playground/assert.v1"
 )
@@ -495,6 +496,42 @@ func TestAddAllMethods(t *testing.T) {
 	Equal(t, body, propfind)
 }
 
+func TestHandleCustomMethods(t *testing.T) {
+	fn := func(c Context) {
+		if _, err := c.Response().Write([]byte(c.Request().Method + " " + c.Param("id"))); err != nil {
+			panic(err)
+		}
+	}
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+
+	dav := l.Group("/dav")
+	dav.Handle("PROPFIND", "/:id", fn)
+	dav.Handle("REPORT", "/:id", fn)
+	l.Handle("X-CUSTOM_1.0", "/custom", fn)
+
+	code, body := request("PROPFIND", "/dav/1", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "PROPFIND 1")
+
+	code, body = request("REPORT", "/dav/2", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "REPORT 2")
+
+	code, body = request("X-CUSTOM_1.0", "/custom", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "X-CUSTOM_1.0 ")
+
+	code, _ = request(GET, "/dav/1", l)
+	Equal(t, code, http.StatusMethodNotAllowed)
+
+	PanicMatches(t, func() { l.Handle("", "/bad", fn) }, "Invalid method '' for path:/bad")
+	PanicMatches(t, func() { l.Handle("BAD METHOD", "/bad", fn) }, "Invalid method 'BAD METHOD' for path:/bad")
+	PanicMatches(t, func() { l.Handle("GET(1)", "/bad", fn) }, "Invalid method 'GET(1)' for path:/bad")
+	PanicMatches(t, func() { l.Match([]string{GET, "BAD/METHOD"}, "/match", fn) }, "Invalid method 'BAD/METHOD' for path:/match")
+}
+
 func TestAddAllMethodsMatch(t *testing.T) {
 	fn := func(c Context) {
 		if _, err := c.Response().Write([]byte(c.Request().Method)); err != nil {
@@ -894,6 +931,85 @@ func TestMethodNotAllowed(t *testing.T) {
 	Equal(t, code, http.StatusNotFound)
 }
 
+func TestCustom405(t *testing.T) {
+
+	fn := func(c Context) {
+		c.JSON(http.StatusMethodNotAllowed, c.Response().Header()[Allow])
+	}
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.Register405(fn)
+	l.Get("/home", basicHandler)
+
+	code, body := request(POST, "/home", l)
+	Equal(t, code, http.StatusMethodNotAllowed)
+	Equal(t, body, `["GET"]`)
+}
+
+func TestRedirectCode(t *testing.T) {
+
+	PanicMatches(t, func() { New().SetRedirectCode(http.StatusOK, http.StatusTemporaryRedirect) }, "invalid redirect code 200")
+	PanicMatches(t, func() { New().SetRedirectCode(http.StatusPermanentRedirect, http.StatusNotModified) }, "invalid redirect code 304")
+
+	l := New()
+	l.SetRedirectCode(http.StatusPermanentRedirect, http.StatusPermanentRedirect)
+	l.Get("/home/", basicHandler)
+	l.Post("/home/", basicHandler)
+
+	code, _ := request(GET, "/home", l)
+	Equal(t, code, http.StatusPermanentRedirect)
+
+	code, _ = request(POST, "/home", l)
+	Equal(t, code, http.StatusPermanentRedirect)
+
+	code, _ = request(GET, "/Home/", l)
+	Equal(t, code, http.StatusPermanentRedirect)
+
+	code, _ = request(GET, "/home/", l)
+	Equal(t, code, http.StatusOK)
+}
+
+func TestCustom406(t *testing.T) {
+
+	l := New()
+	l.Get("/default", func(c Context) {
+		c.NotAcceptable()
+	})
+
+	code, body := request(GET, "/default", l)
+	Equal(t, code, http.StatusNotAcceptable)
+	Equal(t, body, "Not Acceptable\n")
+
+	l = New()
+	l.Register406(func(c Context) {
+		c.Set("406", true)
+		c.Next()
+	}, func(c Context) {
+		c.JSON(http.StatusNotAcceptable, []string{ApplicationJSON, ApplicationXML})
+	})
+	l.Use(func(c Context) {
+		c.Next()
+
+		// the original chain is restored once the 406 handlers complete
+		_, ok := c.Get("406")
+		Equal(t, ok, true)
+		Equal(t, c.BaseContext().index, 1)
+	})
+	l.Get("/custom", func(c Context) {
+		if c.Request().Header.Get(Accept) != ApplicationJSON {
+			c.NotAcceptable()
+			return
+		}
+
+		c.Text(http.StatusOK, "ok")
+	})
+
+	code, body = request(GET, "/custom", l)
+	Equal(t, code, http.StatusNotAcceptable)
+	Equal(t, body, `["application/json","application/xml"]`)
+}
+
 func TestRedirect(t *testing.T) {
 	l := New()
 
@@ -968,23 +1084,85 @@ func TestAutomaticallyHandleOPTIONS(t *testing.T) {
 	w := httptest.NewRecorder()
 	l.serveHTTP(w, r)
 
-	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Code, http.StatusNoContent)
 
 	allow, ok := w.Header()["Allow"]
 
 	Equal(t, ok, true)
-	Equal(t, len(allow), 3)
+	Equal(t, allow, []string{GET, POST, OPTIONS})
+	Equal(t, w.Header().Get(CacheControl), "")
 
 	r, _ = http.NewRequest(OPTIONS, "*", nil)
 	w = httptest.NewRecorder()
 	l.serveHTTP(w, r)
 
-	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Code, http.StatusNoContent)
 
 	allow, ok = w.Header()["Allow"]
 
 	Equal(t, ok, true)
 	Equal(t, len(allow), 4)
+
+	// manually configured OPTIONS handler takes precedence
+	r, _ = http.NewRequest(OPTIONS, "/other", nil)
+	w = httptest.NewRecorder()
+	l.serveHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, len(w.Header()[Allow]), 0)
+
+	// no handlers registered for the path
+	r, _ = http.NewRequest(OPTIONS, "/missing", nil)
+	w = httptest.NewRecorder()
+	l.serveHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, len(w.Header()[Allow]), 0)
+
+	l.SetHandle405MethodNotAllowed(true)
+
+	r, _ = http.NewRequest(OPTIONS, "/missing", nil)
+	w = httptest.NewRecorder()
+	l.serveHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusNotFound)
+
+	l.SetAutomaticOPTIONSMaxAge(time.Hour)
+
+	r, _ = http.NewRequest(OPTIONS, "/home", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusNoContent)
+	Equal(t, w.Header()[Allow], []string{GET, POST, OPTIONS})
+	Equal(t, w.Header().Get(CacheControl), "max-age=3600")
+}
+
+func TestReady(t *testing.T) {
+
+	l := New()
+	l.Get("/health", basicHandler)
+	l.Get("/home", basicHandler)
+
+	code, _ := request(GET, "/home", l)
+	Equal(t, code, http.StatusOK)
+
+	l.SetReady(false)
+	l.SetReadyAllowlist("/health")
+
+	code, _ = request(GET, "/home", l)
+	Equal(t, code, http.StatusServiceUnavailable)
+
+	code, _ = request(GET, "/notfound", l)
+	Equal(t, code, http.StatusServiceUnavailable)
+
+	code, _ = request(GET, "/health", l)
+	Equal(t, code, http.StatusOK)
+
+	l.SetReady(true)
+
+	code, _ = request(GET, "/home", l)
+	Equal(t, code, http.StatusOK)
 }
 
 type closeNotifyingRecorder struct {
@@ -0,0 +1,223 @@
+// Package larstest provides utilities for testing lars handlers and middleware
+// without starting a server.
+//
+// A handler can be called directly with a Context created by NewContext:
+//
+//	rec := larstest.NewRecorder()
+//	c := larstest.NewContext(rec, httptest.NewRequest(lars.GET, "/users/7", nil), lars.Param{Key: "id", Value: "7"})
+//	getUser(c)
+//
+//	var u User
+//	err := rec.DecodeJSON(&u)
+//
+// or a request built and run, through a router or handlers, by the request builder:
+//
+//	rec := larstest.NewRequest(lars.POST, "/users").
+//		Header(lars.Authorization, "Bearer token").
+//		JSON(User{Name: "Joey"}).
+//		Serve(router.Serve())
+//
+//	rec.Status() // i.e. http.StatusCreated
+package larstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/go-playground/lars"
+)
+
+// NewContext returns a Context for the request r, writing to w, with the URL params
+// set, ready to be passed to a handler; middleware, which call Next, must be run along
+// with the handlers that follow using RunHandlers. A new LARS instance with the
+// default configuration is used, see NewContextWith to use a configured one.
+func NewContext(w http.ResponseWriter, r *http.Request, params ...lars.Param) *lars.Ctx {
+	return NewContextWith(lars.New(), w, r, params...)
+}
+
+// NewContextWith returns a Context, the same as NewContext, using the LARS instance l
+// i.e. for it's JSON codec, binders and error handler.
+func NewContextWith(l *lars.LARS, w http.ResponseWriter, r *http.Request, params ...lars.Param) *lars.Ctx {
+
+	c := lars.NewContext(l)
+	c.RequestStart(w, r)
+	c.SetParams(params)
+
+	return c
+}
+
+// Recorder records the response written by a handler, exposing the captured status
+// and the decoded body.
+type Recorder struct {
+	*httptest.ResponseRecorder
+}
+
+// NewRecorder returns an initialized Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{ResponseRecorder: httptest.NewRecorder()}
+}
+
+// Status returns the captured HTTP status code
+func (r *Recorder) Status() int {
+	return r.Code
+}
+
+// BodyString returns the response body as a string
+func (r *Recorder) BodyString() string {
+	return r.Body.String()
+}
+
+// DecodeJSON decodes the JSON response body into v
+func (r *Recorder) DecodeJSON(v interface{}) error {
+	return json.Unmarshal(r.Body.Bytes(), v)
+}
+
+// DecodeXML decodes the XML response body into v
+func (r *Recorder) DecodeXML(v interface{}) error {
+	return xml.Unmarshal(r.Body.Bytes(), v)
+}
+
+// RequestBuilder builds a request fluently, to then be run through a router with
+// Serve or directly through handlers with Run.
+type RequestBuilder struct {
+	method  string
+	target  string
+	header  http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	params  lars.Params
+	body    io.Reader
+	err     error
+}
+
+// NewRequest returns a RequestBuilder for a request of method to target,
+// a path or absolute URL.
+func NewRequest(method, target string) *RequestBuilder {
+	return &RequestBuilder{
+		method: method,
+		target: target,
+		header: make(http.Header),
+		query:  make(url.Values),
+	}
+}
+
+// Header adds the header key with value
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.header.Add(key, value)
+	return b
+}
+
+// Query adds the query param key with value, to those already in the target
+func (b *RequestBuilder) Query(key, value string) *RequestBuilder {
+	b.query.Add(key, value)
+	return b
+}
+
+// Cookie adds the cookie
+func (b *RequestBuilder) Cookie(cookie *http.Cookie) *RequestBuilder {
+	b.cookies = append(b.cookies, cookie)
+	return b
+}
+
+// Param sets the URL param key to value, used by Run as there's no route to extract
+// it from; Serve extracts the params from the target's path.
+func (b *RequestBuilder) Param(key, value string) *RequestBuilder {
+	b.params = append(b.params, lars.Param{Key: key, Value: value})
+	return b
+}
+
+// Body sets the request body and it's Content-Type
+func (b *RequestBuilder) Body(contentType string, body io.Reader) *RequestBuilder {
+	b.header.Set(lars.ContentType, contentType)
+	b.body = body
+	return b
+}
+
+// JSON sets the request body to v encoded as JSON
+func (b *RequestBuilder) JSON(v interface{}) *RequestBuilder {
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	return b.Body(lars.ApplicationJSONCharsetUTF8, bytes.NewReader(buf))
+}
+
+// XML sets the request body to v encoded as XML
+func (b *RequestBuilder) XML(v interface{}) *RequestBuilder {
+
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	return b.Body(lars.ApplicationXMLCharsetUTF8, bytes.NewReader(buf))
+}
+
+// Form sets the request body to the URL encoded form values
+func (b *RequestBuilder) Form(values url.Values) *RequestBuilder {
+	return b.Body(lars.ApplicationForm, strings.NewReader(values.Encode()))
+}
+
+// Build returns the built request, it panics when the JSON or XML body couldn't be
+// encoded as there's no sensible request to test with.
+func (b *RequestBuilder) Build() *http.Request {
+
+	if b.err != nil {
+		panic("larstest: " + b.err.Error())
+	}
+
+	r := httptest.NewRequest(b.method, b.target, b.body)
+
+	for k, v := range b.header {
+		r.Header[k] = append(r.Header[k], v...)
+	}
+
+	if len(b.query) > 0 {
+
+		q := r.URL.Query()
+
+		for k, v := range b.query {
+			q[k] = append(q[k], v...)
+		}
+
+		r.URL.RawQuery = q.Encode()
+		r.RequestURI = r.URL.RequestURI()
+	}
+
+	for _, c := range b.cookies {
+		r.AddCookie(c)
+	}
+
+	return r
+}
+
+// Serve runs the built request through h, usually the router's Serve(), and
+// returns the recorded response.
+func (b *RequestBuilder) Serve(h http.Handler) *Recorder {
+
+	rec := NewRecorder()
+	h.ServeHTTP(rec, b.Build())
+
+	return rec
+}
+
+// Run runs the built request directly through the handlers, in order as a route's
+// would be, with the URL params set by Param and returns the recorded response.
+func (b *RequestBuilder) Run(handlers ...lars.Handler) *Recorder {
+
+	rec := NewRecorder()
+	c := NewContext(rec, b.Build(), b.params...)
+	c.RunHandlers(handlers...)
+
+	return rec
+}
@@ -0,0 +1,181 @@
+package larstest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+type user struct {
+	XMLName xml.Name `json:"-" xml:"user"`
+	ID      int      `json:"id" xml:"id"`
+	Name    string   `json:"name" xml:"name"`
+}
+
+func getUser(c lars.Context) error {
+
+	id, err := c.ParamInt("id")
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user{ID: id, Name: "Joey"})
+}
+
+func TestNewContext(t *testing.T) {
+
+	rec := NewRecorder()
+	c := NewContext(rec, httptest.NewRequest(lars.GET, "/users/7", nil), lars.Param{Key: "id", Value: "7"})
+
+	var u user
+
+	Equal(t, getUser(c), nil)
+	Equal(t, rec.Status(), http.StatusOK)
+	Equal(t, rec.DecodeJSON(&u), nil)
+	Equal(t, u.ID, 7)
+	Equal(t, u.Name, "Joey")
+
+	rec = NewRecorder()
+	c = NewContext(rec, httptest.NewRequest(lars.GET, "/users/joey", nil), lars.Param{Key: "id", Value: "joey"})
+
+	err := getUser(c)
+
+	NotEqual(t, err, nil)
+	Equal(t, err.(*lars.HTTPError).Code, http.StatusBadRequest)
+
+	rec = NewRecorder()
+	c = NewContext(rec, httptest.NewRequest(lars.GET, "/", nil))
+	c.RunHandlers(func(c lars.Context) {
+		c.Set("user", "joey")
+		c.Next()
+	}, func(c lars.Context) {
+		v, _ := c.Get("user")
+		c.Response().WriteString(v.(string))
+	})
+
+	Equal(t, rec.Status(), http.StatusOK)
+	Equal(t, rec.BodyString(), "joey")
+}
+
+func TestRequestBuilderRun(t *testing.T) {
+
+	var order []string
+
+	mw := func(c lars.Context) {
+		order = append(order, "mw")
+		c.Response().Header().Set("X-Middleware", "1")
+		c.Next()
+	}
+
+	rec := NewRequest(lars.GET, "/users/7").Param("id", "7").Run(mw, func(c lars.Context) error {
+		order = append(order, "handler")
+		return getUser(c)
+	})
+
+	var u user
+
+	Equal(t, order, []string{"mw", "handler"})
+	Equal(t, rec.Status(), http.StatusOK)
+	Equal(t, rec.Header().Get("X-Middleware"), "1")
+	Equal(t, rec.DecodeJSON(&u), nil)
+	Equal(t, u.ID, 7)
+
+	// errors are handled by the error handler as they would be by the router
+	rec = NewRequest(lars.GET, "/users/joey").Param("id", "joey").Run(getUser)
+
+	Equal(t, rec.Status(), http.StatusBadRequest)
+
+	rec = NewRequest(lars.POST, "/users").XML(user{ID: 3, Name: "Joey"}).Run(func(c lars.Context) error {
+
+		var u user
+
+		if err := c.Bind(&u); err != nil {
+			return err
+		}
+
+		return c.XML(http.StatusCreated, u)
+	})
+
+	u = user{}
+
+	Equal(t, rec.Status(), http.StatusCreated)
+	Equal(t, rec.DecodeXML(&u), nil)
+	Equal(t, u.ID, 3)
+	Equal(t, u.Name, "Joey")
+}
+
+func TestRequestBuilderServe(t *testing.T) {
+
+	l := lars.New()
+	l.Post("/users/:id", func(c lars.Context) error {
+
+		var u user
+
+		if err := c.Bind(&u); err != nil {
+			return err
+		}
+
+		cookie, _ := c.Request().Cookie("session")
+
+		return c.JSON(http.StatusOK, map[string]string{
+			"id":      c.Param("id"),
+			"name":    u.Name,
+			"sort":    c.QueryParam("sort"),
+			"page":    c.QueryParam("page"),
+			"auth":    c.Request().Header.Get(lars.Authorization),
+			"session": cookie.Value,
+		})
+	})
+
+	rec := NewRequest(lars.POST, "/users/7?sort=name").
+		Query("page", "2").
+		Header(lars.Authorization, "Bearer token").
+		Cookie(&http.Cookie{Name: "session", Value: "abc"}).
+		JSON(user{Name: "Joey"}).
+		Serve(l.Serve())
+
+	var m map[string]string
+
+	Equal(t, rec.Status(), http.StatusOK)
+	Equal(t, rec.DecodeJSON(&m), nil)
+	Equal(t, m, map[string]string{
+		"id":      "7",
+		"name":    "Joey",
+		"sort":    "name",
+		"page":    "2",
+		"auth":    "Bearer token",
+		"session": "abc",
+	})
+
+	l = lars.New()
+	l.Post("/login", func(c lars.Context) {
+		c.Response().WriteString(c.Request().FormValue("user"))
+	})
+
+	rec = NewRequest(lars.POST, "/login").Form(url.Values{"user": {"joey"}}).Serve(l.Serve())
+
+	Equal(t, rec.Status(), http.StatusOK)
+	Equal(t, rec.BodyString(), "joey")
+
+	rec = NewRequest(lars.GET, "/missing").Serve(l.Serve())
+
+	Equal(t, rec.Status(), http.StatusNotFound)
+
+	PanicMatches(t, func() { NewRequest(lars.POST, "/").JSON(make(chan int)).Build() }, "larstest: json: unsupported type: chan int")
+}
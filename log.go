@@ -0,0 +1,90 @@
+package lars
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"time"
+)
+
+// LogEntry is a single log entry buffered on the Context using Log
+type LogEntry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	Fields  []interface{}
+}
+
+// LogSink receives all log entries buffered during a request, together, once the
+// request's handlers have completed so they can be written as a single coherent group.
+// NOTE: the entries slice is reused once the sink returns and must not be retained.
+type LogSink func(c Context, entries []LogEntry)
+
+// SetLogSink sets the sink that receives the log entries buffered using c.Log,
+// the default writes them, as a single group, using the standard logger.
+func (l *LARS) SetLogSink(sink LogSink) {
+	l.logSink = sink
+}
+
+// Log buffers a log entry, with optional key/value pairs, on the Context; all entries
+// are flushed together to the registered LogSink once the request's handlers complete.
+//
+// i.e. c.Log("info", "user loaded", "id", 123, "cached", true)
+func (c *Ctx) Log(level, msg string, kv ...interface{}) {
+	c.logs = append(c.logs, LogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  kv,
+	})
+}
+
+// flushLogs passes the Context's buffered log entries to the registered LogSink.
+func (l *LARS) flushLogs(c *Ctx) {
+
+	if len(c.logs) == 0 {
+		return
+	}
+
+	l.logSink(c.parent, c.logs)
+
+	for i := range c.logs {
+		c.logs[i] = LogEntry{}
+	}
+
+	c.logs = c.logs[:0]
+}
+
+// defaultLogSink writes all entries in a single call to the standard logger
+// so they aren't interleaved with other requests' output.
+func defaultLogSink(c Context, entries []LogEntry) {
+
+	buff := new(bytes.Buffer)
+
+	fmt.Fprintf(buff, "%s %s", c.Request().Method, c.Request().URL.Path)
+
+	if id := c.RequestID(); id != blank {
+		fmt.Fprintf(buff, " request_id=%s", id)
+	}
+
+	buff.WriteByte('\n')
+
+	for _, e := range entries {
+
+		fmt.Fprintf(buff, "\t%s [%s] %s", e.Time.Format(time.RFC3339Nano), e.Level, e.Message)
+
+		for i := 0; i < len(e.Fields); i += 2 {
+
+			if i+1 < len(e.Fields) {
+				fmt.Fprintf(buff, " %v=%v", e.Fields[i], e.Fields[i+1])
+				continue
+			}
+
+			fmt.Fprintf(buff, " %v=<missing>", e.Fields[i])
+		}
+
+		buff.WriteByte('\n')
+	}
+
+	log.Print(buff.String())
+}
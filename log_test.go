@@ -0,0 +1,97 @@
+package lars
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestLog(t *testing.T) {
+
+	var flushed []LogEntry
+	var path string
+	var calls int
+
+	l := New()
+	l.SetLogSink(func(c Context, entries []LogEntry) {
+		calls++
+		path = c.Request().URL.Path
+		flushed = append([]LogEntry(nil), entries...)
+	})
+	l.Use(func(c Context) {
+		c.Log("debug", "middleware")
+		c.Next()
+	})
+	l.Get("/log", func(c Context) {
+		c.Log("info", "user loaded", "id", 123)
+		c.Log("error", "cache miss", "key")
+	})
+	l.Get("/quiet", func(c Context) {
+		c.Response().Write([]byte("quiet"))
+	})
+
+	code, _ := request(GET, "/log", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, calls, 1)
+	Equal(t, path, "/log")
+	Equal(t, len(flushed), 3)
+	Equal(t, flushed[0].Level, "debug")
+	Equal(t, flushed[0].Message, "middleware")
+	Equal(t, len(flushed[0].Fields), 0)
+	Equal(t, flushed[1].Level, "info")
+	Equal(t, flushed[1].Message, "user loaded")
+	Equal(t, flushed[1].Fields, []interface{}{"id", 123})
+	Equal(t, flushed[2].Fields, []interface{}{"key"})
+	Equal(t, flushed[0].Time.IsZero(), false)
+
+	// entries must not carry over to the next request using the pooled Context
+	code, _ = request(GET, "/quiet", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, calls, 2)
+	Equal(t, len(flushed), 1)
+
+	buff := new(bytes.Buffer)
+	log.SetOutput(buff)
+	defer log.SetOutput(os.Stderr)
+
+	l = New()
+	l.Get("/log", func(c Context) {
+		c.Log("info", "user loaded", "id", 123)
+		c.Log("error", "cache miss", "key")
+	})
+	l.Get("/quiet", basicHandler)
+
+	code, _ = request(GET, "/quiet", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, buff.String(), "")
+
+	code, _ = request(GET, "/log", l)
+	Equal(t, code, http.StatusOK)
+	MatchRegex(t, buff.String(), "GET /log\n\t\\S+ \\[info\\] user loaded id=123\n\t\\S+ \\[error\\] cache miss key=<missing>\n$")
+
+	buff.Reset()
+
+	l.Get("/id", func(c Context) {
+		c.SetRequestID("abc123")
+		c.Log("info", "identified")
+	})
+
+	code, _ = request(GET, "/id", l)
+	Equal(t, code, http.StatusOK)
+	MatchRegex(t, buff.String(), "GET /id request_id=abc123\n\t\\S+ \\[info\\] identified\n$")
+}
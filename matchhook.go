@@ -0,0 +1,104 @@
+package lars
+
+import "time"
+
+// MatchResult is the outcome of routing a request
+type MatchResult int
+
+// Route matching outcomes
+const (
+	// MatchFound is when the request matched a route
+	MatchFound MatchResult = iota
+
+	// MatchNotFound is when no route matched, the 404 handlers are run
+	MatchNotFound
+
+	// MatchMethodNotAllowed is when the path only matched routes of other methods,
+	// the 405 handlers are run
+	MatchMethodNotAllowed
+
+	// MatchRedirect is when the path only matched after fixing it's case or trailing slash,
+	// the client is redirected to it
+	MatchRedirect
+
+	// MatchOptions is when an OPTIONS request is answered automatically
+	MatchOptions
+)
+
+var matchResultNames = [...]string{
+	MatchFound:            "found",
+	MatchNotFound:         "not found",
+	MatchMethodNotAllowed: "method not allowed",
+	MatchRedirect:         "redirect",
+	MatchOptions:          "options",
+}
+
+func (m MatchResult) String() string {
+
+	if m < 0 || int(m) >= len(matchResultNames) {
+		return "unknown"
+	}
+
+	return matchResultNames[m]
+}
+
+// MatchInfo describes the routing of a single request
+type MatchInfo struct {
+	Method string
+	Host   string
+	Path   string
+
+	// Pattern is the path of the matched route i.e. /users/:id, blank unless
+	// Result is MatchFound
+	Pattern string
+
+	Result MatchResult
+
+	// Duration is how long finding the handlers took, excluding running them
+	Duration time.Duration
+}
+
+// MatchHook is notified of the outcome of every request's route lookup, for measuring
+// the router's overhead or finding clients hitting unregistered paths. Match is called
+// before the handlers are run, on the request's goroutine, so should be quick.
+type MatchHook interface {
+	Match(c Context, info MatchInfo)
+}
+
+// MatchHookFunc is an adapter allowing an ordinary function to be used as a MatchHook
+type MatchHookFunc func(c Context, info MatchInfo)
+
+// Match calls fn(c, info)
+func (fn MatchHookFunc) Match(c Context, info MatchInfo) {
+	fn(c, info)
+}
+
+// SetMatchHook sets the hook notified of the outcome of every route lookup, nil
+// to remove it. default nil
+//
+//	l.SetMatchHook(lars.MatchHookFunc(func(c lars.Context, info lars.MatchInfo) {
+//		if info.Result == lars.MatchNotFound {
+//			notFound.WithLabelValues(info.Path).Inc()
+//		}
+//	}))
+func (l *LARS) SetMatchHook(hook MatchHook) {
+	l.matchHook = hook
+}
+
+// notifyMatch notifies the hook of the outcome of the Context's route lookup started at start
+func (l *LARS) notifyMatch(c *Ctx, start time.Time, result MatchResult) {
+
+	info := MatchInfo{
+		Method:   c.request.Method,
+		Host:     c.request.Host,
+		Path:     c.request.URL.Path,
+		Result:   result,
+		Duration: time.Since(start),
+	}
+
+	if result == MatchFound && c.route != nil {
+		info.Pattern = c.route.path
+	}
+
+	l.matchHook.Match(c.parent, info)
+}
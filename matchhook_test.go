@@ -0,0 +1,84 @@
+package lars
+
+import (
+	"net/http"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestMatchHook(t *testing.T) {
+
+	var infos []MatchInfo
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.SetAutomaticallyHandleOPTIONS(true)
+	l.SetMatchHook(MatchHookFunc(func(c Context, info MatchInfo) {
+
+		// the hook is called before the handlers are run
+		Equal(t, c.Response().Committed(), false)
+
+		infos = append(infos, info)
+	}))
+
+	l.Get("/users/:id", basicHandler)
+
+	tests := []struct {
+		method  string
+		path    string
+		code    int
+		pattern string
+		result  MatchResult
+	}{
+		{GET, "/users/7", http.StatusOK, "/users/:id", MatchFound},
+		{GET, "/missing", http.StatusNotFound, "", MatchNotFound},
+		{POST, "/users/7", http.StatusMethodNotAllowed, "", MatchMethodNotAllowed},
+		{GET, "/users/7/", http.StatusMovedPermanently, "", MatchRedirect},
+		{GET, "/USERS/7", http.StatusMovedPermanently, "", MatchRedirect},
+		{OPTIONS, "/users/7", http.StatusNoContent, "", MatchOptions},
+	}
+
+	for i, tt := range tests {
+
+		infos = infos[:0]
+
+		code, _ := request(tt.method, tt.path, l)
+
+		Equal(t, code, tt.code)
+		Equal(t, len(infos), 1)
+
+		info := infos[0]
+
+		if info.Result != tt.result {
+			t.Errorf("test %d: expected result %s got %s", i, tt.result, info.Result)
+		}
+
+		Equal(t, info.Method, tt.method)
+		Equal(t, info.Path, tt.path)
+		Equal(t, info.Pattern, tt.pattern)
+	}
+
+	Equal(t, MatchMethodNotAllowed.String(), "method not allowed")
+	Equal(t, MatchResult(-1).String(), "unknown")
+
+	// removing the hook
+	l.SetMatchHook(nil)
+	infos = infos[:0]
+
+	code, _ := request(GET, "/users/7", l)
+
+	Equal(t, code, http.StatusOK)
+	Equal(t, len(infos), 0)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/lars"
+)
+
+const defaultRealm = "Restricted"
+
+// BasicAuthUserKey is the key the authenticated username is stored under using c.Set
+const BasicAuthUserKey = "lars.basicauth.user"
+
+// BasicAuthValidator validates the credentials parsed from the Authorization header
+type BasicAuthValidator func(user, pass string, c lars.Context) bool
+
+// BasicAuth returns a middleware which authenticates the request using HTTP Basic
+// Authentication with the default realm "Restricted".
+func BasicAuth(fn BasicAuthValidator) lars.HandlerFunc {
+	return BasicAuthRealm(defaultRealm, fn)
+}
+
+// BasicAuthRealm returns a middleware which authenticates the request using HTTP Basic
+// Authentication with the realm specified. On success the username is stored on the
+// Context under BasicAuthUserKey and the chain continues, otherwise a 401 Unauthorized
+// is returned along with the WWW-Authenticate challenge.
+func BasicAuthRealm(realm string, fn BasicAuthValidator) lars.HandlerFunc {
+
+	challenge := "Basic realm=" + strconv.Quote(realm)
+
+	return func(c lars.Context) {
+
+		if user, pass, ok := c.Request().BasicAuth(); ok && fn(user, pass, c) {
+			c.Set(BasicAuthUserKey, user)
+			c.Next()
+			return
+		}
+
+		c.Response().Header().Set(lars.WWWAuthenticate, challenge)
+		http.Error(c.Response(), http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}
+}
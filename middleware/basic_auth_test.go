@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestBasicAuth(t *testing.T) {
+
+	validator := func(user, pass string, c lars.Context) bool {
+		return user == "joeybloggs" && pass == "secret"
+	}
+
+	l := lars.New()
+	l.Use(BasicAuth(validator))
+	l.Get("/test", func(c lars.Context) {
+		user, _ := c.Get(BasicAuthUserKey)
+		c.Response().Write([]byte(user.(string)))
+	})
+
+	admin := l.Group("/admin", nil)
+	admin.Use(BasicAuthRealm("Admin Area", validator))
+	admin.Get("", func(c lars.Context) {
+		c.Response().Write([]byte("admin"))
+	})
+
+	r, _ := http.NewRequest(lars.GET, "/test", nil)
+	r.SetBasicAuth("joeybloggs", "secret")
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "joeybloggs")
+
+	r, _ = http.NewRequest(lars.GET, "/test", nil)
+	r.SetBasicAuth("joeybloggs", "wrong")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusUnauthorized)
+	Equal(t, w.Header().Get(lars.WWWAuthenticate), "Basic realm=\"Restricted\"")
+
+	r, _ = http.NewRequest(lars.GET, "/test", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusUnauthorized)
+
+	r, _ = http.NewRequest(lars.GET, "/test", nil)
+	r.Header.Set(lars.Authorization, "Basic not-base64")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusUnauthorized)
+
+	r, _ = http.NewRequest(lars.GET, "/admin", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusUnauthorized)
+	Equal(t, w.Header().Get(lars.WWWAuthenticate), "Basic realm=\"Admin Area\"")
+
+	r, _ = http.NewRequest(lars.GET, "/admin", nil)
+	r.SetBasicAuth("joeybloggs", "secret")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "admin")
+}
@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/lars"
+)
+
+// defaultBodyDumpMaxSize is the default number of bytes captured of each body
+const defaultBodyDumpMaxSize = 64 << 10
+
+// defaultBodyDumpContentTypes are the media types captured by default
+var defaultBodyDumpContentTypes = []string{
+	lars.ApplicationJSON,
+	lars.ApplicationXML,
+	lars.ApplicationForm,
+	"text/",
+}
+
+// BodyDumpConfig contains the BodyDump middleware's configuration
+type BodyDumpConfig struct {
+	// Handler is called with the captured bodies once the request completes, the
+	// response has been sent by then; required
+	Handler func(c lars.Context, dump Dump)
+
+	// MaxSize is the maximum number of bytes captured of each body, larger
+	// bodies are truncated. default 64KB
+	MaxSize int
+
+	// ContentTypes are the media types, or prefixes thereof i.e. "text/", of the bodies
+	// captured; bodies of other types, such as file uploads and downloads, are not.
+	// default application/json, application/xml, application/x-www-form-urlencoded and text/
+	ContentTypes []string
+}
+
+// Dump contains the request and response bodies captured by BodyDump
+type Dump struct {
+	// Request is the part of the request body read by the handlers
+	Request []byte
+
+	// Response is the response body as sent, after compression when BodyDump is
+	// registered before a compression middleware
+	Response []byte
+
+	// RequestTruncated and ResponseTruncated report whether the body exceeded MaxSize
+	RequestTruncated  bool
+	ResponseTruncated bool
+}
+
+// dumpBuffer captures up to max bytes of what's written to it
+type dumpBuffer struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (d *dumpBuffer) capture(b []byte) {
+
+	if n := d.max - len(d.buf); len(b) > n {
+		b = b[:n]
+		d.truncated = true
+	}
+
+	d.buf = append(d.buf, b...)
+}
+
+// dumpReader captures the request body as it's read by the handlers
+type dumpReader struct {
+	io.ReadCloser
+	dump *dumpBuffer
+}
+
+func (r *dumpReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.dump.capture(p[:n])
+	return
+}
+
+// dumpWriter captures the response body, when of a captured media type, as it's written
+type dumpWriter struct {
+	http.ResponseWriter
+	dump    *dumpBuffer
+	types   []string
+	started bool
+	capture bool
+}
+
+func (w *dumpWriter) Write(b []byte) (int, error) {
+
+	if !w.started {
+		w.started = true
+		w.capture = dumpContentType(w.Header().Get(lars.ContentType), w.types)
+	}
+
+	if w.capture {
+		w.dump.capture(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *dumpWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *dumpWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *dumpWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// dumpContentType returns whether the Content-Type is one of the captured media types
+func dumpContentType(contentType string, types []string) bool {
+
+	if i := strings.IndexByte(contentType, ';'); i != -1 {
+		contentType = contentType[:i]
+	}
+
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	for _, t := range types {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BodyDump returns a middleware which captures the request and response bodies, up to
+// MaxSize bytes of each and only of the configured media types, handing them to the
+// Handler once the request completes; for audit trails and troubleshooting production
+// issues. The request body is captured as it's read, so bodies are never buffered in
+// full nor read when the handlers don't.
+//
+//	l.Use(middleware.BodyDump(middleware.BodyDumpConfig{
+//		Handler: func(c lars.Context, dump middleware.Dump) {
+//			c.Log("debug", "bodies", "request", string(dump.Request), "response", string(dump.Response))
+//		},
+//	}))
+func BodyDump(config BodyDumpConfig) lars.HandlerFunc {
+
+	if config.Handler == nil {
+		panic("body dump: Handler is required")
+	}
+
+	if config.MaxSize <= 0 {
+		config.MaxSize = defaultBodyDumpMaxSize
+	}
+
+	types := config.ContentTypes
+	if len(types) == 0 {
+		types = defaultBodyDumpContentTypes
+	}
+
+	types = append([]string(nil), types...)
+
+	for i := range types {
+		types[i] = strings.ToLower(types[i])
+	}
+
+	return func(c lars.Context) {
+
+		reqDump := &dumpBuffer{max: config.MaxSize}
+		resDump := &dumpBuffer{max: config.MaxSize}
+
+		r := c.Request()
+
+		if r.Body != nil && r.Body != http.NoBody && dumpContentType(r.Header.Get(lars.ContentType), types) {
+			r.Body = &dumpReader{ReadCloser: r.Body, dump: reqDump}
+		}
+
+		res := c.Response()
+		orig := res.Writer()
+
+		res.SetWriter(&dumpWriter{ResponseWriter: orig, dump: resDump, types: types})
+		defer res.SetWriter(orig)
+
+		c.Next()
+
+		// restoring the writer commits a buffered response through the dump writer
+		res.SetWriter(orig)
+
+		config.Handler(c, Dump{
+			Request:           reqDump.buf,
+			Response:          resDump.buf,
+			RequestTruncated:  reqDump.truncated,
+			ResponseTruncated: resDump.truncated,
+		})
+	}
+}
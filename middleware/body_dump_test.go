@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestBodyDump(t *testing.T) {
+
+	var dump Dump
+	var status int
+
+	l := lars.New()
+	l.SetBufferedResponse(true)
+	l.Use(BodyDump(BodyDumpConfig{
+		MaxSize: 16,
+		Handler: func(c lars.Context, d Dump) {
+			dump = d
+			status = c.Response().Status()
+		},
+	}))
+	l.Post("/echo", func(c lars.Context) {
+		b, _ := ioutil.ReadAll(c.Request().Body)
+		c.JSONBytes(http.StatusCreated, b)
+	})
+	l.Post("/upload", func(c lars.Context) {
+		ioutil.ReadAll(c.Request().Body)
+		c.Response().Header().Set(lars.ContentType, "image/png")
+		c.Response().Write([]byte("png"))
+	})
+	l.Post("/ignored", func(c lars.Context) {
+		c.Text(http.StatusOK, "ok")
+	})
+
+	hf := l.Serve()
+
+	serve := func(path, contentType, body string) *httptest.ResponseRecorder {
+		dump = Dump{}
+		r, _ := http.NewRequest(lars.POST, path, strings.NewReader(body))
+		r.Header.Set(lars.ContentType, contentType)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/echo", lars.ApplicationJSONCharsetUTF8, `{"id":1}`)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), `{"id":1}`)
+	Equal(t, dump, Dump{Request: []byte(`{"id":1}`), Response: []byte(`{"id":1}`)})
+	Equal(t, status, http.StatusCreated)
+
+	// bodies are truncated
+	w = serve("/echo", lars.ApplicationJSON, `{"name":"joeybloggs"}`)
+	Equal(t, w.Body.String(), `{"name":"joeybloggs"}`)
+	Equal(t, dump, Dump{
+		Request:           []byte(`{"name":"joeyblo`),
+		Response:          []byte(`{"name":"joeyblo`),
+		RequestTruncated:  true,
+		ResponseTruncated: true,
+	})
+
+	// other media types aren't captured
+	w = serve("/upload", "image/png", "png")
+	Equal(t, w.Body.String(), "png")
+	Equal(t, dump, Dump{})
+
+	// unread request bodies aren't captured
+	serve("/ignored", lars.ApplicationForm, "a=b")
+	Equal(t, dump, Dump{Response: []byte("ok")})
+
+	l = lars.New()
+	l.Use(BodyDump(BodyDumpConfig{
+		ContentTypes: []string{"Application/CSV"},
+		Handler: func(c lars.Context, d Dump) {
+			dump = d
+		},
+	}))
+	l.Post("/csv", func(c lars.Context) {
+		b, _ := ioutil.ReadAll(c.Request().Body)
+		c.Response().Header().Set(lars.ContentType, "application/csv")
+		c.Response().Write(b)
+	})
+
+	hf = l.Serve()
+
+	serve("/csv", "application/csv; header=present", "a,b")
+	Equal(t, dump, Dump{Request: []byte("a,b"), Response: []byte("a,b")})
+
+	serve("/csv", lars.ApplicationJSON, "{}")
+	Equal(t, dump, Dump{Response: []byte("{}")})
+
+	PanicMatches(t, func() { BodyDump(BodyDumpConfig{}) }, "body dump: Handler is required")
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/lars"
+)
+
+// BodyLimit returns a middleware which limits the size of request bodies to n bytes;
+// requests whose Content-Length exceeds the limit are rejected with 413 Request Entity
+// Too Large, and reading beyond it, i.e. chunked bodies, fails so Decode, ParseForm etc.
+// return lars.ErrRequestEntityTooLarge.
+func BodyLimit(n int64) lars.HandlerFunc {
+
+	if n <= 0 {
+		panic("body limit: limit must be greater than zero")
+	}
+
+	msg := http.StatusText(http.StatusRequestEntityTooLarge) + ": request body exceeds " + strconv.FormatInt(n, 10) + " bytes"
+
+	return func(c lars.Context) {
+
+		r := c.Request()
+
+		if r.ContentLength > n {
+			http.Error(c.Response(), msg, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(c.Response(), r.Body, n)
+		}
+
+		c.Next()
+	}
+}
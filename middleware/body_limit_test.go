@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestBodyLimit(t *testing.T) {
+
+	l := lars.New()
+	l.Use(BodyLimit(8))
+	l.Post("/test", func(c lars.Context) error {
+
+		var v map[string]string
+
+		if err := c.Decode(false, 1024, &v); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, v["a"])
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(lars.POST, "/test", strings.NewReader(`{"a":""}`))
+	r.Header.Set(lars.ContentType, lars.ApplicationJSON)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+
+	r, _ = http.NewRequest(lars.POST, "/test", strings.NewReader(`{"a":"too large"}`))
+	r.Header.Set(lars.ContentType, lars.ApplicationJSON)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+	Equal(t, w.Body.String(), "Request Entity Too Large: request body exceeds 8 bytes\n")
+
+	// unknown length
+	r, _ = http.NewRequest(lars.POST, "/test", struct{ io.Reader }{strings.NewReader(`{"a":"too large"}`)})
+	r.Header.Set(lars.ContentType, lars.ApplicationJSON)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+	Equal(t, w.Body.String(), `{"message":"Request Entity Too Large"}`)
+
+	PanicMatches(t, func() { BodyLimit(0) }, "body limit: limit must be greater than zero")
+}
@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-playground/lars"
+)
+
+// CircuitState is the state of a route's circuit breaker
+type CircuitState int
+
+// circuit breaker states
+const (
+	// CircuitClosed lets all requests through, counting the failures
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails all requests fast with 503 Service Unavailable
+	CircuitOpen
+
+	// CircuitHalfOpen lets a limited number of probe requests through to test whether
+	// the route has recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerConfig configures the CircuitBreaker middleware
+type CircuitBreakerConfig struct {
+	// FailureRate is the rate of failed requests, between 0 and 1, at which the circuit
+	// opens. default 0.5
+	FailureRate float64
+
+	// MinRequests is the minimum number of requests within Window before the failure
+	// rate is evaluated. default 10
+	MinRequests int
+
+	// Window is the interval failures are counted over, counts are reset once it
+	// elapses. default 10 seconds
+	Window time.Duration
+
+	// OpenTimeout is how long the circuit stays open before letting probe requests
+	// through. default 30 seconds
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of probe requests, that must all succeed, let through
+	// while half-open before the circuit closes again. default 1
+	HalfOpenRequests int
+
+	// IsFailure returns whether the completed request failed. default a 5xx response status
+	IsFailure func(c lars.Context) bool
+
+	// OnStateChange is called when a route's circuit changes state i.e. for alerting;
+	// key is the route's method and path pattern.
+	OnStateChange func(key string, from, to CircuitState)
+}
+
+// circuit is the breaker of a single route
+type circuit struct {
+	state     CircuitState
+	requests  int
+	failures  int
+	windowEnd time.Time
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+type circuitBreakers struct {
+	config   CircuitBreakerConfig
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+// CircuitBreaker returns a middleware which fails requests fast with 503 Service
+// Unavailable, and a Retry-After header, once a route is consistently failing; each
+// route, by method and path pattern, has it's own circuit which opens once the failure
+// rate within a window reaches the threshold and, after the open timeout, lets probe
+// requests through to close it again once they succeed.
+func CircuitBreaker(config CircuitBreakerConfig) lars.HandlerFunc {
+	return newCircuitBreakers(config).handler()
+}
+
+func newCircuitBreakers(config CircuitBreakerConfig) *circuitBreakers {
+
+	if config.FailureRate <= 0 || config.FailureRate > 1 {
+		config.FailureRate = 0.5
+	}
+
+	if config.MinRequests < 1 {
+		config.MinRequests = 10
+	}
+
+	if config.Window <= 0 {
+		config.Window = time.Second * 10
+	}
+
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = time.Second * 30
+	}
+
+	if config.HalfOpenRequests < 1 {
+		config.HalfOpenRequests = 1
+	}
+
+	if config.IsFailure == nil {
+		config.IsFailure = func(c lars.Context) bool {
+			return c.Response().Status() >= http.StatusInternalServerError
+		}
+	}
+
+	return &circuitBreakers{
+		config:   config,
+		circuits: make(map[string]*circuit),
+		now:      time.Now,
+	}
+}
+
+func (cb *circuitBreakers) handler() lars.HandlerFunc {
+
+	return func(c lars.Context) {
+
+		route := c.Route()
+		if route == nil {
+			c.Next()
+			return
+		}
+
+		key := route.Method() + " " + route.Path()
+
+		allowed, retryAfter := cb.allow(key)
+		if !allowed {
+			c.Response().Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+			http.Error(c.Response(), http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		// a panicking handler is a failure, the recovery middleware is run after
+		defer func() {
+			if r := recover(); r != nil {
+				cb.done(key, true)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		cb.done(key, cb.config.IsFailure(c))
+	}
+}
+
+// allow returns whether a request to the route may proceed, or how long until the
+// circuit lets requests through when it may not.
+func (cb *circuitBreakers) allow(key string) (bool, time.Duration) {
+
+	cb.mu.Lock()
+
+	now := cb.now()
+
+	ci, ok := cb.circuits[key]
+	if !ok {
+		ci = &circuit{windowEnd: now.Add(cb.config.Window)}
+		cb.circuits[key] = ci
+	}
+
+	var from CircuitState
+	changed := false
+
+	if ci.state == CircuitOpen {
+
+		if remaining := ci.openedAt.Add(cb.config.OpenTimeout).Sub(now); remaining > 0 {
+			cb.mu.Unlock()
+			return false, remaining
+		}
+
+		from, changed = ci.state, true
+		ci.state = CircuitHalfOpen
+		ci.probes = 0
+		ci.successes = 0
+	}
+
+	allowed := true
+
+	if ci.state == CircuitHalfOpen {
+
+		if ci.probes < cb.config.HalfOpenRequests {
+			ci.probes++
+		} else {
+			allowed = false
+		}
+	}
+
+	cb.mu.Unlock()
+
+	if changed {
+		cb.stateChanged(key, from, CircuitHalfOpen)
+	}
+
+	if !allowed {
+		return false, time.Second
+	}
+
+	return true, 0
+}
+
+// done records the outcome of a request let through to the route.
+func (cb *circuitBreakers) done(key string, failed bool) {
+
+	cb.mu.Lock()
+
+	now := cb.now()
+	ci := cb.circuits[key]
+	from := ci.state
+
+	switch ci.state {
+	case CircuitClosed:
+
+		if !now.Before(ci.windowEnd) {
+			ci.requests, ci.failures = 0, 0
+			ci.windowEnd = now.Add(cb.config.Window)
+		}
+
+		ci.requests++
+
+		if failed {
+			ci.failures++
+		}
+
+		if ci.requests >= cb.config.MinRequests && float64(ci.failures)/float64(ci.requests) >= cb.config.FailureRate {
+			ci.state = CircuitOpen
+			ci.openedAt = now
+		}
+
+	case CircuitHalfOpen:
+
+		if failed {
+			ci.state = CircuitOpen
+			ci.openedAt = now
+			break
+		}
+
+		if ci.successes++; ci.successes >= cb.config.HalfOpenRequests {
+			ci.state = CircuitClosed
+			ci.requests, ci.failures = 0, 0
+			ci.windowEnd = now.Add(cb.config.Window)
+		}
+	}
+
+	to := ci.state
+
+	cb.mu.Unlock()
+
+	if from != to {
+		cb.stateChanged(key, from, to)
+	}
+}
+
+func (cb *circuitBreakers) stateChanged(key string, from, to CircuitState) {
+	if cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(key, from, to)
+	}
+}
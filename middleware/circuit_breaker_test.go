@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestCircuitBreaker(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	var changes []string
+
+	cb := newCircuitBreakers(CircuitBreakerConfig{
+		MinRequests: 4,
+		OpenTimeout: time.Second * 30,
+		OnStateChange: func(key string, from, to CircuitState) {
+			changes = append(changes, key+": "+from.String()+" -> "+to.String())
+		},
+	})
+	cb.now = func() time.Time { return now }
+
+	failing := true
+
+	l := lars.New()
+	l.Use(cb.handler())
+	l.Get("/downstream/:id", func(c lars.Context) {
+		if failing {
+			c.Response().WriteHeader(http.StatusBadGateway)
+			return
+		}
+		c.Text(http.StatusOK, "ok")
+	})
+	l.Get("/other", func(c lars.Context) {
+		c.Text(http.StatusOK, "ok")
+	})
+
+	hf := l.Serve()
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(lars.GET, path, nil)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	Equal(t, serve("/downstream/1").Code, http.StatusBadGateway)
+	Equal(t, serve("/downstream/2").Code, http.StatusBadGateway)
+	failing = false
+	Equal(t, serve("/downstream/3").Code, http.StatusOK)
+	failing = true
+	Equal(t, serve("/downstream/4").Code, http.StatusBadGateway)
+
+	// 3 of 4 failed
+	w := serve("/downstream/5")
+	Equal(t, w.Code, http.StatusServiceUnavailable)
+	Equal(t, w.Header().Get("Retry-After"), "30")
+	Equal(t, changes, []string{"GET /downstream/:id: closed -> open"})
+
+	// other routes are unaffected
+	Equal(t, serve("/other").Code, http.StatusOK)
+	Equal(t, serve("/notfound").Code, http.StatusNotFound)
+
+	now = now.Add(time.Second * 20)
+	Equal(t, serve("/downstream/1").Header().Get("Retry-After"), "10")
+
+	// a failing probe opens the circuit again
+	now = now.Add(time.Second * 10)
+	Equal(t, serve("/downstream/1").Code, http.StatusBadGateway)
+	Equal(t, serve("/downstream/1").Code, http.StatusServiceUnavailable)
+	Equal(t, changes[1:], []string{"GET /downstream/:id: open -> half-open", "GET /downstream/:id: half-open -> open"})
+
+	// a successful probe closes it
+	now = now.Add(time.Second * 30)
+	failing = false
+	Equal(t, serve("/downstream/1").Code, http.StatusOK)
+	Equal(t, serve("/downstream/1").Code, http.StatusOK)
+	Equal(t, changes[3:], []string{"GET /downstream/:id: open -> half-open", "GET /downstream/:id: half-open -> closed"})
+
+	// failures are counted per window
+	failing = true
+	Equal(t, serve("/downstream/1").Code, http.StatusBadGateway)
+	Equal(t, serve("/downstream/1").Code, http.StatusBadGateway)
+	now = now.Add(time.Second * 10)
+	Equal(t, serve("/downstream/1").Code, http.StatusBadGateway)
+	Equal(t, serve("/downstream/1").Code, http.StatusBadGateway)
+	Equal(t, len(changes), 5)
+
+	Equal(t, CircuitClosed.String(), "closed")
+}
+
+func TestCircuitBreakerHalfOpenProbes(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	cb := newCircuitBreakers(CircuitBreakerConfig{MinRequests: 1, HalfOpenRequests: 2})
+	cb.now = func() time.Time { return now }
+
+	ok, _ := cb.allow("GET /")
+	Equal(t, ok, true)
+	cb.done("GET /", true)
+
+	ok, retry := cb.allow("GET /")
+	Equal(t, ok, false)
+	Equal(t, retry, time.Second*30)
+
+	now = now.Add(time.Second * 30)
+
+	// only HalfOpenRequests probes are let through at once
+	ok, _ = cb.allow("GET /")
+	Equal(t, ok, true)
+	ok, _ = cb.allow("GET /")
+	Equal(t, ok, true)
+	ok, retry = cb.allow("GET /")
+	Equal(t, ok, false)
+	Equal(t, retry, time.Second)
+
+	cb.done("GET /", false)
+	Equal(t, cb.circuits["GET /"].state, CircuitHalfOpen)
+	cb.done("GET /", false)
+	Equal(t, cb.circuits["GET /"].state, CircuitClosed)
+
+	l := lars.New()
+	l.Use(CircuitBreaker(CircuitBreakerConfig{MinRequests: 1}))
+	l.Get("/panic", func(c lars.Context) { panic("boom") })
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(lars.GET, "/panic", nil)
+	PanicMatches(t, func() { hf.ServeHTTP(httptest.NewRecorder(), r) }, "boom")
+
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusServiceUnavailable)
+}
@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/lars"
+)
+
+// compressMinLength is the minimum body size, in bytes, worth compressing;
+// smaller bodies are sent as is as the compression overhead outweighs the savings.
+const compressMinLength = 1024
+
+// incompressibleTypes are the media types which are already compressed
+var incompressibleTypes = map[string]struct{}{
+	"application/gzip":             {},
+	"application/x-gzip":           {},
+	"application/zip":              {},
+	"application/x-bzip2":          {},
+	"application/x-7z-compressed":  {},
+	"application/x-rar-compressed": {},
+	"application/zstd":             {},
+	"application/pdf":              {},
+	"application/octet-stream":     {},
+	"font/woff":                    {},
+	"font/woff2":                   {},
+}
+
+// Encoder compresses everything written to it into the writer it was last reset with,
+// encoders are pooled and reset for each response; *gzip.Writer as well as the writers
+// of the popular brotli and zstd packages satisfy it.
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Encoding is a content coding the response may be compressed with
+type Encoding struct {
+	// Name is the content coding sent as the Content-Encoding i.e. lars.Brotli
+	Name string
+
+	// Level is the compression level passed to New
+	Level int
+
+	// New returns an Encoder, writing to w, compressing at level; an error is
+	// returned when the level isn't valid
+	New func(w io.Writer, level int) (Encoder, error)
+}
+
+// CompressConfig contains the Compress middleware's configuration
+type CompressConfig struct {
+	// Encodings are the content codings responses may be compressed with, in order of
+	// preference; the one the client values most is used, those valued equally are
+	// chosen in this order so list the one producing the smallest output first.
+	// default []Encoding{GzipEncoding(gzip.DefaultCompression)}
+	Encodings []Encoding
+}
+
+// encoder is an Encoding with it's pool of Encoders
+type encoder struct {
+	name string
+	pool *sync.Pool
+}
+
+// compressWriter buffers the start of the body until it's known whether it's worth
+// compressing, the response is compressed once compressMinLength bytes have been
+// written or it's flushed, smaller bodies are written as is once closed.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+	enc      Encoder
+	buf      []byte
+	status   int
+	started  bool
+	hijacked bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+
+	if w.started {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.status = code
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+
+	if !w.started {
+
+		w.buf = append(w.buf, b...)
+
+		if len(w.buf) >= compressMinLength {
+			if err := w.start(true); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(b), nil
+	}
+
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// start writes the header, compressing the response when compress is set and
+// the response is compressible, followed by anything buffered so far.
+func (w *compressWriter) start(compress bool) (err error) {
+
+	w.started = true
+
+	h := w.Header()
+
+	if len(w.buf) > 0 && h.Get(lars.ContentType) == "" {
+		h.Set(lars.ContentType, http.DetectContentType(w.buf))
+	}
+
+	if compress && w.compressible() {
+		h.Del(lars.ContentLength)
+		h.Set(lars.ContentEncoding, w.encoding)
+
+		w.enc = w.pool.Get().(Encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if len(w.buf) > 0 {
+		if w.enc != nil {
+			_, err = w.enc.Write(w.buf)
+		} else {
+			_, err = w.ResponseWriter.Write(w.buf)
+		}
+	}
+
+	w.buf = nil
+
+	return
+}
+
+// compressible returns whether the response has a body which isn't already
+// encoded or of an already compressed media type.
+func (w *compressWriter) compressible() bool {
+
+	if w.status == http.StatusNoContent || w.status == http.StatusNotModified || (w.status != 0 && w.status < http.StatusOK) {
+		return false
+	}
+
+	h := w.Header()
+
+	if h.Get(lars.ContentEncoding) != "" {
+		return false
+	}
+
+	typ := h.Get(lars.ContentType)
+	if i := strings.IndexByte(typ, ';'); i != -1 {
+		typ = typ[:i]
+	}
+
+	typ = strings.ToLower(strings.TrimSpace(typ))
+
+	if _, ok := incompressibleTypes[typ]; ok {
+		return false
+	}
+
+	if strings.HasPrefix(typ, "image/") {
+		return typ == "image/svg+xml"
+	}
+
+	return !strings.HasPrefix(typ, "video/") && !strings.HasPrefix(typ, "audio/")
+}
+
+// Flush compresses, when compressible, and sends everything written so far.
+func (w *compressWriter) Flush() {
+
+	if !w.started {
+		w.start(true)
+	}
+
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes anything still buffered and completes the compressed stream.
+func (w *compressWriter) Close() error {
+
+	if w.hijacked {
+		return nil
+	}
+
+	if !w.started {
+		if err := w.start(false); err != nil {
+			return err
+		}
+	}
+
+	if w.enc == nil {
+		return nil
+	}
+
+	err := w.enc.Close()
+	w.pool.Put(w.enc)
+	w.enc = nil
+
+	return err
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *compressWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// Compress returns a middleware which compresses HTTP responses using the content coding,
+// out of the configured Encodings, the client values most. Bodies smaller than 1KB,
+// already encoded or of an already compressed media type, such as images, are sent as
+// is; range and websocket upgrade requests are never compressed. It panics when an
+// Encoding's level isn't valid.
+//
+//	middleware.Compress(middleware.CompressConfig{
+//		Encodings: []middleware.Encoding{
+//			{Name: lars.Brotli, Level: 5, New: newBrotliEncoder},
+//			{Name: lars.Zstd, Level: 3, New: newZstdEncoder},
+//			middleware.GzipEncoding(gzip.DefaultCompression),
+//		},
+//	})
+func Compress(config CompressConfig) lars.HandlerFunc {
+
+	if len(config.Encodings) == 0 {
+		return Gzip
+	}
+
+	encoders := make([]encoder, len(config.Encodings))
+	names := make([]string, len(config.Encodings))
+
+	for i, e := range config.Encodings {
+		encoders[i] = encoder{name: e.Name, pool: newEncoderPool(e)}
+		names[i] = e.Name
+	}
+
+	return func(c lars.Context) {
+
+		name := c.AcceptEncoding(names...)
+
+		for _, e := range encoders {
+			if e.name == name {
+				serveCompressed(c, e)
+				return
+			}
+		}
+
+		serveCompressed(c, encoder{})
+	}
+}
+
+// newEncoderPool returns the pool of the Encoding's Encoders, testing the level
+// up front so it doesn't have to be each time one is created in the pool.
+func newEncoderPool(e Encoding) *sync.Pool {
+
+	if _, err := e.New(ioutil.Discard, e.Level); err != nil {
+		panic("compress: " + e.Name + ": " + err.Error())
+	}
+
+	return &sync.Pool{
+		New: func() interface{} {
+			enc, _ := e.New(ioutil.Discard, e.Level)
+			return enc
+		},
+	}
+}
+
+// serveCompressed runs the remaining handlers compressing the response with e, the
+// response is sent as is when e is the zero value, as no encoding is acceptable.
+func serveCompressed(c lars.Context, e encoder) {
+
+	c.Response().Header().Add(lars.Vary, lars.AcceptEncoding)
+
+	r := c.Request()
+
+	if e.pool == nil || r.Header.Get(lars.Upgrade) != "" || r.Header.Get("Range") != "" {
+		c.Next()
+		return
+	}
+
+	orig := c.Response().Writer()
+	cw := &compressWriter{ResponseWriter: orig, encoding: e.name, pool: e.pool}
+
+	defer func() {
+		// restoring the writer commits a buffered response through
+		// the compress writer before it's closed
+		c.Response().SetWriter(orig)
+		cw.Close()
+	}()
+
+	c.Response().SetWriter(cw)
+
+	c.Next()
+}
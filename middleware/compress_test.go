@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+var deflateEncoding = Encoding{
+	Name:  "deflate",
+	Level: flate.BestCompression,
+	New: func(w io.Writer, level int) (Encoder, error) {
+		return flate.NewWriter(w, level)
+	},
+}
+
+func TestCompress(t *testing.T) {
+
+	l := lars.New()
+	l.Use(Compress(CompressConfig{
+		Encodings: []Encoding{deflateEncoding, GzipEncoding(gzip.BestSpeed)},
+	}))
+	l.Get("/test", func(c lars.Context) {
+		c.Text(http.StatusOK, testBody)
+	})
+
+	hf := l.Serve()
+
+	serve := func(acceptEncoding string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(lars.GET, "/test", nil)
+		r.Header.Set(lars.AcceptEncoding, acceptEncoding)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	// codings valued equally are chosen in the configured order
+	w := serve("gzip, deflate")
+	Equal(t, w.Header().Get(lars.ContentEncoding), "deflate")
+	Equal(t, w.Header().Get(lars.Vary), lars.AcceptEncoding)
+
+	b, err := ioutil.ReadAll(flate.NewReader(w.Body))
+	Equal(t, err, nil)
+	Equal(t, string(b), testBody)
+
+	w = serve("deflate;q=0.5, gzip")
+	Equal(t, w.Header().Get(lars.ContentEncoding), lars.Gzip)
+
+	r, err := gzip.NewReader(w.Body)
+	Equal(t, err, nil)
+
+	b, err = ioutil.ReadAll(r)
+	Equal(t, err, nil)
+	Equal(t, string(b), testBody)
+
+	w = serve("*")
+	Equal(t, w.Header().Get(lars.ContentEncoding), "deflate")
+
+	w = serve("br, zstd")
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Header().Get(lars.Vary), lars.AcceptEncoding)
+	Equal(t, w.Body.String(), testBody)
+
+	// gzip by default
+	l = lars.New()
+	l.Use(Compress(CompressConfig{}))
+	l.Get("/test", func(c lars.Context) {
+		c.Text(http.StatusOK, testBody)
+	})
+
+	hf = l.Serve()
+
+	w = serve("deflate, gzip")
+	Equal(t, w.Header().Get(lars.ContentEncoding), lars.Gzip)
+
+	bad := deflateEncoding
+	bad.Level = 42
+
+	PanicMatches(t, func() { Compress(CompressConfig{Encodings: []Encoding{bad}}) }, "compress: deflate: flate: invalid compression level 42: want value in range [-2, 9]")
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+
+	"github.com/go-playground/lars"
+)
+
+// etagWriter captures the response so it can be tagged once the handler chain returns,
+// once flushed the response is no longer captured and is sent as is.
+type etagWriter struct {
+	http.ResponseWriter
+	buf         *bytes.Buffer
+	status      int
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+
+	return w.buf.Write(b)
+}
+
+// Flush stops capturing the response, it's being streamed, and sends everything
+// written so far.
+func (w *etagWriter) Flush() {
+
+	if !w.passthrough {
+
+		w.passthrough = true
+
+		if w.status != 0 {
+			w.ResponseWriter.WriteHeader(w.status)
+		}
+
+		w.ResponseWriter.Write(w.buf.Bytes())
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough = true
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *etagWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// ETag is a middleware which tags successful GET responses with an ETag generated from
+// the body, unless the handler set one itself, and answers requests whose If-None-Match
+// matches it, or If-Modified-Since isn't before the Last-Modified set by the handler,
+// with a 304 Not Modified without the body. The body is captured until the handler
+// chain returns and is then served as c.ServeContent would, including Range requests;
+// streamed responses are sent as is once flushed.
+//
+// NOTE: register ETag after any compression middleware, i.e. l.Use(Gzip, ETag), so
+// that the tag is generated from the uncompressed body.
+func ETag(c lars.Context) {
+
+	r := c.Request()
+
+	if r.Method != lars.GET {
+		c.Next()
+		return
+	}
+
+	res := c.Response()
+	w := res.Writer()
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	defer bufferPool.Put(buf)
+
+	ew := &etagWriter{ResponseWriter: w, buf: buf}
+	res.SetWriter(ew)
+
+	c.Next()
+
+	res.SetWriter(w)
+
+	if ew.passthrough || (ew.status == 0 && buf.Len() == 0) {
+		return
+	}
+
+	if ew.status != 0 && ew.status != http.StatusOK {
+		w.WriteHeader(ew.status)
+		w.Write(buf.Bytes())
+		return
+	}
+
+	body := buf.Bytes()
+	h := w.Header()
+
+	if h.Get(lars.ETag) == "" {
+		h.Set(lars.ETag, lars.GenerateETag(body))
+	}
+
+	if h.Get(lars.ContentType) == "" {
+		h.Set(lars.ContentType, http.DetectContentType(body))
+	}
+
+	modtime, _ := http.ParseTime(h.Get(lars.LastModified))
+
+	http.ServeContent(w, r, "", modtime, bytes.NewReader(body))
+}
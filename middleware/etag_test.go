@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestETag(t *testing.T) {
+
+	l := lars.New()
+	l.Use(ETag)
+	l.Get("/json", func(c lars.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"name": "lars"})
+	})
+	l.Get("/tagged", func(c lars.Context) error {
+		c.Response().Header().Set(lars.ETag, `W/"v1"`)
+		c.Response().Header().Set(lars.LastModified, "Sat, 02 Jan 2016 03:04:05 GMT")
+		return c.Text(http.StatusOK, "tagged")
+	})
+	l.Get("/sniff", func(c lars.Context) {
+		c.Response().Write([]byte("<html></html>"))
+	})
+	l.Get("/created", func(c lars.Context) error {
+		return c.Text(http.StatusCreated, "created")
+	})
+	l.Get("/empty", func(c lars.Context) {
+	})
+	l.Get("/flush", func(c lars.Context) {
+		c.Response().Write([]byte("a"))
+		c.Response().Flush()
+		c.Response().Write([]byte("b"))
+	})
+	l.Post("/json", func(c lars.Context) error {
+		return c.Text(http.StatusOK, "posted")
+	})
+
+	tag := lars.GenerateETag([]byte(`{"name":"lars"}`))
+
+	r, _ := http.NewRequest(lars.GET, "/json", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), tag)
+	Equal(t, w.Header().Get(lars.ContentType), lars.ApplicationJSONCharsetUTF8)
+	Equal(t, w.Header().Get(lars.ContentLength), "15")
+	Equal(t, w.Body.String(), `{"name":"lars"}`)
+
+	r, _ = http.NewRequest(lars.GET, "/json", nil)
+	r.Header.Set(lars.IfNoneMatch, `"other", `+tag)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusNotModified)
+	Equal(t, w.Header().Get(lars.ETag), tag)
+	Equal(t, w.Header().Get(lars.ContentType), "")
+	Equal(t, w.Body.Len(), 0)
+
+	r, _ = http.NewRequest(lars.GET, "/json", nil)
+	r.Header.Set(lars.IfNoneMatch, `"other"`)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), `{"name":"lars"}`)
+
+	// the handler's own tag is compared weakly
+	r, _ = http.NewRequest(lars.GET, "/tagged", nil)
+	r.Header.Set(lars.IfNoneMatch, `"v1"`)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusNotModified)
+	Equal(t, w.Header().Get(lars.ETag), `W/"v1"`)
+
+	r, _ = http.NewRequest(lars.GET, "/tagged", nil)
+	r.Header.Set(lars.IfModifiedSince, "Sat, 02 Jan 2016 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusNotModified)
+
+	r, _ = http.NewRequest(lars.GET, "/tagged", nil)
+	r.Header.Set(lars.IfModifiedSince, "Fri, 01 Jan 2016 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "tagged")
+
+	r, _ = http.NewRequest(lars.GET, "/sniff", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentType), "text/html; charset=utf-8")
+	Equal(t, w.Header().Get(lars.ETag), lars.GenerateETag([]byte("<html></html>")))
+
+	r, _ = http.NewRequest(lars.GET, "/created", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Header().Get(lars.ETag), "")
+	Equal(t, w.Body.String(), "created")
+
+	r, _ = http.NewRequest(lars.GET, "/empty", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), "")
+
+	r, _ = http.NewRequest(lars.GET, "/flush", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), "")
+	Equal(t, w.Body.String(), "ab")
+
+	r, _ = http.NewRequest(lars.POST, "/json", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), "")
+	Equal(t, w.Body.String(), "posted")
+}
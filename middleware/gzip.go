@@ -1,76 +1,46 @@
 package middleware
 
 import (
-	"bufio"
 	"compress/gzip"
 	"io"
 	"io/ioutil"
-	"net"
-	"net/http"
-	"strings"
 	"sync"
 
 	"github.com/go-playground/lars"
 )
 
-type gzipWriter struct {
-	io.Writer
-	http.ResponseWriter
-	sniffComplete bool
-}
-
-func (w gzipWriter) Write(b []byte) (int, error) {
-
-	if !w.sniffComplete {
-		if w.Header().Get(lars.ContentType) == "" {
-			w.Header().Set(lars.ContentType, http.DetectContentType(b))
-		}
-		w.sniffComplete = true
-	}
-
-	return w.Writer.Write(b)
-}
-
-func (w gzipWriter) Flush() error {
-	return w.Writer.(*gzip.Writer).Flush()
-}
-
-func (w gzipWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return w.ResponseWriter.(http.Hijacker).Hijack()
-}
-
-func (w *gzipWriter) CloseNotify() <-chan bool {
-	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
-}
-
 var writerPool = sync.Pool{
 	New: func() interface{} {
 		return gzip.NewWriter(ioutil.Discard)
 	},
 }
 
-// Gzip returns a middleware which compresses HTTP response using gzip compression
-// scheme.
-func Gzip(c lars.Context) {
-
-	c.Response().Header().Add(lars.Vary, lars.AcceptEncoding)
+var gzipEncoder = encoder{name: lars.Gzip, pool: &writerPool}
 
-	if strings.Contains(c.Request().Header.Get(lars.AcceptEncoding), lars.Gzip) {
-
-		w := writerPool.Get().(*gzip.Writer)
-		w.Reset(c.Response().Writer())
+// GzipEncoding returns the gzip Encoding, for use with Compress, compressing at level
+// i.e. gzip.BestSpeed
+func GzipEncoding(level int) Encoding {
+	return Encoding{
+		Name:  lars.Gzip,
+		Level: level,
+		New: func(w io.Writer, level int) (Encoder, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+	}
+}
 
-		defer func() {
-			w.Close()
-			writerPool.Put(w)
-		}()
+// Gzip returns a middleware which compresses HTTP response using gzip compression
+// scheme when the client accepts it. Bodies smaller than 1KB, already encoded or of
+// an already compressed media type, such as images, are sent as is; range and
+// websocket upgrade requests are never compressed. See Compress for other encodings.
+func Gzip(c lars.Context) {
 
-		gw := gzipWriter{Writer: w, ResponseWriter: c.Response().Writer()}
-		c.Response().Header().Set(lars.ContentEncoding, lars.Gzip)
-		c.Response().SetWriter(gw)
+	if c.AcceptEncoding(lars.Gzip) == "" {
+		serveCompressed(c, encoder{})
+		return
 	}
 
-	c.Next()
+	serveCompressed(c, gzipEncoder)
 }
 
 // GzipLevel returns a middleware which compresses HTTP response using gzip compression
@@ -84,31 +54,5 @@ func GzipLevel(level int) lars.HandlerFunc {
 		panic(err)
 	}
 
-	var pool = sync.Pool{
-		New: func() interface{} {
-			z, _ := gzip.NewWriterLevel(ioutil.Discard, level)
-			return z
-		},
-	}
-
-	return func(c lars.Context) {
-		c.Response().Header().Add(lars.Vary, lars.AcceptEncoding)
-
-		if strings.Contains(c.Request().Header.Get(lars.AcceptEncoding), lars.Gzip) {
-
-			w := pool.Get().(*gzip.Writer)
-			w.Reset(c.Response().Writer())
-
-			defer func() {
-				w.Close()
-				pool.Put(w)
-			}()
-
-			gw := gzipWriter{Writer: w, ResponseWriter: c.Response().Writer()}
-			c.Response().Header().Set(lars.ContentEncoding, lars.Gzip)
-			c.Response().SetWriter(gw)
-		}
-
-		c.Next()
-	}
+	return Compress(CompressConfig{Encodings: []Encoding{GzipEncoding(level)}})
 }
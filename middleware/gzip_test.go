@@ -5,14 +5,17 @@ import (
 	"bytes"
 	"compress/flate"
 	"compress/gzip"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-playground/lars"
+	"github.com/gorilla/websocket"
 	. "gopkg.in/go-playground/assert.v1"
 )
 
@@ -27,11 +30,14 @@ import (
 // go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
 //
 
+// testBody is large enough to be compressed
+var testBody = strings.Repeat("test", 512)
+
 func TestGzip(t *testing.T) {
 	l := lars.New()
 	l.Use(Gzip)
 	l.Get("/test", func(c lars.Context) {
-		c.Response().Write([]byte("test"))
+		c.Response().Write([]byte(testBody))
 	})
 
 	server := httptest.NewServer(l.Serve())
@@ -47,7 +53,7 @@ func TestGzip(t *testing.T) {
 
 	b, err := ioutil.ReadAll(resp.Body)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), testBody)
 
 	req, _ = http.NewRequest(lars.GET, server.URL+"/test", nil)
 	req.Header.Set(lars.AcceptEncoding, "gzip")
@@ -64,7 +70,7 @@ func TestGzip(t *testing.T) {
 
 	b, err = ioutil.ReadAll(r)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), testBody)
 }
 
 func TestGzipLevel(t *testing.T) {
@@ -75,7 +81,7 @@ func TestGzipLevel(t *testing.T) {
 	l := lars.New()
 	l.Use(GzipLevel(flate.BestCompression))
 	l.Get("/test", func(c lars.Context) {
-		c.Response().Write([]byte("test"))
+		c.Response().Write([]byte(testBody))
 	})
 
 	server := httptest.NewServer(l.Serve())
@@ -91,7 +97,7 @@ func TestGzipLevel(t *testing.T) {
 
 	b, err := ioutil.ReadAll(resp.Body)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), testBody)
 
 	req, _ = http.NewRequest(lars.GET, server.URL+"/test", nil)
 	req.Header.Set(lars.AcceptEncoding, "gzip")
@@ -108,42 +114,47 @@ func TestGzipLevel(t *testing.T) {
 
 	b, err = ioutil.ReadAll(r)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), testBody)
 }
 
 func TestGzipFlush(t *testing.T) {
 
 	rec := httptest.NewRecorder()
-	buff := new(bytes.Buffer)
-
-	w := gzip.NewWriter(buff)
-	gw := gzipWriter{Writer: w, ResponseWriter: rec}
+	gw := &compressWriter{ResponseWriter: rec, encoding: lars.Gzip, pool: &writerPool}
 
-	Equal(t, buff.Len(), 0)
-
-	err := gw.Flush()
+	_, err := gw.Write([]byte("x"))
 	Equal(t, err, nil)
+	Equal(t, rec.Body.Len(), 0)
+
+	// flushing compresses whatever was written so far
+	gw.Flush()
+	Equal(t, rec.Flushed, true)
+	Equal(t, rec.Header().Get(lars.ContentEncoding), lars.Gzip)
 
-	n1 := buff.Len()
+	n1 := rec.Body.Len()
 	NotEqual(t, n1, 0)
 
-	_, err = gw.Write([]byte("x"))
+	_, err = gw.Write([]byte("y"))
 	Equal(t, err, nil)
+	Equal(t, rec.Body.Len(), n1)
 
-	n2 := buff.Len()
-	Equal(t, n1, n2)
+	gw.Flush()
+	NotEqual(t, rec.Body.Len(), n1)
 
-	err = gw.Flush()
+	Equal(t, gw.Close(), nil)
+
+	r, err := gzip.NewReader(rec.Body)
+	Equal(t, err, nil)
+
+	b, err := ioutil.ReadAll(r)
 	Equal(t, err, nil)
-	NotEqual(t, n2, buff.Len())
+	Equal(t, string(b), "xy")
 }
 
 func TestGzipCloseNotify(t *testing.T) {
 
 	rec := newCloseNotifyingRecorder()
-	buf := new(bytes.Buffer)
-	w := gzip.NewWriter(buf)
-	gw := gzipWriter{Writer: w, ResponseWriter: rec}
+	gw := &compressWriter{ResponseWriter: rec, encoding: lars.Gzip, pool: &writerPool}
 	closed := false
 	notifier := gw.CloseNotify()
 	rec.close()
@@ -160,14 +171,16 @@ func TestGzipCloseNotify(t *testing.T) {
 func TestGzipHijack(t *testing.T) {
 
 	rec := newCloseNotifyingRecorder()
-	buf := new(bytes.Buffer)
-	w := gzip.NewWriter(buf)
-	gw := gzipWriter{Writer: w, ResponseWriter: rec}
+	gw := &compressWriter{ResponseWriter: rec, encoding: lars.Gzip, pool: &writerPool}
 
 	_, bufrw, err := gw.Hijack()
 	Equal(t, err, nil)
 
 	bufrw.WriteString("test")
+
+	// nothing is written once hijacked
+	Equal(t, gw.Close(), nil)
+	Equal(t, rec.Header().Get(lars.ContentEncoding), "")
 }
 
 type closeNotifyingRecorder struct {
@@ -196,3 +209,255 @@ func (c *closeNotifyingRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	writer := bufio.NewWriter(c.Body)
 	return nil, bufio.NewReadWriter(reader, writer), nil
 }
+
+func TestGzipBufferedResponse(t *testing.T) {
+
+	l := lars.New()
+	l.SetBufferedResponse(true)
+	l.Use(Gzip)
+	l.Get("/test", func(c lars.Context) {
+		c.Text(http.StatusCreated, testBody)
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	req, _ := http.NewRequest(lars.GET, server.URL+"/test", nil)
+	req.Header.Set(lars.AcceptEncoding, "gzip")
+
+	client := &http.Client{}
+
+	resp, err := client.Do(req)
+	Equal(t, err, nil)
+	Equal(t, resp.StatusCode, http.StatusCreated)
+	Equal(t, resp.Header.Get(lars.ContentEncoding), lars.Gzip)
+
+	r, err := gzip.NewReader(resp.Body)
+	Equal(t, err, nil)
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	Equal(t, err, nil)
+	Equal(t, string(b), testBody)
+}
+
+func TestGzipNegotiation(t *testing.T) {
+
+	l := lars.New()
+	l.Use(Gzip)
+	l.Get("/test", func(c lars.Context) {
+		c.Text(http.StatusOK, testBody)
+	})
+
+	hf := l.Serve()
+
+	tests := []struct {
+		acceptEncoding string
+		compressed     bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP", true},
+		{"deflate, gzip;q=0.5", true},
+		{"*", true},
+		{"gzip;q=0", false},
+		{"gzip;q=0, *", false},
+		{"*;q=0", false},
+		{"deflate, br", false},
+	}
+
+	for i, tt := range tests {
+
+		r, _ := http.NewRequest(lars.GET, "/test", nil)
+		r.Header.Set(lars.AcceptEncoding, tt.acceptEncoding)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+
+		if compressed := w.Header().Get(lars.ContentEncoding) == lars.Gzip; compressed != tt.compressed {
+			t.Errorf("test %d: expected compressed %t for '%s'", i, tt.compressed, tt.acceptEncoding)
+		}
+
+		Equal(t, w.Header().Get(lars.Vary), lars.AcceptEncoding)
+	}
+}
+
+func TestGzipSkipped(t *testing.T) {
+
+	png := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{0}, 2048)...)
+
+	l := lars.New()
+	l.Use(Gzip)
+	l.Get("/small", func(c lars.Context) {
+		c.Text(http.StatusOK, "small")
+	})
+	l.Get("/image", func(c lars.Context) {
+		c.Response().Write(png)
+	})
+	l.Get("/svg", func(c lars.Context) {
+		c.Response().Header().Set(lars.ContentType, "image/svg+xml")
+		c.Response().Write([]byte(testBody))
+	})
+	l.Get("/encoded", func(c lars.Context) {
+		c.Response().Header().Set(lars.ContentEncoding, "br")
+		c.Response().Write([]byte(testBody))
+	})
+	l.Get("/nocontent", func(c lars.Context) {
+		c.Response().WriteHeader(http.StatusNoContent)
+	})
+	l.Head("/head", func(c lars.Context) {
+		c.Response().Header().Set(lars.ContentLength, "2048")
+		c.Response().WriteHeader(http.StatusOK)
+	})
+
+	hf := l.Serve()
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, path, nil)
+		r.Header.Set(lars.AcceptEncoding, "gzip")
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(lars.GET, "/small")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Header().Get(lars.ContentLength), "5")
+	Equal(t, w.Header().Get(lars.ContentType), lars.TextPlainCharsetUTF8)
+	Equal(t, w.Body.String(), "small")
+
+	w = serve(lars.GET, "/image")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Header().Get(lars.ContentType), "image/png")
+	Equal(t, w.Body.Bytes(), png)
+
+	w = serve(lars.GET, "/svg")
+	Equal(t, w.Header().Get(lars.ContentEncoding), lars.Gzip)
+
+	w = serve(lars.GET, "/encoded")
+	Equal(t, w.Header().Get(lars.ContentEncoding), "br")
+	Equal(t, w.Body.String(), testBody)
+
+	w = serve(lars.GET, "/nocontent")
+	Equal(t, w.Code, http.StatusNoContent)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Body.Len(), 0)
+
+	w = serve(lars.HEAD, "/head")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Header().Get(lars.ContentLength), "2048")
+	Equal(t, w.Body.Len(), 0)
+}
+
+func TestGzipStream(t *testing.T) {
+
+	l := lars.New()
+	l.Use(Gzip)
+	l.Get("/stream", func(c lars.Context) {
+
+		i := 0
+
+		c.Stream(func(w io.Writer) bool {
+			w.Write([]byte("chunk"))
+			i++
+			return i < 3
+		})
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	req, _ := http.NewRequest(lars.GET, server.URL+"/stream", nil)
+	req.Header.Set(lars.AcceptEncoding, "gzip")
+
+	resp, err := (&http.Client{}).Do(req)
+	Equal(t, err, nil)
+	Equal(t, resp.StatusCode, http.StatusOK)
+	Equal(t, resp.Header.Get(lars.ContentEncoding), lars.Gzip)
+
+	r, err := gzip.NewReader(resp.Body)
+	Equal(t, err, nil)
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	Equal(t, err, nil)
+	Equal(t, string(b), "chunkchunkchunk")
+}
+
+func TestGzipAttachment(t *testing.T) {
+
+	l := lars.New()
+	l.Use(Gzip)
+	l.Get("/file", func(c lars.Context) {
+		c.Attachment(strings.NewReader(testBody), "test.txt")
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(lars.GET, "/file", nil)
+	r.Header.Set(lars.AcceptEncoding, "gzip")
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), lars.Gzip)
+	Equal(t, w.Header().Get(lars.ContentLength), "")
+	Equal(t, w.Header().Get(lars.ContentDisposition), "attachment;filename=test.txt")
+
+	gr, err := gzip.NewReader(w.Body)
+	Equal(t, err, nil)
+
+	b, err := ioutil.ReadAll(gr)
+	Equal(t, err, nil)
+	Equal(t, string(b), testBody)
+
+	// ranges apply to the identity encoding
+	r, _ = http.NewRequest(lars.GET, "/file", nil)
+	r.Header.Set(lars.AcceptEncoding, "gzip")
+	r.Header.Set("Range", "bytes=0-3")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Body.String(), "test")
+}
+
+func TestGzipWebSocket(t *testing.T) {
+
+	l := lars.New()
+	l.Use(Gzip)
+	l.Get("/ws", func(c lars.Context) error {
+		return c.Upgrade(websocket.Upgrader{}, func(conn *websocket.Conn) {
+
+			messageType, b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			conn.WriteMessage(messageType, b)
+		})
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	header := make(http.Header)
+	header.Set(lars.AcceptEncoding, "gzip")
+
+	ws, res, err := websocket.DefaultDialer.Dial("ws://"+server.Listener.Addr().String()+"/ws", header)
+	Equal(t, err, nil)
+	Equal(t, res.StatusCode, http.StatusSwitchingProtocols)
+	Equal(t, res.Header.Get(lars.ContentEncoding), "")
+
+	defer ws.Close()
+
+	Equal(t, ws.WriteMessage(websocket.TextMessage, []byte("upgraded")), nil)
+
+	typ, b, err := ws.ReadMessage()
+	Equal(t, err, nil)
+	Equal(t, typ, websocket.TextMessage)
+	Equal(t, string(b), "upgraded")
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-playground/lars"
+)
+
+// IPFilterConfig configures the IPFilter middleware, the lists contain CIDRs or single
+// IPs i.e. "10.0.0.0/8" or "192.168.1.10"
+type IPFilterConfig struct {
+	// Allow, when not empty, are the only networks requests are accepted from
+	Allow []string
+
+	// Deny are the networks requests are rejected from, taking precedence over Allow
+	Deny []string
+}
+
+// AllowIPs returns a middleware which only accepts requests from the networks,
+// rejecting all others with 403 Forbidden; useful for admin only route groups.
+func AllowIPs(networks ...string) lars.HandlerFunc {
+	return IPFilter(IPFilterConfig{Allow: networks})
+}
+
+// DenyIPs returns a middleware which rejects requests from the networks with
+// 403 Forbidden.
+func DenyIPs(networks ...string) lars.HandlerFunc {
+	return IPFilter(IPFilterConfig{Deny: networks})
+}
+
+// IPFilter returns a middleware which evaluates c.SecureClientIP() against the allow and
+// deny lists, rejecting requests with 403 Forbidden, including those whose IP can't be
+// determined; the client IP is only taken from the X-Forwarded-For and X-Real-IP headers
+// when sent by a proxy trusted using l.SetTrustedProxies, otherwise the peer address is
+// used as the headers can be spoofed to bypass the lists.
+// NOTE: panics if a network is not a valid CIDR or IP
+func IPFilter(config IPFilterConfig) lars.HandlerFunc {
+
+	allow := lars.ParseNetworks("ip filter: invalid network", config.Allow...)
+	deny := lars.ParseNetworks("ip filter: invalid network", config.Deny...)
+
+	return func(c lars.Context) {
+
+		ip := net.ParseIP(c.SecureClientIP())
+
+		if ip == nil || containsIP(deny, ip) || (len(allow) > 0 && !containsIP(allow, ip)) {
+			http.Error(c.Response(), http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
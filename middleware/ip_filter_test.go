@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestIPFilter(t *testing.T) {
+
+	l := lars.New()
+	l.SetTrustedProxies("10.0.0.1")
+
+	admin := l.Group("/admin", IPFilter(IPFilterConfig{
+		Allow: []string{"192.168.0.0/16", "2001:db8::/32"},
+		Deny:  []string{"192.168.1.10"},
+	}))
+	admin.Get("/", func(c lars.Context) {
+		c.Text(http.StatusOK, "ok")
+	})
+
+	l.Get("/public", DenyIPs("203.0.113.0/24"), func(c lars.Context) {
+		c.Text(http.StatusOK, "ok")
+	})
+
+	hf := l.Serve()
+
+	serve := func(path, remote, forwarded string) int {
+		r, _ := http.NewRequest(lars.GET, path, nil)
+		r.RemoteAddr = remote
+		if forwarded != "" {
+			r.Header.Set(lars.XForwardedFor, forwarded)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	Equal(t, serve("/admin/", "192.168.2.1:1234", ""), http.StatusOK)
+	Equal(t, serve("/admin/", "[2001:db8::1]:1234", ""), http.StatusOK)
+	Equal(t, serve("/admin/", "192.168.1.10:1234", ""), http.StatusForbidden)
+	Equal(t, serve("/admin/", "172.16.0.1:1234", ""), http.StatusForbidden)
+
+	// only trusted proxies can forward the client IP
+	Equal(t, serve("/admin/", "10.0.0.1:1234", "192.168.2.1"), http.StatusOK)
+	Equal(t, serve("/admin/", "10.0.0.1:1234", "172.16.0.1"), http.StatusForbidden)
+	Equal(t, serve("/admin/", "172.16.0.1:1234", "192.168.2.1"), http.StatusForbidden)
+
+	Equal(t, serve("/public", "198.51.100.1:1234", ""), http.StatusOK)
+	Equal(t, serve("/public", "203.0.113.5:1234", ""), http.StatusForbidden)
+
+	l = lars.New()
+	l.Get("/", AllowIPs("127.0.0.1"), func(c lars.Context) {})
+
+	hf = l.Serve()
+
+	Equal(t, serve("/", "127.0.0.1:1234", ""), http.StatusOK)
+	Equal(t, serve("/", "127.0.0.2:1234", ""), http.StatusForbidden)
+	Equal(t, serve("/", "invalid", ""), http.StatusForbidden)
+
+	// without trusted proxies the forwarding headers can't be used to spoof the client IP
+	l = lars.New()
+	l.Get("/admin", AllowIPs("10.0.0.0/8"), func(c lars.Context) {})
+
+	hf = l.Serve()
+
+	Equal(t, serve("/admin", "203.0.113.9:1234", "10.0.0.1"), http.StatusForbidden)
+	Equal(t, serve("/admin", "10.0.0.2:1234", "203.0.113.9"), http.StatusOK)
+
+	r, _ := http.NewRequest(lars.GET, "/admin", nil)
+	r.RemoteAddr = "203.0.113.9:1234"
+	r.Header.Set(lars.XRealIP, "10.0.0.1")
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusForbidden)
+
+	PanicMatches(t, func() { AllowIPs("bad") }, "ip filter: invalid network 'bad'")
+	PanicMatches(t, func() { DenyIPs("10.0.0.0/99") }, "ip filter: invalid network '10.0.0.0/99': invalid CIDR address: 10.0.0.0/99")
+}
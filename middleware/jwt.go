@@ -0,0 +1,390 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/lars"
+)
+
+// JWTClaimsKey is the key the validated token's JWTClaims are stored under using c.Set
+const JWTClaimsKey = "lars.jwt.claims"
+
+var (
+	errJWTMalformed = errors.New("jwt: malformed token")
+	errJWTAlgorithm = errors.New("jwt: unsupported algorithm")
+	errJWTKey       = errors.New("jwt: invalid key for algorithm")
+	errJWTSignature = errors.New("jwt: invalid signature")
+	errJWTExpired   = errors.New("jwt: token is expired")
+	errJWTNotValid  = errors.New("jwt: token is not valid yet")
+	errJWTIssuer    = errors.New("jwt: invalid issuer")
+	errJWTAudience  = errors.New("jwt: invalid audience")
+	errJWTClaims    = errors.New("jwt: invalid claims")
+)
+
+// JWTClaims are the claims of a validated JSON Web Token, numbers are float64's
+type JWTClaims map[string]interface{}
+
+// JWTHeader is the header of a JSON Web Token
+type JWTHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Type      string `json:"typ"`
+}
+
+// JWTKeyFunc returns the key used to verify the signature of the token with the
+// given header; a []byte for HS256/384/512, *rsa.PublicKey for RS256/384/512 and
+// *ecdsa.PublicKey for ES256/384/512. The key type must match the algorithm so a
+// token can't choose to be verified with a different kind of key.
+type JWTKeyFunc func(c lars.Context, header JWTHeader) (interface{}, error)
+
+// JWTConfig configures the JWT middleware
+type JWTConfig struct {
+	// KeyFunc looks up the key used to verify each token's signature, required
+	KeyFunc JWTKeyFunc
+
+	// Lookup lists where the token is extracted from, in order, as "header:<name>",
+	// "cookie:<name>" or "query:<name>"; from the Authorization header the Bearer
+	// scheme is required. default "header:Authorization"
+	Lookup []string
+
+	// Issuer, when set, must match the token's iss claim
+	Issuer string
+
+	// Audience, when set, must be one of the token's aud claim values
+	Audience string
+
+	// Leeway allows for clock skew when validating the exp and nbf claims
+	Leeway time.Duration
+
+	// Validate, when set, is called to perform any additional claims validation
+	Validate func(c lars.Context, claims JWTClaims) bool
+}
+
+type jwtExtractor func(c lars.Context) string
+
+// JWT returns a middleware which authenticates the request using a HS256/384/512
+// signed JSON Web Token, sent using the Bearer scheme, verified with key.
+func JWT(key []byte) lars.HandlerFunc {
+	return JWTWithConfig(JWTConfig{
+		KeyFunc: func(c lars.Context, header JWTHeader) (interface{}, error) {
+			return key, nil
+		},
+	})
+}
+
+// JWTWithConfig returns a middleware which authenticates the request using a JSON Web
+// Token validated according to config; the signature and the exp and nbf claims, when
+// present, are always validated. On success the claims are stored on the Context under
+// JWTClaimsKey and the chain continues, otherwise a 401 Unauthorized is returned along
+// with the WWW-Authenticate challenge.
+func JWTWithConfig(config JWTConfig) lars.HandlerFunc {
+
+	if config.KeyFunc == nil {
+		panic("jwt: KeyFunc is required")
+	}
+
+	lookup := config.Lookup
+	if len(lookup) == 0 {
+		lookup = []string{"header:" + lars.Authorization}
+	}
+
+	extractors := make([]jwtExtractor, len(lookup))
+
+	for i, l := range lookup {
+		extractors[i] = newJWTExtractor(l)
+	}
+
+	return func(c lars.Context) {
+
+		var token string
+
+		for _, extract := range extractors {
+			if token = extract(c); token != "" {
+				break
+			}
+		}
+
+		challenge := "Bearer"
+
+		if token != "" {
+
+			claims, err := config.validate(c, token)
+			if err == nil {
+				c.Set(JWTClaimsKey, claims)
+				c.Next()
+				return
+			}
+
+			challenge = `Bearer error="invalid_token"`
+		}
+
+		c.Response().Header().Set(lars.WWWAuthenticate, challenge)
+		http.Error(c.Response(), http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}
+}
+
+func newJWTExtractor(lookup string) jwtExtractor {
+
+	parts := strings.SplitN(lookup, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		panic("jwt: invalid lookup '" + lookup + "'")
+	}
+
+	name := parts[1]
+
+	switch parts[0] {
+	case "header":
+
+		if !strings.EqualFold(name, lars.Authorization) {
+			return func(c lars.Context) string {
+				return c.Request().Header.Get(name)
+			}
+		}
+
+		return func(c lars.Context) string {
+
+			auth := c.Request().Header.Get(lars.Authorization)
+
+			if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+				return strings.TrimSpace(auth[7:])
+			}
+
+			return ""
+		}
+
+	case "cookie":
+		return func(c lars.Context) string {
+
+			if cookie, err := c.Request().Cookie(name); err == nil {
+				return cookie.Value
+			}
+
+			return ""
+		}
+
+	case "query":
+		return func(c lars.Context) string {
+			return c.QueryParams().Get(name)
+		}
+	}
+
+	panic("jwt: invalid lookup '" + lookup + "'")
+}
+
+// validate parses the token, verifies it's signature and validates it's claims
+func (config *JWTConfig) validate(c lars.Context, token string) (JWTClaims, error) {
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errJWTMalformed
+	}
+
+	var header JWTHeader
+
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errJWTMalformed
+	}
+
+	key, err := config.KeyFunc(c, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = verifyJWT(header.Algorithm, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims JWTClaims
+
+	if err = decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if err = config.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	if config.Validate != nil && !config.Validate(c, claims) {
+		return nil, errJWTClaims
+	}
+
+	return claims, nil
+}
+
+func (config *JWTConfig) validateClaims(claims JWTClaims) error {
+
+	now := time.Now()
+
+	if v, ok := claims["exp"]; ok {
+
+		exp, ok := v.(float64)
+		if !ok {
+			return errJWTClaims
+		}
+
+		if !now.Add(-config.Leeway).Before(time.Unix(int64(exp), 0)) {
+			return errJWTExpired
+		}
+	}
+
+	if v, ok := claims["nbf"]; ok {
+
+		nbf, ok := v.(float64)
+		if !ok {
+			return errJWTClaims
+		}
+
+		if now.Add(config.Leeway).Before(time.Unix(int64(nbf), 0)) {
+			return errJWTNotValid
+		}
+	}
+
+	if config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != config.Issuer {
+			return errJWTIssuer
+		}
+	}
+
+	if config.Audience != "" {
+
+		switch aud := claims["aud"].(type) {
+		case string:
+			if aud == config.Audience {
+				return nil
+			}
+		case []interface{}:
+			for _, a := range aud {
+				if a == config.Audience {
+					return nil
+				}
+			}
+		}
+
+		return errJWTAudience
+	}
+
+	return nil
+}
+
+func decodeJWTSegment(seg string, v interface{}) error {
+
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errJWTMalformed
+	}
+
+	if err = json.Unmarshal(b, v); err != nil {
+		return errJWTMalformed
+	}
+
+	return nil
+}
+
+// verifyJWT verifies the signature of signed, the token's header and claims
+// segments, using the key which must be of the type the algorithm requires.
+func verifyJWT(alg string, key interface{}, signed string, sig []byte) error {
+
+	if len(alg) != 5 {
+		return errJWTAlgorithm
+	}
+
+	var h crypto.Hash
+
+	switch alg[2:] {
+	case "256":
+		h = crypto.SHA256
+	case "384":
+		h = crypto.SHA384
+	case "512":
+		h = crypto.SHA512
+	default:
+		return errJWTAlgorithm
+	}
+
+	switch alg[:2] {
+	case "HS":
+
+		k, ok := key.([]byte)
+		if !ok || len(k) == 0 {
+			return errJWTKey
+		}
+
+		mac := hmac.New(hashFunc(h), k)
+		mac.Write([]byte(signed))
+
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errJWTSignature
+		}
+
+	case "RS":
+
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errJWTKey
+		}
+
+		if rsa.VerifyPKCS1v15(k, h, digest(h, signed), sig) != nil {
+			return errJWTSignature
+		}
+
+	case "ES":
+
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errJWTKey
+		}
+
+		size := (k.Curve.Params().BitSize + 7) / 8
+
+		if len(sig) != 2*size {
+			return errJWTSignature
+		}
+
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+
+		if !ecdsa.Verify(k, digest(h, signed), r, s) {
+			return errJWTSignature
+		}
+
+	default:
+		return errJWTAlgorithm
+	}
+
+	return nil
+}
+
+func hashFunc(h crypto.Hash) func() hash.Hash {
+	switch h {
+	case crypto.SHA384:
+		return sha512.New384
+	case crypto.SHA512:
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+func digest(h crypto.Hash, signed string) []byte {
+	d := hashFunc(h)()
+	d.Write([]byte(signed))
+	return d.Sum(nil)
+}
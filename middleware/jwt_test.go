@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestJWT(t *testing.T) {
+
+	key := []byte("secret")
+
+	l := lars.New()
+	l.Use(JWT(key))
+	l.Get("/test", func(c lars.Context) {
+		claims, _ := c.Get(JWTClaimsKey)
+		c.Text(http.StatusOK, claims.(JWTClaims)["sub"].(string))
+	})
+
+	hf := l.Serve()
+
+	serve := func(auth string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(lars.GET, "/test", nil)
+		if auth != "" {
+			r.Header.Set(lars.Authorization, auth)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	now := time.Now().Unix()
+
+	w := serve("Bearer " + signHS256(t, key, JWTClaims{"sub": "joeybloggs", "exp": now + 60}))
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "joeybloggs")
+
+	w = serve("bearer " + signHS256(t, key, JWTClaims{"sub": "joeybloggs"}))
+	Equal(t, w.Code, http.StatusOK)
+
+	w = serve("")
+	Equal(t, w.Code, http.StatusUnauthorized)
+	Equal(t, w.Header().Get(lars.WWWAuthenticate), "Bearer")
+
+	w = serve("Basic am9leWJsb2dnczpzZWNyZXQ=")
+	Equal(t, w.Code, http.StatusUnauthorized)
+	Equal(t, w.Header().Get(lars.WWWAuthenticate), "Bearer")
+
+	tests := []string{
+		"Bearer " + signHS256(t, []byte("wrong"), JWTClaims{"sub": "joeybloggs"}),
+		"Bearer " + signHS256(t, key, JWTClaims{"sub": "joeybloggs", "exp": now - 60}),
+		"Bearer " + signHS256(t, key, JWTClaims{"sub": "joeybloggs", "nbf": now + 60}),
+		"Bearer " + signHS256(t, key, JWTClaims{"sub": "joeybloggs", "exp": "tomorrow"}),
+		"Bearer " + encodeJWT(t, map[string]string{"alg": "none"}, JWTClaims{"sub": "joeybloggs"}) + ".",
+		"Bearer not.a.token",
+		"Bearer token",
+	}
+
+	for i, auth := range tests {
+
+		w = serve(auth)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("test %d: expected 401 got %d", i, w.Code)
+		}
+
+		Equal(t, w.Header().Get(lars.WWWAuthenticate), `Bearer error="invalid_token"`)
+	}
+}
+
+func TestJWTWithConfig(t *testing.T) {
+
+	hsKey := []byte("secret")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Equal(t, err, nil)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Equal(t, err, nil)
+
+	l := lars.New()
+	l.Use(JWTWithConfig(JWTConfig{
+		KeyFunc: func(c lars.Context, header JWTHeader) (interface{}, error) {
+			switch header.KeyID {
+			case "hs":
+				return hsKey, nil
+			case "rs":
+				return &rsaKey.PublicKey, nil
+			case "es":
+				return &ecKey.PublicKey, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+		Lookup:   []string{"header:X-Token", "cookie:token", "query:token"},
+		Issuer:   "lars",
+		Audience: "api",
+		Leeway:   time.Minute,
+		Validate: func(c lars.Context, claims JWTClaims) bool {
+			return claims["admin"] == true
+		},
+	}))
+	l.Get("/test", func(c lars.Context) {
+		claims, _ := c.Get(JWTClaimsKey)
+		c.Text(http.StatusOK, claims.(JWTClaims)["sub"].(string))
+	})
+
+	hf := l.Serve()
+
+	serve := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	now := time.Now().Unix()
+	claims := JWTClaims{"sub": "joeybloggs", "iss": "lars", "aud": []string{"web", "api"}, "admin": true, "exp": now - 30}
+
+	hs := signHS256(t, hsKey, claims, "hs")
+
+	r, _ := http.NewRequest(lars.GET, "/test", nil)
+	r.Header.Set("X-Token", hs)
+	Equal(t, serve(r), http.StatusOK)
+
+	r, _ = http.NewRequest(lars.GET, "/test", nil)
+	r.AddCookie(&http.Cookie{Name: "token", Value: hs})
+	Equal(t, serve(r), http.StatusOK)
+
+	r, _ = http.NewRequest(lars.GET, "/test?token="+hs, nil)
+	Equal(t, serve(r), http.StatusOK)
+
+	// the Authorization header isn't looked up
+	r, _ = http.NewRequest(lars.GET, "/test", nil)
+	r.Header.Set(lars.Authorization, "Bearer "+hs)
+	Equal(t, serve(r), http.StatusUnauthorized)
+
+	signed := encodeJWT(t, map[string]string{"alg": "RS256", "kid": "rs"}, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	Equal(t, err, nil)
+
+	r, _ = http.NewRequest(lars.GET, "/test?token="+signed+"."+base64.RawURLEncoding.EncodeToString(sig), nil)
+	Equal(t, serve(r), http.StatusOK)
+
+	signed = encodeJWT(t, map[string]string{"alg": "ES256", "kid": "es"}, claims)
+	digest = sha256.Sum256([]byte(signed))
+	rs, ss, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+	Equal(t, err, nil)
+
+	sig = make([]byte, 64)
+	rs.FillBytes(sig[:32])
+	ss.FillBytes(sig[32:])
+
+	r, _ = http.NewRequest(lars.GET, "/test?token="+signed+"."+base64.RawURLEncoding.EncodeToString(sig), nil)
+	Equal(t, serve(r), http.StatusOK)
+
+	// key type must match the algorithm, here the HMAC is keyed with the RSA key id
+	r, _ = http.NewRequest(lars.GET, "/test?token="+signHS256(t, hsKey, claims, "rs"), nil)
+	Equal(t, serve(r), http.StatusUnauthorized)
+
+	invalid := []JWTClaims{
+		{"sub": "joeybloggs", "iss": "other", "aud": "api", "admin": true},
+		{"sub": "joeybloggs", "iss": "lars", "aud": "web", "admin": true},
+		{"sub": "joeybloggs", "iss": "lars", "aud": "api"},
+		{"sub": "joeybloggs", "iss": "lars", "aud": "api", "admin": true, "exp": now - 120},
+		{"sub": "joeybloggs", "iss": "lars", "aud": "api", "admin": true, "nbf": now + 120},
+	}
+
+	for i, c := range invalid {
+
+		r, _ = http.NewRequest(lars.GET, "/test?token="+signHS256(t, hsKey, c, "hs"), nil)
+
+		if code := serve(r); code != http.StatusUnauthorized {
+			t.Errorf("test %d: expected 401 got %d", i, code)
+		}
+	}
+
+	r, _ = http.NewRequest(lars.GET, "/test?token="+signHS256(t, hsKey, JWTClaims{"sub": "joeybloggs", "iss": "lars", "aud": "api", "admin": true}, "unknown"), nil)
+	Equal(t, serve(r), http.StatusUnauthorized)
+
+	PanicMatches(t, func() { JWTWithConfig(JWTConfig{}) }, "jwt: KeyFunc is required")
+	PanicMatches(t, func() {
+		JWTWithConfig(JWTConfig{KeyFunc: func(lars.Context, JWTHeader) (interface{}, error) { return nil, nil }, Lookup: []string{"body:token"}})
+	}, "jwt: invalid lookup 'body:token'")
+	PanicMatches(t, func() {
+		JWTWithConfig(JWTConfig{KeyFunc: func(lars.Context, JWTHeader) (interface{}, error) { return nil, nil }, Lookup: []string{"header"}})
+	}, "jwt: invalid lookup 'header'")
+}
+
+func encodeJWT(t *testing.T, header map[string]string, claims JWTClaims) string {
+
+	h, err := json.Marshal(header)
+	Equal(t, err, nil)
+
+	c, err := json.Marshal(claims)
+	Equal(t, err, nil)
+
+	return base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+}
+
+func signHS256(t *testing.T, key []byte, claims JWTClaims, kid ...string) string {
+
+	header := map[string]string{"alg": "HS256", "typ": "JWT"}
+	if len(kid) > 0 {
+		header["kid"] = kid[0]
+	}
+
+	signed := encodeJWT(t, header, claims)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
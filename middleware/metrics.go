@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"bufio"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-playground/lars"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the request duration
+// histogram's buckets
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the upper bounds, in bytes, of the response size histogram's
+// buckets
+var DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+
+// MetricsConfig configures the metrics collected by Metrics
+type MetricsConfig struct {
+	// Namespace prefixes the metric names i.e. "lars" for lars_http_requests_total.
+	// default "lars"
+	Namespace string
+
+	// DurationBuckets are the request duration histogram's buckets in seconds.
+	// default DefaultDurationBuckets
+	DurationBuckets []float64
+
+	// SizeBuckets are the response size histogram's buckets in bytes.
+	// default DefaultSizeBuckets
+	SizeBuckets []float64
+}
+
+// metricMethods are the methods unmatched requests are labeled with, any other is
+// labeled "other" as clients can send arbitrary methods
+var metricMethods = map[string]struct{}{
+	lars.CONNECT: {},
+	lars.DELETE:  {},
+	lars.GET:     {},
+	lars.HEAD:    {},
+	lars.OPTIONS: {},
+	lars.PATCH:   {},
+	lars.POST:    {},
+	lars.PUT:     {},
+	lars.TRACE:   {},
+}
+
+// metricLabels are the labels requests are counted by, the method and route are the
+// matched route's so the number of series is bounded
+type metricLabels struct {
+	method string
+	route  string
+	status string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+
+	h.sum += v
+
+	for i, b := range buckets {
+		if v <= b {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+type metricSeries struct {
+	count    uint64
+	duration histogram
+	size     histogram
+}
+
+// Metrics collects Prometheus metrics of the requests it's Middleware is run for; the
+// request count, duration and response size labeled by method, matched route pattern
+// and status class along with the number of requests in flight. It's an http.Handler
+// serving the metrics in the Prometheus text exposition format.
+//
+// i.e.
+//
+//	m := middleware.NewMetrics(middleware.MetricsConfig{})
+//	l.Use(m.Middleware())
+//	m.Mount(l, "/metrics")
+type Metrics struct {
+	config   MetricsConfig
+	inFlight int64
+	mu       sync.Mutex
+	series   map[metricLabels]*metricSeries
+	now      func() time.Time
+}
+
+var _ http.Handler = new(Metrics)
+
+// NewMetrics returns a new Metrics collector
+func NewMetrics(config MetricsConfig) *Metrics {
+
+	if config.Namespace == "" {
+		config.Namespace = "lars"
+	}
+
+	if len(config.DurationBuckets) == 0 {
+		config.DurationBuckets = DefaultDurationBuckets
+	}
+
+	if len(config.SizeBuckets) == 0 {
+		config.SizeBuckets = DefaultSizeBuckets
+	}
+
+	return &Metrics{
+		config: config,
+		series: make(map[metricLabels]*metricSeries),
+		now:    time.Now,
+	}
+}
+
+// Middleware returns the middleware collecting the metrics of each request, requests
+// not matching a route are labeled with the route "unmatched" and, unless a standard
+// method, the method "other".
+func (m *Metrics) Middleware() lars.HandlerFunc {
+
+	return func(c lars.Context) {
+
+		start := m.now()
+
+		atomic.AddInt64(&m.inFlight, 1)
+		defer atomic.AddInt64(&m.inFlight, -1)
+
+		c.Next()
+
+		res := c.Response()
+
+		status := res.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := metricLabels{
+			method: "other",
+			route:  "unmatched",
+			status: strconv.Itoa(status/100) + "xx",
+		}
+
+		if route := c.Route(); route != nil {
+			labels.method = route.Method()
+			labels.route = route.Path()
+		} else if _, ok := metricMethods[c.Request().Method]; ok {
+			labels.method = c.Request().Method
+		}
+
+		m.observe(labels, m.now().Sub(start).Seconds(), float64(res.Size()))
+	}
+}
+
+func (m *Metrics) observe(labels metricLabels, duration float64, size float64) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.series[labels]
+	if !ok {
+		s = &metricSeries{
+			duration: histogram{counts: make([]uint64, len(m.config.DurationBuckets))},
+			size:     histogram{counts: make([]uint64, len(m.config.SizeBuckets))},
+		}
+		m.series[labels] = s
+	}
+
+	s.count++
+	s.duration.observe(m.config.DurationBuckets, duration)
+	s.size.observe(m.config.SizeBuckets, size)
+}
+
+// Mount registers a GET route at path, i.e. "/metrics", serving the metrics; the route
+// is flagged Silent so scrapes aren't access logged.
+func (m *Metrics) Mount(r lars.IRoutes, path string) *lars.Route {
+	return r.Get(path, m).Silent()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set(lars.ContentType, metricsContentType)
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	name := m.config.Namespace + "_http_"
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := make([]metricLabels, 0, len(m.series))
+	for l := range m.series {
+		labels = append(labels, l)
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+
+		if labels[i].route != labels[j].route {
+			return labels[i].route < labels[j].route
+		}
+
+		if labels[i].method != labels[j].method {
+			return labels[i].method < labels[j].method
+		}
+
+		return labels[i].status < labels[j].status
+	})
+
+	writeMetricHeader(bw, name+"requests_total", "counter", "Total number of HTTP requests.")
+
+	for _, l := range labels {
+		bw.WriteString(name + "requests_total" + l.String("", "") + " " + strconv.FormatUint(m.series[l].count, 10) + "\n")
+	}
+
+	writeMetricHeader(bw, name+"request_duration_seconds", "histogram", "Duration of HTTP requests in seconds.")
+
+	for _, l := range labels {
+		writeHistogram(bw, name+"request_duration_seconds", l, m.config.DurationBuckets, &m.series[l].duration, m.series[l].count)
+	}
+
+	writeMetricHeader(bw, name+"response_size_bytes", "histogram", "Size of HTTP responses in bytes.")
+
+	for _, l := range labels {
+		writeHistogram(bw, name+"response_size_bytes", l, m.config.SizeBuckets, &m.series[l].size, m.series[l].count)
+	}
+
+	writeMetricHeader(bw, name+"requests_in_flight", "gauge", "Number of HTTP requests currently being handled.")
+	bw.WriteString(name + "requests_in_flight " + strconv.FormatInt(atomic.LoadInt64(&m.inFlight), 10) + "\n")
+}
+
+// String returns the labels in the exposition format, followed by the extra label when
+// not blank i.e. the le label of a histogram bucket
+func (l metricLabels) String(extra, value string) string {
+
+	s := `{method="` + escapeLabel(l.method) + `",route="` + escapeLabel(l.route) + `",status="` + l.status + `"`
+
+	if extra != "" {
+		s += `,` + extra + `="` + value + `"`
+	}
+
+	return s + "}"
+}
+
+func writeMetricHeader(w *bufio.Writer, name, typ, help string) {
+	w.WriteString("# HELP " + name + " " + help + "\n# TYPE " + name + " " + typ + "\n")
+}
+
+func writeHistogram(w *bufio.Writer, name string, l metricLabels, buckets []float64, h *histogram, count uint64) {
+
+	var cumulative uint64
+
+	for i, b := range buckets {
+		cumulative += h.counts[i]
+		w.WriteString(name + "_bucket" + l.String("le", strconv.FormatFloat(b, 'g', -1, 64)) + " " + strconv.FormatUint(cumulative, 10) + "\n")
+	}
+
+	w.WriteString(name + "_bucket" + l.String("le", "+Inf") + " " + strconv.FormatUint(count, 10) + "\n")
+	w.WriteString(name + "_sum" + l.String("", "") + " " + strconv.FormatFloat(h.sum, 'g', -1, 64) + "\n")
+	w.WriteString(name + "_count" + l.String("", "") + " " + strconv.FormatUint(count, 10) + "\n")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
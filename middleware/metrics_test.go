@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestMetrics(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	m := NewMetrics(MetricsConfig{DurationBuckets: []float64{0.1, 1}, SizeBuckets: []float64{10}})
+	m.now = func() time.Time {
+		now = now.Add(time.Millisecond * 250)
+		return now
+	}
+
+	l := lars.New()
+	l.Use(m.Middleware())
+	l.Get("/users/:id", func(c lars.Context) {
+		c.Text(http.StatusOK, "user "+c.Param("id"))
+	})
+	l.Post("/users", func(c lars.Context) {
+		c.Response().WriteHeader(http.StatusCreated)
+	})
+	m.Mount(l, "/metrics")
+
+	hf := l.Serve()
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	serve(lars.GET, "/users/1")
+	serve(lars.GET, "/users/2")
+	serve(lars.POST, "/users")
+	serve(lars.GET, "/missing")
+
+	w := serve(lars.GET, "/metrics")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentType), "text/plain; version=0.0.4; charset=utf-8")
+
+	expected := `# HELP lars_http_requests_total Total number of HTTP requests.
+# TYPE lars_http_requests_total counter
+lars_http_requests_total{method="POST",route="/users",status="2xx"} 1
+lars_http_requests_total{method="GET",route="/users/:id",status="2xx"} 2
+lars_http_requests_total{method="GET",route="unmatched",status="4xx"} 1
+# HELP lars_http_request_duration_seconds Duration of HTTP requests in seconds.
+# TYPE lars_http_request_duration_seconds histogram
+lars_http_request_duration_seconds_bucket{method="POST",route="/users",status="2xx",le="0.1"} 0
+lars_http_request_duration_seconds_bucket{method="POST",route="/users",status="2xx",le="1"} 1
+lars_http_request_duration_seconds_bucket{method="POST",route="/users",status="2xx",le="+Inf"} 1
+lars_http_request_duration_seconds_sum{method="POST",route="/users",status="2xx"} 0.25
+lars_http_request_duration_seconds_count{method="POST",route="/users",status="2xx"} 1
+lars_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="0.1"} 0
+lars_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="1"} 2
+lars_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="+Inf"} 2
+lars_http_request_duration_seconds_sum{method="GET",route="/users/:id",status="2xx"} 0.5
+lars_http_request_duration_seconds_count{method="GET",route="/users/:id",status="2xx"} 2
+`
+	Equal(t, w.Body.String()[:len(expected)], expected)
+
+	Equal(t, strings.Contains(w.Body.String(), `lars_http_response_size_bytes_bucket{method="GET",route="/users/:id",status="2xx",le="10"} 2`), true)
+	Equal(t, strings.Contains(w.Body.String(), `lars_http_response_size_bytes_sum{method="POST",route="/users",status="2xx"} 0`), true)
+	Equal(t, strings.Contains(w.Body.String(), "lars_http_requests_in_flight 1\n"), true)
+
+	// the scrape itself is counted
+	w = serve(lars.GET, "/metrics")
+	Equal(t, strings.Contains(w.Body.String(), `lars_http_requests_total{method="GET",route="/metrics",status="2xx"} 1`), true)
+
+	// arbitrary methods don't create new series
+	serve("FOO1", "/missing")
+	serve("FOO2", "/users/1")
+	serve(lars.DELETE, "/missing")
+
+	w = serve(lars.GET, "/metrics")
+	Equal(t, strings.Contains(w.Body.String(), `lars_http_requests_total{method="other",route="unmatched",status="4xx"} 2`), true)
+	Equal(t, strings.Contains(w.Body.String(), `lars_http_requests_total{method="DELETE",route="unmatched",status="4xx"} 1`), true)
+	Equal(t, strings.Contains(w.Body.String(), "FOO"), false)
+
+	Equal(t, escapeLabel("a\"b\\c\nd"), `a\"b\\c\nd`)
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-playground/lars"
+)
+
+// RateLimitKeyFunc returns the key requests are rate limited by i.e. an API key
+type RateLimitKeyFunc func(c lars.Context) string
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	// Allowed is whether a token was available and the request may proceed
+	Allowed bool
+
+	// Limit is the bucket's capacity, the maximum burst of requests
+	Limit int
+
+	// Remaining is the number of tokens left in the bucket
+	Remaining int
+
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+
+	// RetryAfter is the time until the next token is available when not allowed
+	RetryAfter time.Duration
+}
+
+// RateLimitStore stores the token buckets of the rate limited keys
+type RateLimitStore interface {
+	Take(key string) RateLimitResult
+}
+
+// RateLimitConfig configures the RateLimit middleware
+type RateLimitConfig struct {
+	// Store holds the token buckets, required
+	Store RateLimitStore
+
+	// KeyFunc returns the key requests are rate limited by. default c.SecureClientIP(),
+	// the peer address unless trusted proxies are configured using l.SetTrustedProxies
+	// as the forwarding headers could be rotated to get a new bucket every request
+	KeyFunc RateLimitKeyFunc
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// defaultRateLimitMaxIdle is the default time after which unused buckets are evicted
+const defaultRateLimitMaxIdle = 10 * time.Minute
+
+// MemoryRateLimitStore is an in-memory token bucket RateLimitStore, buckets which
+// have refilled completely are evicted as they're equivalent to a new bucket, as are
+// those unused for longer than the max idle time.
+type MemoryRateLimitStore struct {
+	burst     int
+	refill    time.Duration
+	maxIdle   time.Duration
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+var _ RateLimitStore = new(MemoryRateLimitStore)
+
+// NewMemoryRateLimitStore returns a new in-memory token bucket store whose buckets
+// hold up to burst tokens and are refilled by one token every refill interval.
+//
+// i.e. NewMemoryRateLimitStore(10, time.Second) allows bursts of 10 requests and a
+// sustained 1 request per second.
+func NewMemoryRateLimitStore(burst int, refill time.Duration) *MemoryRateLimitStore {
+
+	if burst < 1 || refill <= 0 {
+		panic("rate limit: burst and refill must be greater than zero")
+	}
+
+	return &MemoryRateLimitStore{
+		burst:   burst,
+		refill:  refill,
+		maxIdle: defaultRateLimitMaxIdle,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetMaxIdle sets the time after which unused buckets are evicted even when they haven't
+// refilled completely, bounding the memory used by clients cycling through keys; the key
+// starts over with a full bucket. default 10 minutes
+func (s *MemoryRateLimitStore) SetMaxIdle(d time.Duration) {
+
+	s.mu.Lock()
+	s.maxIdle = d
+	s.mu.Unlock()
+}
+
+// Take takes a token from the key's bucket
+func (s *MemoryRateLimitStore) Take(key string) RateLimitResult {
+
+	now := s.now()
+	burst := float64(s.burst)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+float64(now.Sub(b.last))/float64(s.refill))
+	b.last = now
+
+	res := RateLimitResult{Limit: s.burst}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * float64(s.refill))
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((burst - b.tokens) * float64(s.refill))
+
+	return res
+}
+
+// sweep evicts the buckets which have refilled completely or have been idle for longer
+// than the max idle time, at most once per the shorter of the two.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+
+	interval := time.Duration(s.burst) * s.refill
+	if s.maxIdle > 0 && s.maxIdle < interval {
+		interval = s.maxIdle
+	}
+
+	if now.Sub(s.lastSweep) < interval {
+		return
+	}
+
+	s.lastSweep = now
+	burst := float64(s.burst)
+
+	for k, b := range s.buckets {
+
+		idle := now.Sub(b.last)
+
+		if b.tokens+float64(idle)/float64(s.refill) >= burst || (s.maxIdle > 0 && idle >= s.maxIdle) {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+// RateLimit returns a middleware which rate limits requests, by c.SecureClientIP(), using
+// an in-memory token bucket allowing bursts of up to burst requests refilled by one
+// request every refill interval.
+func RateLimit(burst int, refill time.Duration) lars.HandlerFunc {
+	return RateLimitWithConfig(RateLimitConfig{
+		Store: NewMemoryRateLimitStore(burst, refill),
+	})
+}
+
+// RateLimitWithConfig returns a middleware which rate limits requests according to
+// config. The X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset, in
+// seconds, headers are set on every response; requests exceeding the limit are
+// answered with 429 Too Many Requests along with the Retry-After header.
+func RateLimitWithConfig(config RateLimitConfig) lars.HandlerFunc {
+
+	if config.Store == nil {
+		panic("rate limit: Store is required")
+	}
+
+	keyFunc := config.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(c lars.Context) string {
+			return c.SecureClientIP()
+		}
+	}
+
+	return func(c lars.Context) {
+
+		res := config.Store.Take(keyFunc(c))
+
+		h := c.Response().Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("X-RateLimit-Reset", seconds(res.Reset))
+
+		if res.Allowed {
+			c.Next()
+			return
+		}
+
+		h.Set("Retry-After", seconds(res.RetryAfter))
+		http.Error(c.Response(), http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	}
+}
+
+// seconds returns d in whole seconds, rounded up
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
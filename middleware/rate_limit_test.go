@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestRateLimit(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	store := NewMemoryRateLimitStore(2, time.Second*10)
+	store.now = func() time.Time { return now }
+
+	l := lars.New()
+	l.Use(RateLimitWithConfig(RateLimitConfig{Store: store}))
+	l.Get("/test", func(c lars.Context) {
+		c.Text(http.StatusOK, "ok")
+	})
+
+	hf := l.Serve()
+
+	serve := func(ip string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(lars.GET, "/test", nil)
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("10.0.0.1")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("X-RateLimit-Limit"), "2")
+	Equal(t, w.Header().Get("X-RateLimit-Remaining"), "1")
+	Equal(t, w.Header().Get("X-RateLimit-Reset"), "10")
+	Equal(t, w.Header().Get("Retry-After"), "")
+
+	w = serve("10.0.0.1")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("X-RateLimit-Remaining"), "0")
+	Equal(t, w.Header().Get("X-RateLimit-Reset"), "20")
+
+	w = serve("10.0.0.1")
+	Equal(t, w.Code, http.StatusTooManyRequests)
+	Equal(t, w.Header().Get("X-RateLimit-Remaining"), "0")
+	Equal(t, w.Header().Get("Retry-After"), "10")
+
+	// other clients have their own bucket
+	w = serve("10.0.0.2")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("X-RateLimit-Remaining"), "1")
+
+	now = now.Add(time.Second * 4)
+
+	w = serve("10.0.0.1")
+	Equal(t, w.Code, http.StatusTooManyRequests)
+	Equal(t, w.Header().Get("Retry-After"), "6")
+
+	now = now.Add(time.Second * 6)
+
+	w = serve("10.0.0.1")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("X-RateLimit-Remaining"), "0")
+
+	// refills up to the burst only
+	now = now.Add(time.Hour)
+
+	w = serve("10.0.0.1")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("X-RateLimit-Remaining"), "1")
+
+	// spoofed forwarding headers don't get a new bucket without trusted proxies
+	serve("10.0.0.3")
+	serve("10.0.0.3")
+
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2"} {
+		r, _ := http.NewRequest(lars.GET, "/test", nil)
+		r.RemoteAddr = "10.0.0.3:1234"
+		r.Header.Set(lars.XRealIP, ip)
+		r.Header.Set(lars.XForwardedFor, ip)
+		w = httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		Equal(t, w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimitKeyFunc(t *testing.T) {
+
+	l := lars.New()
+	l.Use(RateLimitWithConfig(RateLimitConfig{
+		Store: NewMemoryRateLimitStore(1, time.Hour),
+		KeyFunc: func(c lars.Context) string {
+			return c.Request().Header.Get("X-API-Key")
+		},
+	}))
+	l.Get("/test", func(c lars.Context) {
+		c.Text(http.StatusOK, "ok")
+	})
+
+	hf := l.Serve()
+
+	serve := func(key string) int {
+		r, _ := http.NewRequest(lars.GET, "/test", nil)
+		r.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	Equal(t, serve("a"), http.StatusOK)
+	Equal(t, serve("a"), http.StatusTooManyRequests)
+	Equal(t, serve("b"), http.StatusOK)
+	Equal(t, serve("b"), http.StatusTooManyRequests)
+}
+
+func TestRateLimitEviction(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	store := NewMemoryRateLimitStore(2, time.Second)
+	store.now = func() time.Time { return now }
+
+	store.Take("a")
+	store.Take("b")
+	store.Take("b")
+	Equal(t, len(store.buckets), 2)
+
+	// both have refilled and are evicted, "b" gets a new bucket
+	now = now.Add(time.Second * 2)
+	store.Take("b")
+	store.Take("b")
+	Equal(t, len(store.buckets), 1)
+
+	// not swept again until an empty bucket could have refilled
+	now = now.Add(time.Second)
+	store.Take("c")
+	Equal(t, len(store.buckets), 2)
+
+	now = now.Add(time.Second * 10)
+	res := store.Take("d")
+	Equal(t, res.Allowed, true)
+	Equal(t, len(store.buckets), 1)
+
+	// idle buckets are evicted before they've refilled
+	store = NewMemoryRateLimitStore(10, time.Hour)
+	store.now = func() time.Time { return now }
+	store.SetMaxIdle(time.Minute)
+
+	store.Take("a")
+	store.Take("b")
+	Equal(t, len(store.buckets), 2)
+
+	now = now.Add(time.Second * 30)
+	store.Take("b")
+	Equal(t, len(store.buckets), 2)
+
+	now = now.Add(time.Second * 40)
+	store.Take("c")
+	Equal(t, len(store.buckets), 2)
+
+	_, ok := store.buckets["a"]
+	Equal(t, ok, false)
+}
+
+func TestRateLimitPanics(t *testing.T) {
+	PanicMatches(t, func() { NewMemoryRateLimitStore(0, time.Second) }, "rate limit: burst and refill must be greater than zero")
+	PanicMatches(t, func() { NewMemoryRateLimitStore(1, 0) }, "rate limit: burst and refill must be greater than zero")
+	PanicMatches(t, func() { RateLimitWithConfig(RateLimitConfig{}) }, "rate limit: Store is required")
+	NotEqual(t, RateLimit(10, time.Second), nil)
+}
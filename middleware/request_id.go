@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-playground/lars"
+)
+
+// maxRequestIDLength is the maximum length of a request ID accepted from the client
+const maxRequestIDLength = 128
+
+// crockford is the Crockford base32 alphabet ULIDs are encoded with
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// RequestIDGenerator returns a new, unique, request ID
+type RequestIDGenerator func() string
+
+// RequestIDConfig configures the RequestID middleware
+type RequestIDConfig struct {
+	// Header is the request and response header the ID is read from and set on.
+	// default lars.XRequestID
+	Header string
+
+	// Generator generates the IDs of requests without one. default UUID
+	Generator RequestIDGenerator
+}
+
+// UUID generates a random, version 4, UUID i.e. "7b0a6a0e-52d4-4f5e-9f3b-2c0b3f8a1d6e"
+func UUID() string {
+
+	var b [16]byte
+	rand.Read(b[:])
+
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+
+	var s [36]byte
+
+	hex.Encode(s[:8], b[:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+
+	return string(s[:])
+}
+
+// ULID generates a ULID, a 48 bit millisecond timestamp followed by 80 random bits
+// encoded using Crockford's base32, so IDs sort by the time they were generated
+// i.e. "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+func ULID() string {
+	return ulid(time.Now())
+}
+
+func ulid(t time.Time) string {
+
+	var b [16]byte
+
+	binary.BigEndian.PutUint64(b[:8], uint64(t.UnixNano()/int64(time.Millisecond))<<16)
+	rand.Read(b[6:])
+
+	// 128 bits as 26 5 bit characters, the first holding only 3 bits
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	var s [26]byte
+
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(s[:])
+}
+
+// RequestID returns a middleware which sets the request's ID, available using
+// c.RequestID(), to the X-Request-ID header sent by the client, or a generated UUID
+// when there's none, and sets it on the response so logs across services correlate.
+func RequestID() lars.HandlerFunc {
+	return RequestIDWithConfig(RequestIDConfig{})
+}
+
+// RequestIDWithConfig returns a RequestID middleware using the config; IDs sent by
+// the client longer than 128 characters or containing non printable ASCII characters
+// are replaced so they can't be used to forge log entries.
+func RequestIDWithConfig(config RequestIDConfig) lars.HandlerFunc {
+
+	header := config.Header
+	if header == "" {
+		header = lars.XRequestID
+	}
+
+	generate := config.Generator
+	if generate == nil {
+		generate = UUID
+	}
+
+	return func(c lars.Context) {
+
+		id := c.Request().Header.Get(header)
+
+		if !validRequestID(id) {
+			id = generate()
+		}
+
+		c.SetRequestID(id)
+		c.Response().Header().Set(header, id)
+
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
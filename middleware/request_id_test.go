@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestRequestID(t *testing.T) {
+
+	l := lars.New()
+	l.Use(RequestID())
+	l.Get("/", func(c lars.Context) {
+		c.Text(http.StatusOK, c.RequestID())
+	})
+
+	hf := l.Serve()
+
+	serve := func(id string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(lars.GET, "/", nil)
+		if id != "" {
+			r.Header.Set(lars.XRequestID, id)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("")
+	Equal(t, w.Code, http.StatusOK)
+	MatchRegex(t, w.Body.String(), "^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+	Equal(t, w.Header().Get(lars.XRequestID), w.Body.String())
+
+	Equal(t, serve("").Body.String() != w.Body.String(), true)
+
+	w = serve("upstream-id-1")
+	Equal(t, w.Body.String(), "upstream-id-1")
+	Equal(t, w.Header().Get(lars.XRequestID), "upstream-id-1")
+
+	// forged log lines and oversized IDs are replaced
+	Equal(t, serve("id\nforged").Body.String() != "id\nforged", true)
+	Equal(t, len(serve(strings.Repeat("a", 129)).Body.String()), 36)
+	Equal(t, serve(strings.Repeat("a", 128)).Body.String(), strings.Repeat("a", 128))
+
+	l = lars.New()
+	l.Use(RequestIDWithConfig(RequestIDConfig{
+		Header:    "X-Correlation-ID",
+		Generator: func() string { return "custom" },
+	}))
+	l.Get("/", func(c lars.Context) {
+		c.Text(http.StatusOK, c.RequestID())
+	})
+
+	r, _ := http.NewRequest(lars.GET, "/", nil)
+	r.Header.Set(lars.XRequestID, "ignored")
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Body.String(), "custom")
+	Equal(t, w.Header().Get("X-Correlation-ID"), "custom")
+}
+
+func TestULID(t *testing.T) {
+
+	id := ulid(time.Unix(0, 1469918176385*int64(time.Millisecond)))
+	Equal(t, len(id), 26)
+	Equal(t, id[:10], "01ARYZ6S41")
+	MatchRegex(t, id, "^[0-9A-HJKMNP-TV-Z]{26}$")
+
+	// sorted by the time they were generated
+	now := time.Now()
+	Equal(t, ulid(now) < ulid(now.Add(time.Millisecond)), true)
+	Equal(t, len(ULID()), 26)
+}
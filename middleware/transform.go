@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/go-playground/lars"
+)
+
+// Transformer transforms a captured response body before it's written to the client
+// i.e. minifying HTML, injecting a CSP nonce or rewriting URLs for a CDN.
+type Transformer interface {
+	Transform(c lars.Context, body []byte) ([]byte, error)
+}
+
+// TransformerFunc is an adapter allowing an ordinary function to be used as a Transformer.
+type TransformerFunc func(c lars.Context, body []byte) ([]byte, error)
+
+// Transform calls f(c, body)
+func (f TransformerFunc) Transform(c lars.Context, body []byte) ([]byte, error) {
+	return f(c, body)
+}
+
+type transformWriter struct {
+	http.ResponseWriter
+	buf    *bytes.Buffer
+	status int
+}
+
+func (w *transformWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *transformWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+// Flush is a no-op, the body is captured until the handler chain returns.
+func (w *transformWriter) Flush() {
+}
+
+func (w *transformWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *transformWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// Transform returns a middleware which captures the response body and runs it through
+// the provided transformers, in order, before writing it. If a transformer returns an
+// error a 500 Internal Server Error is written instead.
+//
+// NOTE: register Transform after any compression middleware, i.e. l.Use(Gzip, Transform(t)),
+// so that the body is transformed before it is compressed.
+func Transform(transformers ...Transformer) lars.HandlerFunc {
+
+	return func(c lars.Context) {
+
+		res := c.Response()
+		w := res.Writer()
+
+		buf := bufferPool.Get().(*bytes.Buffer)
+		buf.Reset()
+
+		defer bufferPool.Put(buf)
+
+		tw := &transformWriter{ResponseWriter: w, buf: buf}
+		res.SetWriter(tw)
+
+		c.Next()
+
+		res.SetWriter(w)
+
+		if tw.status == 0 && buf.Len() == 0 {
+			return
+		}
+
+		var err error
+		body := buf.Bytes()
+
+		for _, t := range transformers {
+			if body, err = t.Transform(c, body); err != nil {
+				w.Header().Del(lars.ContentLength)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if tw.status == 0 {
+			tw.status = http.StatusOK
+		}
+
+		// the handler's Content-Length, if any, no longer matches the body
+		w.Header().Del(lars.ContentLength)
+		w.WriteHeader(tw.status)
+		w.Write(body)
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestTransform(t *testing.T) {
+
+	upper := TransformerFunc(func(c lars.Context, body []byte) ([]byte, error) {
+		return bytes.ToUpper(body), nil
+	})
+
+	cdn := TransformerFunc(func(c lars.Context, body []byte) ([]byte, error) {
+		return bytes.Replace(body, []byte("/STATIC/"), []byte("//CDN.EXAMPLE.COM/"), -1), nil
+	})
+
+	l := lars.New()
+	l.Use(Transform(upper, cdn))
+	l.Get("/test", func(c lars.Context) {
+		c.Response().Header().Set(lars.ContentLength, "24")
+		c.Response().WriteHeader(http.StatusCreated)
+		c.Response().Write([]byte("<img src=\"/static/a.png\">"))
+	})
+	l.Get("/empty", func(c lars.Context) {
+	})
+	l.Get("/flush", func(c lars.Context) {
+		c.Response().Write([]byte("a"))
+		c.Response().Flush()
+		c.Response().Write([]byte("b"))
+	})
+
+	r, _ := http.NewRequest(lars.GET, "/test", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Header().Get(lars.ContentLength), "")
+	Equal(t, w.Body.String(), "<IMG SRC=\"//CDN.EXAMPLE.COM/A.PNG\">")
+
+	r, _ = http.NewRequest(lars.GET, "/empty", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.Len(), 0)
+
+	r, _ = http.NewRequest(lars.GET, "/flush", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "AB")
+
+	l = lars.New()
+	l.Use(Transform(TransformerFunc(func(c lars.Context, body []byte) ([]byte, error) {
+		return nil, errors.New("bad transform")
+	})))
+	l.Get("/test", func(c lars.Context) {
+		c.Response().Write([]byte("test"))
+	})
+
+	r, _ = http.NewRequest(lars.GET, "/test", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusInternalServerError)
+	Equal(t, w.Body.String(), "Internal Server Error\n")
+}
+
+func TestTransformBufferedResponse(t *testing.T) {
+
+	l := lars.New()
+	l.SetBufferedResponse(true)
+	l.Use(Transform(TransformerFunc(func(c lars.Context, body []byte) ([]byte, error) {
+		return bytes.ToUpper(body), nil
+	})))
+	l.Get("/test", func(c lars.Context) {
+		c.Text(http.StatusCreated, "test")
+	})
+
+	r, _ := http.NewRequest(lars.GET, "/test", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "TEST")
+}
+
+func TestTransformBeforeGzip(t *testing.T) {
+
+	l := lars.New()
+	l.Use(Gzip, Transform(TransformerFunc(func(c lars.Context, body []byte) ([]byte, error) {
+		return append(body, " transformed"...), nil
+	})))
+	l.Get("/test", func(c lars.Context) {
+		c.Response().Write([]byte(testBody))
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	req, _ := http.NewRequest(lars.GET, server.URL+"/test", nil)
+	req.Header.Set(lars.AcceptEncoding, "gzip")
+
+	client := &http.Client{}
+
+	resp, err := client.Do(req)
+	Equal(t, err, nil)
+	Equal(t, resp.StatusCode, http.StatusOK)
+	Equal(t, resp.Header.Get(lars.ContentEncoding), lars.Gzip)
+
+	r, err := gzip.NewReader(resp.Body)
+	Equal(t, err, nil)
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	Equal(t, err, nil)
+	Equal(t, string(b), testBody+" transformed")
+}
@@ -0,0 +1,52 @@
+package lars
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountMethods are the methods a mounted handler is registered for, the same as Any
+var mountMethods = []string{CONNECT, DELETE, GET, HEAD, OPTIONS, PATCH, POST, PUT, TRACE}
+
+// Mount delegates all requests to prefix, and the paths below it, to the http.Handler
+// after running the group's middleware; the prefix is stripped from the request's URL
+// path, as http.StripPrefix does, so handlers such as a legacy mux or a metrics
+// endpoint route relative to where they're mounted.
+//
+// i.e. l.Mount("/legacy", legacyMux) serves /legacy/users as /users
+func (g *routeGroup) Mount(prefix string, h http.Handler) {
+
+	if h == nil {
+		panic("No handler mounted at path:" + prefix)
+	}
+
+	prefix = strings.TrimSuffix(prefix, basePath)
+
+	handler := func(c Context) {
+
+		ctx := c.BaseContext()
+
+		r := new(http.Request)
+		*r = *ctx.Request()
+
+		u := new(url.URL)
+		*u = *r.URL
+		u.Path = basePath + ctx.Param(WildcardParam)
+		u.RawPath = blank
+		r.URL = u
+
+		h.ServeHTTP(ctx.response, r)
+	}
+
+	wild := prefix + "/*"
+
+	for _, m := range mountMethods {
+
+		if prefix != blank || g.prefix != blank {
+			g.handle(m, prefix, []Handler{handler})
+		}
+
+		g.handle(m, wild, []Handler{handler})
+	}
+}
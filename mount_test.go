@@ -0,0 +1,87 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestMount(t *testing.T) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + r.URL.RawQuery + " " + r.Header.Get("X-Mw")))
+	})
+
+	l := New()
+	l.Use(func(c Context) {
+		c.Request().Header.Set("X-Mw", "l")
+		c.Response().Header().Set("X-Lars", "1")
+		c.Next()
+	})
+	l.Mount("/legacy/", mux)
+	l.Get("/other", basicHandler)
+
+	admin := l.Group("/admin", func(c Context) {
+		if c.Request().Header.Get("Authorization") == blank {
+			c.Response().WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	})
+	admin.Mount("/debug", mux)
+
+	code, body := request(GET, "/legacy/users/1?a=b", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "GET /users/1 a=b l")
+
+	code, body = request(POST, "/legacy", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "POST /  l")
+
+	code, body = request(DELETE, "/legacy/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "DELETE /  l")
+
+	code, _ = request(GET, "/other", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/admin/debug/vars", l)
+	Equal(t, code, http.StatusUnauthorized)
+
+	r, _ := http.NewRequest(GET, "/admin/debug/vars", nil)
+	r.Header.Set("Authorization", "token")
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "GET /vars  l")
+	Equal(t, w.Header().Get("X-Lars"), "1")
+	Equal(t, r.URL.Path, "/admin/debug/vars")
+
+	PanicMatches(t, func() { l.Mount("/nil", nil) }, "No handler mounted at path:/nil")
+
+	// mounted at the root of the router
+	l2 := New()
+	l2.Mount("/", mux)
+
+	code, body = request(GET, "/", l2)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "GET /  ")
+
+	code, body = request(PUT, "/a/b", l2)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "PUT /a/b  ")
+}
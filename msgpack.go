@@ -0,0 +1,70 @@
+package lars
+
+import "io"
+
+// MsgpackCodec marshals responses and decodes request bodies as MessagePack, lars has
+// no MessagePack implementation of it's own so one, such as vmihailenco/msgpack, must
+// be adapted to it and set using SetMsgpackCodec; it must be safe for concurrent use.
+type MsgpackCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Decode(r io.Reader, v interface{}) error
+}
+
+// SetMsgpackCodec sets the MsgpackCodec used by c.Msgpack and to decode request bodies
+// with the "application/msgpack" Content-Type using Bind and Decode; setting nil
+// removes MessagePack support. default nil
+func (l *LARS) SetMsgpackCodec(codec MsgpackCodec) {
+
+	l.msgpackCodec = codec
+
+	if codec == nil {
+		l.RegisterBinder(ApplicationMsgpack, nil)
+		return
+	}
+
+	l.RegisterBinder(ApplicationMsgpack, bindMsgpack)
+}
+
+// Msgpack marshals provided interface + returns MessagePack + status code,
+// ErrNoMsgpackCodec is returned when no MsgpackCodec was set.
+func (c *Ctx) Msgpack(code int, i interface{}) error {
+
+	codec := c.lars.msgpackCodec
+	if codec == nil {
+		return ErrNoMsgpackCodec
+	}
+
+	b, err := codec.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	return c.MsgpackBytes(code, b)
+}
+
+// MsgpackBytes returns provided MessagePack response with status code
+func (c *Ctx) MsgpackBytes(code int, b []byte) error {
+
+	c.response.Header().Set(ContentType, ApplicationMsgpack)
+	return c.writeBody(code, b)
+}
+
+// MsgpackOffer offers i rendered as MessagePack using c.Msgpack
+func MsgpackOffer(i interface{}) Offer {
+	return Offer{
+		MediaType: ApplicationMsgpack,
+		Render: func(c Context, code int) error {
+			return c.Msgpack(code, i)
+		},
+	}
+}
+
+func bindMsgpack(c Context, v interface{}) error {
+
+	codec := c.BaseContext().lars.msgpackCodec
+	if codec == nil {
+		return ErrUnsupportedMediaType
+	}
+
+	return codec.Decode(c.Request().Body, v)
+}
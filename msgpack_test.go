@@ -0,0 +1,133 @@
+package lars
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+// testMsgpackCodec stands in for a MessagePack library, prefixing JSON with a marker
+type testMsgpackCodec struct{}
+
+func (testMsgpackCodec) Marshal(v interface{}) ([]byte, error) {
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte("MP"), b...), nil
+}
+
+func (testMsgpackCodec) Decode(r io.Reader, v interface{}) error {
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(b, []byte("MP")) {
+		return errors.New("not msgpack")
+	}
+
+	return json.Unmarshal(b[2:], v)
+}
+
+func TestMsgpack(t *testing.T) {
+
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	l := New()
+	l.Get("/msgpack", func(c Context) error {
+		return c.Msgpack(http.StatusOK, user{Name: "lars"})
+	})
+	l.Get("/negotiate", func(c Context) error {
+		return c.Negotiate(http.StatusOK, JSONOffer(user{Name: "lars"}), MsgpackOffer(user{Name: "lars"}))
+	})
+	l.Post("/bind", func(c Context) error {
+
+		var u user
+
+		if err := c.Bind(&u); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, u.Name)
+	})
+	l.Post("/decode", func(c Context) error {
+
+		var u user
+
+		if err := c.Decode(false, 1024, &u); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, u.Name)
+	})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(POST, path, strings.NewReader(body))
+		r.Header.Set(ContentType, ApplicationMsgpack)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	// no codec set
+	code, _ := request(GET, "/msgpack", l)
+	Equal(t, code, http.StatusInternalServerError)
+
+	Equal(t, post("/bind", `MP{"name":"joey"}`).Code, http.StatusUnsupportedMediaType)
+	Equal(t, post("/decode", `MP{"name":"joey"}`).Code, http.StatusUnsupportedMediaType)
+
+	l.SetMsgpackCodec(testMsgpackCodec{})
+
+	r, _ := http.NewRequest(GET, "/msgpack", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationMsgpack)
+	Equal(t, w.Body.String(), `MP{"name":"lars"}`)
+
+	r, _ = http.NewRequest(GET, "/negotiate", nil)
+	r.Header.Set(Accept, ApplicationMsgpack)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), `MP{"name":"lars"}`)
+
+	w = post("/bind", `MP{"name":"joey"}`)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "joey")
+
+	w = post("/decode", `MP{"name":"joey"}`)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "joey")
+
+	Equal(t, post("/bind", `{"name":"joey"}`).Code, http.StatusInternalServerError)
+
+	l.SetMsgpackCodec(nil)
+
+	Equal(t, post("/bind", `MP{"name":"joey"}`).Code, http.StatusUnsupportedMediaType)
+}
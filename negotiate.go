@@ -0,0 +1,138 @@
+package lars
+
+import "strings"
+
+// Offer is a response, in a single media type, that Negotiate may choose
+type Offer struct {
+	MediaType string
+	Render    func(c Context, code int) error
+}
+
+// JSONOffer offers i rendered as JSON using c.JSON
+func JSONOffer(i interface{}) Offer {
+	return Offer{
+		MediaType: ApplicationJSON,
+		Render: func(c Context, code int) error {
+			return c.JSON(code, i)
+		},
+	}
+}
+
+// XMLOffer offers i rendered as XML using c.XML
+func XMLOffer(i interface{}) Offer {
+	return Offer{
+		MediaType: ApplicationXML,
+		Render: func(c Context, code int) error {
+			return c.XML(code, i)
+		},
+	}
+}
+
+// HTMLOffer offers the named template rendered, with data, using c.Render
+func HTMLOffer(name string, data interface{}) Offer {
+	return Offer{
+		MediaType: TextHTML,
+		Render: func(c Context, code int) error {
+			return c.Render(code, name, data)
+		},
+	}
+}
+
+// TextOffer offers s as plain text using c.Text
+func TextOffer(s string) Offer {
+	return Offer{
+		MediaType: TextPlain,
+		Render: func(c Context, code int) error {
+			return c.Text(code, s)
+		},
+	}
+}
+
+// Accepts returns the media type, out of the offered ones, most acceptable to the
+// client according to the Accept header and it's quality values; the most specific
+// media range matching an offer determines it's quality and ties go to the earliest
+// offer. The first offer is returned when no Accept header was sent and blank
+// when none are acceptable.
+//
+// i.e. c.Accepts(lars.ApplicationJSON, lars.TextHTML)
+func (c *Ctx) Accepts(offers ...string) string {
+
+	if len(offers) == 0 {
+		return blank
+	}
+
+	accepted := c.request.Header.Get(Accept)
+	if accepted == blank {
+		return offers[0]
+	}
+
+	values := parseAccept(accepted)
+
+	var best string
+	var bestQuality float64
+
+	for _, offer := range offers {
+
+		quality, specificity := 0.0, -1
+
+		for _, v := range values {
+			if s := mediaRangeSpecificity(v.value, offer); s > specificity {
+				quality, specificity = v.quality, s
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	return best
+}
+
+// Negotiate renders the offer most acceptable to the client, as determined by
+// Accepts, with status code. When none are acceptable the 406 Not Acceptable
+// handlers, registered using Register406, are run instead.
+//
+// i.e. c.Negotiate(http.StatusOK, lars.JSONOffer(user), lars.HTMLOffer("users/show", user))
+func (c *Ctx) Negotiate(code int, offers ...Offer) error {
+
+	c.response.Header().Add(Vary, Accept)
+
+	types := make([]string, len(offers))
+
+	for i, o := range offers {
+		types[i] = o.MediaType
+	}
+
+	mediaType := c.Accepts(types...)
+
+	for _, o := range offers {
+		if o.MediaType == mediaType {
+			return o.Render(c.parent, code)
+		}
+	}
+
+	c.parent.NotAcceptable()
+
+	return nil
+}
+
+// mediaRangeSpecificity returns how specifically the Accept media range matches
+// the media type; 2 for an exact match, 1 for type/*, 0 for */* and -1 for none.
+func mediaRangeSpecificity(mediaRange, mediaType string) int {
+
+	if mediaRange == "*/*" || mediaRange == "*" {
+		return 0
+	}
+
+	if strings.EqualFold(mediaRange, mediaType) {
+		return 2
+	}
+
+	if i := strings.IndexByte(mediaRange, slashByte); i != -1 && mediaRange[i+1:] == "*" &&
+		len(mediaType) > i && mediaType[i] == slashByte && strings.EqualFold(mediaRange[:i], mediaType[:i]) {
+		return 1
+	}
+
+	return -1
+}
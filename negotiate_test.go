@@ -0,0 +1,134 @@
+package lars
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestAccepts(t *testing.T) {
+
+	tests := []struct {
+		accept   string
+		offers   []string
+		expected string
+	}{
+		{"", []string{ApplicationJSON, TextHTML}, ApplicationJSON},
+		{"", nil, ""},
+		{"text/html", []string{ApplicationJSON, TextHTML}, TextHTML},
+		{"TEXT/HTML", []string{ApplicationJSON, TextHTML}, TextHTML},
+		{"application/xml;q=0.9, application/json", []string{ApplicationXML, ApplicationJSON}, ApplicationJSON},
+		{"text/*;q=0.5, application/json;q=0.4", []string{ApplicationJSON, TextPlain}, TextPlain},
+		{"*/*", []string{ApplicationXML, ApplicationJSON}, ApplicationXML},
+		{"*/*;q=0.1, application/json", []string{ApplicationXML, ApplicationJSON}, ApplicationJSON},
+		// the most specific range wins, even when refusing
+		{"text/*, text/plain;q=0", []string{TextPlain, TextHTML}, TextHTML},
+		{"*/*, application/json;q=0", []string{ApplicationJSON}, ""},
+		{"image/png", []string{ApplicationJSON, TextHTML}, ""},
+		{"text/html;level=1;q=0.2, application/json;q=0.8", []string{TextHTML, ApplicationJSON}, ApplicationJSON},
+	}
+
+	for i, tt := range tests {
+
+		var accepts string
+
+		l := New()
+		l.Get("/", func(c Context) {
+			accepts = c.Accepts(tt.offers...)
+		})
+
+		r, _ := http.NewRequest(GET, "/", nil)
+		if tt.accept != "" {
+			r.Header.Set(Accept, tt.accept)
+		}
+		l.Serve().ServeHTTP(httptest.NewRecorder(), r)
+
+		if accepts != tt.expected {
+			t.Errorf("test %d: expected '%s' got '%s'", i, tt.expected, accepts)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	u := user{Name: "joeybloggs"}
+
+	l := New()
+	l.Get("/users", func(c Context) error {
+		return c.Negotiate(http.StatusOK, JSONOffer(u), XMLOffer(u), TextOffer(u.Name), Offer{
+			MediaType: ApplicationMsgpack,
+			Render: func(c Context, code int) error {
+				return c.Text(http.StatusCreated, "msgpack")
+			},
+		})
+	})
+
+	hf := l.Serve()
+
+	negotiate := func(accept string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, "/users", nil)
+		r.Header.Set(Accept, accept)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := negotiate("application/json")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Header().Get(Vary), Accept)
+	Equal(t, w.Body.String(), `{"name":"joeybloggs"}`)
+
+	w = negotiate("application/xml, application/json;q=0.5")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationXMLCharsetUTF8)
+	Equal(t, w.Body.String(), string(xmlHeader)+`<user><name>joeybloggs</name></user>`)
+
+	w = negotiate("text/*")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "joeybloggs")
+
+	w = negotiate("application/msgpack")
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "msgpack")
+
+	w = negotiate("image/png")
+	Equal(t, w.Code, http.StatusNotAcceptable)
+	Equal(t, w.Body.String(), "Not Acceptable\n")
+
+	// HTML is rendered using the Renderer
+	l.SetRenderer(rendererFunc(func(w io.Writer, name string, data interface{}, c Context) error {
+		_, err := io.WriteString(w, "<h1>"+data.(user).Name+"</h1>")
+		return err
+	}))
+	l.Get("/users/html", func(c Context) error {
+		return c.Negotiate(http.StatusOK, JSONOffer(u), HTMLOffer("users/show", u))
+	})
+
+	r, _ := http.NewRequest(GET, "/users/html", nil)
+	r.Header.Set(Accept, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), TextHTMLCharsetUTF8)
+	Equal(t, w.Body.String(), "<h1>joeybloggs</h1>")
+}
@@ -18,8 +18,11 @@ const (
 )
 
 type methodChain struct {
-	handlerName string
-	chain       HandlersChain
+	route *Route
+	chain HandlersChain
+	// next is the route registered for the same path but with different
+	// param constraints, tried in order when this one doesn't match
+	next *methodChain
 }
 
 type existingParams map[string]struct{}
@@ -72,7 +75,7 @@ func (n *node) incrementChildPrio(pos int) int {
 
 // addRoute adds a node with the given handle to the path.
 // here we set a Middleware because we have  to transfer all route's middlewares (it's a chain of functions) (with it's handler) to the node
-func (n *node) add(path string, handlerName string, handler HandlersChain) (lp uint8) {
+func (n *node) add(path string, route *Route, handler HandlersChain) (lp uint8) {
 
 	var err error
 
@@ -80,14 +83,20 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 		path = basePath
 	}
 
-	existing := make(existingParams)
 	fullPath := path
 
 	if path, err = url.QueryUnescape(path); err != nil {
 		panic("Query Unescape Error on path '" + fullPath + "': " + err.Error())
 	}
 
-	fullPath = path
+	return n.addUnescaped(path, route, handler)
+}
+
+// addUnescaped adds a node with the given handle to the already unescaped path.
+func (n *node) addUnescaped(path string, route *Route, handler HandlersChain) (lp uint8) {
+
+	existing := make(existingParams)
+	fullPath := path
 
 	n.priority++
 	numParams := countParams(path)
@@ -178,29 +187,23 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 					n.incrementChildPrio(len(n.indices) - 1)
 					n = child
 				}
-				n.insertChild(numParams, existing, path, fullPath, handlerName, handler)
+				n.insertChild(numParams, existing, path, fullPath, route, handler)
 				return
 
 			} else if i == len(path) { // Make node a (in-path) leaf
-				if n.handler != nil {
-					panic("handlers are already registered for path '" + fullPath + "'")
-				}
-				n.handler = &methodChain{
-					handlerName: handlerName,
-					chain:       handler,
-				}
+				n.setHandler(&methodChain{route: route, chain: handler}, fullPath)
 			}
 			return
 		}
 	} else { // Empty tree
-		n.insertChild(numParams, existing, path, fullPath, handlerName, handler)
+		n.insertChild(numParams, existing, path, fullPath, route, handler)
 		n.nType = isRoot
 	}
 
 	return
 }
 
-func (n *node) insertChild(numParams uint8, existing existingParams, path string, fullPath string, handlerName string, handler HandlersChain) {
+func (n *node) insertChild(numParams uint8, existing existingParams, path string, fullPath string, route *Route, handler HandlersChain) {
 
 	var offset int // already handled bytes of the path
 
@@ -301,10 +304,10 @@ func (n *node) insertChild(numParams uint8, existing existingParams, path string
 			child = &node{
 				path:     path[i:],
 				nType:    matchesAny,
-				handler:  &methodChain{handlerName: handlerName, chain: handler},
 				priority: 1,
 			}
 			n.children = []*node{child}
+			child.setHandler(&methodChain{route: route, chain: handler}, fullPath)
 
 			return
 		}
@@ -316,11 +319,145 @@ func (n *node) insertChild(numParams uint8, existing existingParams, path string
 
 	// insert remaining path part and handle to the leaf
 	n.path = path[offset:]
-	n.handler = &methodChain{handlerName: handlerName, chain: handler}
+	n.setHandler(&methodChain{route: route, chain: handler}, fullPath)
+}
+
+// setHandler sets the node's handler, routes registered for the same path are
+// only permitted when they have param constraints; they're tried in the order
+// registered followed by the one without constraints.
+func (n *node) setHandler(mc *methodChain, fullPath string) {
+
+	if n.handler == nil {
+		n.handler = mc
+		return
+	}
+
+	if !mc.constrained() {
+
+		last := n.handler
+		for last.next != nil {
+			last = last.next
+		}
+
+		if !last.constrained() {
+			panic("handlers are already registered for path '" + fullPath + "'")
+		}
+
+		last.next = mc
+		return
+	}
+
+	// insert before the route without constraints, if any
+	mc2 := &n.handler
+	for *mc2 != nil && (*mc2).constrained() {
+		mc2 = &(*mc2).next
+	}
+
+	mc.next = *mc2
+	*mc2 = mc
+}
+
+func (mc *methodChain) constrained() bool {
+	return mc.route != nil && len(mc.route.constraints) > 0
+}
+
+// match returns the first route, registered for the node, whose param
+// constraints are satisfied by p.
+func (mc *methodChain) match(p Params) (HandlersChain, *Route) {
+
+	for ; mc != nil; mc = mc.next {
+		if mc.route == nil || mc.route.matches(p) {
+			return mc.chain, mc.route
+		}
+	}
+
+	return nil, nil
+}
+
+// remove unlinks the handlers of the route registered with path, including it's param
+// constraints, and returns the route, nil when there's none; the node is kept so the
+// structure of the tree is unchanged, use rebuild to prune it.
+func (n *node) remove(path string) *Route {
+
+	var removed *Route
+
+	for mc := &n.handler; *mc != nil; {
+
+		if route := (*mc).route; route != nil && route.path == path {
+			removed = route
+			*mc = (*mc).next
+			continue
+		}
+
+		mc = &(*mc).next
+	}
+
+	// routes with optional params are registered for more than one node
+	for _, child := range n.children {
+		if route := child.remove(path); route != nil {
+			removed = route
+		}
+	}
+
+	return removed
+}
+
+// rebuild returns a new tree holding the handlers still registered in the tree, nil when
+// there are none, pruning the nodes remove left without handlers; those would otherwise
+// leak and conflict with routes registered later i.e. a removed /t/:id with /t/new.
+func (n *node) rebuild() *node {
+
+	var root *node
+
+	n.chains(blank, func(path string, mc *methodChain) {
+
+		if root == nil {
+			root = new(node)
+		}
+
+		root.addUnescaped(path, mc.route, mc.chain)
+	})
+
+	return root
+}
+
+// chains calls fn, in the order they're tried, for every handler registered in the tree
+// along with the full path it was registered for.
+func (n *node) chains(prefix string, fn func(path string, mc *methodChain)) {
+
+	prefix += n.path
+
+	for mc := n.handler; mc != nil; mc = mc.next {
+		fn(prefix, mc)
+	}
+
+	for _, child := range n.children {
+		child.chains(prefix, fn)
+	}
+}
+
+// walk calls fn for the route of every handler registered in the tree, once per
+// route even when it has optional params and so is registered for several nodes.
+func (n *node) walk(fn func(route *Route)) {
+	n.walkSeen(fn, make(map[*Route]bool))
+}
+
+func (n *node) walkSeen(fn func(route *Route), seen map[*Route]bool) {
+
+	for mc := n.handler; mc != nil; mc = mc.next {
+		if mc.route != nil && !seen[mc.route] {
+			seen[mc.route] = true
+			fn(mc.route)
+		}
+	}
+
+	for _, child := range n.children {
+		child.walkSeen(fn, seen)
+	}
 }
 
 // Returns the handle registered with the given path (key).
-func (n *node) find(path string, po Params) (handler HandlersChain, p Params, handlerName string) {
+func (n *node) find(path string, po Params) (handler HandlersChain, p Params, route *Route) {
 
 	p = po
 
@@ -375,8 +512,7 @@ walk: // Outer loop for walking the tree
 					}
 
 					if n.handler != nil {
-						handler = n.handler.chain
-						handlerName = n.handler.handlerName
+						handler, route = n.handler.match(p)
 						return
 					} else if len(n.children) == 1 {
 						// No handle found. Check if a handle for this path
@@ -393,8 +529,12 @@ walk: // Outer loop for walking the tree
 					p[i].Key = WildcardParam
 					p[i].Value = path[1:]
 
-					handler = n.handler.chain
-					handlerName = n.handler.handlerName
+					// named catch-all i.e. /*filepath
+					if len(n.path) > 2 {
+						p[i].Key = n.path[2:]
+					}
+
+					handler, route = n.handler.match(p)
 					return
 
 					// can't happen, but left here in case I'm wrong
@@ -408,7 +548,7 @@ walk: // Outer loop for walking the tree
 			// We should have reached the node containing the handle.
 			// Check if this node has a handle registered.
 			if n.handler != nil {
-				if handler, handlerName = n.handler.chain, n.handler.handlerName; handler != nil {
+				if handler, route = n.handler.match(p); handler != nil {
 					return
 				}
 			}
@@ -77,6 +77,115 @@ func TestWildcardParam(t *testing.T) {
 	Equal(t, body, "testwildslash/")
 }
 
+func TestNamedWildcardParam(t *testing.T) {
+	l := New()
+	l.Get("/files/:bucket/*filepath", func(c Context) {
+		c.Text(http.StatusOK, c.Param("bucket")+" "+c.Param("filepath")+" "+c.Param(WildcardParam)+" "+c.ParamDefault("filepath", "index.html"))
+	})
+	l.Get("/static/*", func(c Context) {
+		c.Text(http.StatusOK, c.ParamDefault(WildcardParam, "index.html")+" "+c.ParamDefault("missing", "default"))
+	})
+
+	code, body := request(GET, "/files/images/2016/logo.png", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "images 2016/logo.png 2016/logo.png 2016/logo.png")
+
+	code, body = request(GET, "/files/images/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "images   index.html")
+
+	code, body = request(GET, "/static/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "index.html default")
+
+	code, body = request(GET, "/static/css/app.css", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "css/app.css default")
+
+	// catch-all must be the final segment
+	PanicMatches(t, func() { l.Get("/assets/*filepath/edit", basicHandler) }, "Character after the * symbol is not permitted, path '/assets/*filepath/edit'")
+	PanicMatches(t, func() { l.Get("/public/*/edit", basicHandler) }, "Character after the * symbol is not permitted, path '/public/*/edit'")
+}
+
+func TestOptionalParams(t *testing.T) {
+
+	l := New()
+	l.Get("/articles/:year{int}/:month(\\d{2})?/:day?", func(c Context) {
+		c.Text(http.StatusOK, c.Param("year")+"-"+c.Param("month")+"-"+c.Param("day")+" "+c.Route().Path())
+	}).Name("articles")
+	l.Get("/feeds/:format?", func(c Context) {
+		c.Text(http.StatusOK, "feed "+c.ParamDefault("format", "rss"))
+	})
+
+	code, body := request(GET, "/articles/2016", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "2016-- /articles/:year{int}/:month(\\d{2})?/:day?")
+
+	code, body = request(GET, "/articles/2016/05", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "2016-05- /articles/:year{int}/:month(\\d{2})?/:day?")
+
+	code, body = request(GET, "/articles/2016/05/31", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "2016-05-31 /articles/:year{int}/:month(\\d{2})?/:day?")
+
+	// constraints of matched optional params still apply
+	code, _ = request(GET, "/articles/2016/may", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(GET, "/articles/2016/05/31/extra", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "/feeds", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "feed rss")
+
+	code, body = request(GET, "/feeds/atom", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "feed atom")
+
+	url, err := l.URL("articles", "2016")
+	Equal(t, err, nil)
+	Equal(t, url, "/articles/2016")
+
+	url, err = l.URL("articles", "2016", "05", "31")
+	Equal(t, err, nil)
+	Equal(t, url, "/articles/2016/05/31")
+
+	_, err = l.URL("articles")
+	Equal(t, err, ErrRouteParams)
+
+	// listed once
+	Equal(t, len(l.Routes()), 2)
+
+	doc := l.OpenAPI(OpenAPIInfo{})
+	Equal(t, len(doc.Paths), 5)
+	NotEqual(t, doc.Paths["/articles/{year}/{month}"], nil)
+
+	Equal(t, l.Remove(GET, "/articles/:year{int}/:month(\\d{2})?/:day?"), true)
+
+	code, _ = request(GET, "/articles/2016", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(GET, "/articles/2016/05/31", l)
+	Equal(t, code, http.StatusNotFound)
+
+	PanicMatches(t, func() { l.Get("/posts/:id?/edit", basicHandler) }, "Optional params must be the final segments, path '/posts/:id?/edit'")
+
+	l = New()
+	l.Get("/:lang?", basicHandler).Name("home")
+
+	code, _ = request(GET, "/", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/fr", l)
+	Equal(t, code, http.StatusOK)
+
+	url, err = l.URL("home")
+	Equal(t, err, nil)
+	Equal(t, url, "/")
+}
+
 func TestBadRoutes(t *testing.T) {
 	l := New()
 
@@ -0,0 +1,366 @@
+package lars
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const openAPIVersion = "3.0.3"
+
+// routeDoc is the documentation of a route used when generating the OpenAPI document
+type routeDoc struct {
+	summary     string
+	description string
+	tags        []string
+	request     interface{}
+	responses   map[int]interface{}
+}
+
+// OpenAPIInfo is the metadata about the API included in the OpenAPI document
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIDocument is an OpenAPI 3 document describing the registered routes
+type OpenAPIDocument struct {
+	OpenAPI string                                  `json:"openapi"`
+	Info    OpenAPIInfo                             `json:"info"`
+	Paths   map[string]map[string]*OpenAPIOperation `json:"paths"`
+}
+
+// OpenAPIOperation describes a single route
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a URL param
+type OpenAPIParameter struct {
+	Name     string                 `json:"name"`
+	In       string                 `json:"in"`
+	Required bool                   `json:"required"`
+	Schema   map[string]interface{} `json:"schema"`
+}
+
+// OpenAPIRequestBody describes the request body of a route
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a response of a route
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType describes the schema of a request or response body
+type OpenAPIMediaType struct {
+	Schema map[string]interface{} `json:"schema"`
+}
+
+func (r *Route) doc() *routeDoc {
+
+	if r.documentation == nil {
+		r.documentation = &routeDoc{responses: make(map[int]interface{})}
+	}
+
+	return r.documentation
+}
+
+// Summary sets the short summary of the route included in the OpenAPI document.
+func (r *Route) Summary(summary string) *Route {
+	return r.set(func() { r.doc().summary = summary })
+}
+
+// Description sets the description of the route included in the OpenAPI document.
+func (r *Route) Description(description string) *Route {
+	return r.set(func() { r.doc().description = description })
+}
+
+// Tags sets the tags the route is grouped by in the OpenAPI document.
+func (r *Route) Tags(tags ...string) *Route {
+	return r.set(func() { r.doc().tags = tags })
+}
+
+// Request sets the type of the route's JSON request body, described in the OpenAPI
+// document using the JSON schema of v's type i.e. Request(User{})
+func (r *Route) Request(v interface{}) *Route {
+	return r.set(func() { r.doc().request = v })
+}
+
+// Response adds a response, with the status code, to the route; v is the value whose
+// type describes the JSON body, nil for responses without a body i.e. Response(200, User{})
+func (r *Route) Response(code int, v interface{}) *Route {
+	return r.set(func() { r.doc().responses[code] = v })
+}
+
+// OpenAPI generates an OpenAPI 3 document describing every registered route, along
+// with the documentation added to the routes using Summary, Request, Response etc.
+func (l *LARS) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+
+	doc := &OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+
+	addPath := func(route *Route, path string) {
+
+		path, params := openAPIPath(path)
+
+		op := &OpenAPIOperation{
+			Parameters: params,
+			Responses:  make(map[string]*OpenAPIResponse),
+		}
+
+		if d := route.documentation; d != nil {
+
+			op.Summary = d.summary
+			op.Description = d.description
+			op.Tags = d.tags
+
+			if d.request != nil {
+				op.RequestBody = &OpenAPIRequestBody{
+					Required: true,
+					Content:  openAPIContent(d.request),
+				}
+			}
+
+			for code, v := range d.responses {
+				op.Responses[strconv.Itoa(code)] = &OpenAPIResponse{
+					Description: http.StatusText(code),
+					Content:     openAPIContent(v),
+				}
+			}
+		}
+
+		// at least one response is required
+		if len(op.Responses) == 0 {
+			op.Responses[strconv.Itoa(http.StatusOK)] = &OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+		}
+
+		ops, ok := doc.Paths[path]
+		if !ok {
+			ops = make(map[string]*OpenAPIOperation)
+			doc.Paths[path] = ops
+		}
+
+		ops[strings.ToLower(route.method)] = op
+	}
+
+	// routes with optional params are documented for each path they're registered for
+	add := func(route *Route) {
+		for _, path := range optionalPaths(route.path) {
+			addPath(route, path)
+		}
+	}
+
+	l.routesMu.RLock()
+	defer l.routesMu.RUnlock()
+
+	for _, tree := range l.trees {
+		tree.walk(add)
+	}
+
+	for _, h := range l.hosts {
+		for _, tree := range h.trees {
+			tree.walk(add)
+		}
+	}
+
+	return doc
+}
+
+// ServeOpenAPI registers a GET route at path, i.e. "/openapi.json", serving the OpenAPI
+// document; it's generated on the first request so all routes have been registered.
+func (l *LARS) ServeOpenAPI(path string, info OpenAPIInfo) *Route {
+
+	var (
+		once sync.Once
+		b    []byte
+		err  error
+	)
+
+	return l.Get(path, func(c Context) error {
+
+		once.Do(func() {
+			b, err = json.Marshal(l.OpenAPI(info))
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return c.JSONBytes(http.StatusOK, b)
+	})
+}
+
+// openAPIPath converts the route's path to an OpenAPI path template i.e. /users/:id(\d+)
+// becomes /users/{id}, returning the parameters.
+func openAPIPath(path string) (string, []OpenAPIParameter) {
+
+	var params []OpenAPIParameter
+
+	segments := strings.Split(path, basePath)
+
+	for i, s := range segments {
+
+		if s == blank || (s[0] != paramByte && s[0] != wildByte) {
+			continue
+		}
+
+		name := s[1:]
+		schema := map[string]interface{}{"type": "string"}
+
+		if j := strings.IndexAny(name, "({"); j != -1 {
+
+			constraint := name[j+1 : len(name)-1]
+
+			if name[j] == '(' {
+				schema["pattern"] = "^(?:" + constraint + ")$"
+			} else {
+				switch constraint {
+				case "int":
+					schema["type"] = "integer"
+				case "uuid":
+					schema["format"] = "uuid"
+				case "alpha":
+					schema["pattern"] = "^[a-zA-Z]+$"
+				case "alphanum":
+					schema["pattern"] = "^[a-zA-Z0-9]+$"
+				}
+			}
+
+			name = name[:j]
+		}
+
+		if s[0] == wildByte && name == blank {
+			name = WildcardParam[1:]
+		}
+
+		segments[i] = "{" + name + "}"
+		params = append(params, OpenAPIParameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+
+	return strings.Join(segments, basePath), params
+}
+
+func openAPIContent(v interface{}) map[string]OpenAPIMediaType {
+
+	if v == nil {
+		return nil
+	}
+
+	return map[string]OpenAPIMediaType{
+		ApplicationJSON: {Schema: openAPISchema(reflect.TypeOf(v), make(map[reflect.Type]bool))},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// openAPISchema returns the JSON schema of t as encoded by encoding/json, recursive
+// types are described as plain objects once they've been seen.
+func openAPISchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Slice, reflect.Array:
+
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+
+		return map[string]interface{}{"type": "array", "items": openAPISchema(t.Elem(), seen)}
+
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": openAPISchema(t.Elem(), seen)}
+
+	case reflect.Struct:
+
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+
+		if seen[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+
+		seen[t] = true
+		defer delete(seen, t)
+
+		props := make(map[string]interface{})
+		openAPIProperties(t, props, seen)
+
+		return map[string]interface{}{"type": "object", "properties": props}
+	}
+
+	return map[string]interface{}{}
+}
+
+// openAPIProperties adds the JSON encoded fields of struct t to props, including
+// those of embedded structs.
+func openAPIProperties(t reflect.Type, props map[string]interface{}, seen map[reflect.Type]bool) {
+
+	for i := 0; i < t.NumField(); i++ {
+
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+
+		if f.Anonymous && name == blank {
+
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				openAPIProperties(ft, props, seen)
+				continue
+			}
+		}
+
+		if f.PkgPath != blank {
+			continue // unexported
+		}
+
+		if name == blank {
+			name = f.Name
+		}
+
+		props[name] = openAPISchema(f.Type, seen)
+	}
+}
@@ -0,0 +1,117 @@
+package lars
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+type openAPIBase struct {
+	ID int64 `json:"id"`
+}
+
+type openAPIUser struct {
+	openAPIBase
+	Name     string            `json:"name"`
+	Email    string            `json:"email,omitempty"`
+	Password string            `json:"-"`
+	Created  time.Time         `json:"created"`
+	Tags     []string          `json:"tags"`
+	Meta     map[string]string `json:"meta"`
+	Score    float64
+	Friends  []*openAPIUser `json:"friends"`
+	private  bool
+}
+
+func TestOpenAPI(t *testing.T) {
+
+	l := New()
+	l.Get("/users/:id{int}", basicHandler).
+		Summary("Get a user").
+		Description("Returns the user with the ID").
+		Tags("users").
+		Response(http.StatusOK, &openAPIUser{}).
+		Response(http.StatusNotFound, nil)
+	l.Post("/users", basicHandler).Request(openAPIUser{}).Response(http.StatusCreated, openAPIUser{})
+	l.Get("/files/:name([a-z]+)/*", basicHandler)
+	l.Get("/undocumented", basicHandler)
+
+	doc := l.OpenAPI(OpenAPIInfo{Title: "Test", Version: "1.0.0"})
+	Equal(t, doc.OpenAPI, "3.0.3")
+	Equal(t, doc.Info.Title, "Test")
+	Equal(t, len(doc.Paths), 4)
+
+	op := doc.Paths["/users/{id}"]["get"]
+	Equal(t, op.Summary, "Get a user")
+	Equal(t, op.Description, "Returns the user with the ID")
+	Equal(t, op.Tags, []string{"users"})
+	Equal(t, len(op.Parameters), 1)
+	Equal(t, op.Parameters[0].Name, "id")
+	Equal(t, op.Parameters[0].In, "path")
+	Equal(t, op.Parameters[0].Required, true)
+	Equal(t, op.Parameters[0].Schema["type"], "integer")
+	Equal(t, len(op.Responses), 2)
+	Equal(t, op.Responses["404"].Description, "Not Found")
+	Equal(t, op.Responses["404"].Content == nil, true)
+
+	schema := op.Responses["200"].Content[ApplicationJSON].Schema
+	Equal(t, schema["type"], "object")
+
+	props := schema["properties"].(map[string]interface{})
+	Equal(t, len(props), 8)
+	Equal(t, props["id"], map[string]interface{}{"type": "integer"})
+	Equal(t, props["name"], map[string]interface{}{"type": "string"})
+	Equal(t, props["email"], map[string]interface{}{"type": "string"})
+	Equal(t, props["created"], map[string]interface{}{"type": "string", "format": "date-time"})
+	Equal(t, props["tags"], map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}})
+	Equal(t, props["meta"], map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}})
+	Equal(t, props["Score"], map[string]interface{}{"type": "number"})
+	Equal(t, props["friends"], map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}})
+
+	op = doc.Paths["/users"]["post"]
+	Equal(t, op.RequestBody.Required, true)
+	Equal(t, op.RequestBody.Content[ApplicationJSON].Schema["type"], "object")
+	Equal(t, op.Responses["201"].Description, "Created")
+
+	op = doc.Paths["/files/{name}/{wildcard}"]["get"]
+	Equal(t, len(op.Parameters), 2)
+	Equal(t, op.Parameters[0].Schema["pattern"], "^(?:[a-z]+)$")
+	Equal(t, op.Parameters[1].Name, "wildcard")
+
+	op = doc.Paths["/undocumented"]["get"]
+	Equal(t, len(op.Responses), 1)
+	Equal(t, op.Responses["200"].Description, "OK")
+}
+
+func TestServeOpenAPI(t *testing.T) {
+
+	l := New()
+	l.ServeOpenAPI("/openapi.json", OpenAPIInfo{Title: "Test", Version: "1.0.0"})
+	l.Get("/users/:id", basicHandler).Tags("users")
+
+	for i := 0; i < 2; i++ {
+		code, body := request(GET, "/openapi.json", l)
+		Equal(t, code, http.StatusOK)
+
+		var doc OpenAPIDocument
+		Equal(t, json.Unmarshal([]byte(body), &doc), nil)
+		Equal(t, doc.Info.Version, "1.0.0")
+		Equal(t, len(doc.Paths), 2)
+		Equal(t, doc.Paths["/users/{id}"]["get"].Tags, []string{"users"})
+		Equal(t, doc.Paths["/users/{id}"]["get"].Parameters[0].Schema["type"], "string")
+	}
+}
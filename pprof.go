@@ -0,0 +1,27 @@
+package lars
+
+import "net/http/pprof"
+
+// pprofProfiles are the runtime profiles served by name
+var pprofProfiles = []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"}
+
+// EnablePprof registers the net/http/pprof handlers under prefix, i.e. "/debug/pprof",
+// through the router so the profiling endpoints run the middleware, such as
+// authentication, instead of needing a second listener. The routes are flagged Silent.
+//
+// i.e. l.EnablePprof("/debug/pprof", AdminOnly)
+func (l *LARS) EnablePprof(prefix string, middleware ...Handler) {
+
+	g := l.Group(prefix, middleware...)
+
+	g.Get("/", pprof.Index).Silent()
+	g.Get("/cmdline", pprof.Cmdline).Silent()
+	g.Get("/profile", pprof.Profile).Silent()
+	g.Get("/symbol", pprof.Symbol).Silent()
+	g.Post("/symbol", pprof.Symbol).Silent()
+	g.Get("/trace", pprof.Trace).Silent()
+
+	for _, name := range pprofProfiles {
+		g.Get("/"+name, pprof.Handler(name)).Silent()
+	}
+}
@@ -0,0 +1,72 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestEnablePprof(t *testing.T) {
+
+	guard := func(c Context) {
+
+		if c.Request().Header.Get("X-Admin") != "true" {
+			c.Response().WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		c.Next()
+	}
+
+	l := New()
+	l.EnablePprof("/admin/pprof", guard)
+
+	hf := l.Serve()
+
+	serve := func(path string, admin bool) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		if admin {
+			r.Header.Set("X-Admin", "true")
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/admin/pprof/", false)
+	Equal(t, w.Code, http.StatusUnauthorized)
+
+	w = serve("/admin/pprof/", true)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, strings.Contains(w.Body.String(), "goroutine"), true)
+
+	w = serve("/admin/pprof/goroutine?debug=1", true)
+	Equal(t, w.Code, http.StatusOK)
+	MatchRegex(t, w.Body.String(), "^goroutine profile: total")
+
+	w = serve("/admin/pprof/cmdline", true)
+	Equal(t, w.Code, http.StatusOK)
+
+	w = serve("/admin/pprof/heap", false)
+	Equal(t, w.Code, http.StatusUnauthorized)
+
+	for _, r := range l.Routes() {
+		Equal(t, strings.HasPrefix(r.Path, "/admin/pprof/"), true)
+	}
+
+	Equal(t, len(l.Routes()), 12)
+}
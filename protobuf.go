@@ -0,0 +1,90 @@
+package lars
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+// ProtobufCodec marshals and unmarshals protocol buffer messages, lars has no protocol
+// buffers implementation of it's own so one, such as google.golang.org/protobuf/proto,
+// must be adapted to it and set using SetProtobufCodec i.e.
+//
+//	func (protoCodec) Marshal(v interface{}) ([]byte, error) { return proto.Marshal(v.(proto.Message)) }
+//	func (protoCodec) Unmarshal(b []byte, v interface{}) error { return proto.Unmarshal(b, v.(proto.Message)) }
+type ProtobufCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(b []byte, v interface{}) error
+}
+
+// SetProtobufCodec sets the ProtobufCodec used by c.Protobuf and to decode request
+// bodies with the "application/protobuf" or "application/x-protobuf" Content-Type
+// using Bind and Decode; setting nil removes protocol buffers support. default nil
+func (l *LARS) SetProtobufCodec(codec ProtobufCodec) {
+
+	l.protobufCodec = codec
+
+	fn := BinderFunc(bindProtobuf)
+	if codec == nil {
+		fn = nil
+	}
+
+	l.RegisterBinder(ApplicationProtobuf, fn)
+	l.RegisterBinder(ApplicationXProtobuf, fn)
+}
+
+// Protobuf marshals the provided message + returns protocol buffers + status code,
+// ErrNoProtobufCodec is returned when no ProtobufCodec was set.
+func (c *Ctx) Protobuf(code int, m interface{}) error {
+
+	codec := c.lars.protobufCodec
+	if codec == nil {
+		return ErrNoProtobufCodec
+	}
+
+	b, err := codec.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return c.ProtobufBytes(code, b)
+}
+
+// ProtobufBytes returns provided protocol buffers response with status code
+func (c *Ctx) ProtobufBytes(code int, b []byte) error {
+
+	c.response.Header().Set(ContentType, ApplicationProtobuf)
+	return c.writeBody(code, b)
+}
+
+// ProtobufOffer offers the message rendered as protocol buffers using c.Protobuf
+func ProtobufOffer(m interface{}) Offer {
+	return Offer{
+		MediaType: ApplicationProtobuf,
+		Render: func(c Context, code int) error {
+			return c.Protobuf(code, m)
+		},
+	}
+}
+
+// decodeProtobuf reads the whole message from r, protocol buffers can't be
+// decoded as they're read, and unmarshals it into v
+func (c *Ctx) decodeProtobuf(r io.Reader, v interface{}) error {
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return c.lars.protobufCodec.Unmarshal(b, v)
+}
+
+func bindProtobuf(c Context, v interface{}) error {
+
+	ctx := c.BaseContext()
+
+	if ctx.lars.protobufCodec == nil {
+		return ErrUnsupportedMediaType
+	}
+
+	return ctx.decodeProtobuf(ctx.request.Body, v)
+}
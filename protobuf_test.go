@@ -0,0 +1,125 @@
+package lars
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+// testMessage stands in for a generated protocol buffers message
+type testMessage struct {
+	Name string
+}
+
+// testProtobufCodec stands in for a protocol buffers library, encoding a
+// testMessage as it's name prefixed with a marker
+type testProtobufCodec struct{}
+
+func (testProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte("PB" + v.(*testMessage).Name), nil
+}
+
+func (testProtobufCodec) Unmarshal(b []byte, v interface{}) error {
+
+	if !strings.HasPrefix(string(b), "PB") {
+		return errors.New("not protobuf")
+	}
+
+	v.(*testMessage).Name = string(b[2:])
+
+	return nil
+}
+
+func TestProtobuf(t *testing.T) {
+
+	l := New()
+	l.Get("/protobuf", func(c Context) error {
+		return c.Protobuf(http.StatusOK, &testMessage{Name: "lars"})
+	})
+	l.Get("/negotiate", func(c Context) error {
+		return c.Negotiate(http.StatusOK, JSONOffer(testMessage{Name: "lars"}), ProtobufOffer(&testMessage{Name: "lars"}))
+	})
+	l.Post("/bind", func(c Context) error {
+
+		var m testMessage
+
+		if err := c.Bind(&m); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, m.Name)
+	})
+	l.Post("/decode", func(c Context) error {
+
+		var m testMessage
+
+		if err := c.Decode(false, 1024, &m); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, m.Name)
+	})
+
+	post := func(path, contentType, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(POST, path, strings.NewReader(body))
+		r.Header.Set(ContentType, contentType)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	// no codec set
+	code, _ := request(GET, "/protobuf", l)
+	Equal(t, code, http.StatusInternalServerError)
+
+	Equal(t, post("/bind", ApplicationProtobuf, "PBjoey").Code, http.StatusUnsupportedMediaType)
+	Equal(t, post("/decode", ApplicationProtobuf, "PBjoey").Code, http.StatusUnsupportedMediaType)
+
+	l.SetProtobufCodec(testProtobufCodec{})
+
+	r, _ := http.NewRequest(GET, "/protobuf", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationProtobuf)
+	Equal(t, w.Body.String(), "PBlars")
+
+	r, _ = http.NewRequest(GET, "/negotiate", nil)
+	r.Header.Set(Accept, ApplicationProtobuf)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "PBlars")
+
+	for _, typ := range []string{ApplicationProtobuf, ApplicationXProtobuf} {
+
+		w = post("/bind", typ, "PBjoey")
+		Equal(t, w.Code, http.StatusOK)
+		Equal(t, w.Body.String(), "joey")
+
+		w = post("/decode", typ, "PBjoey")
+		Equal(t, w.Code, http.StatusOK)
+		Equal(t, w.Body.String(), "joey")
+	}
+
+	Equal(t, post("/bind", ApplicationProtobuf, "joey").Code, http.StatusInternalServerError)
+
+	l.SetProtobufCodec(nil)
+
+	Equal(t, post("/bind", ApplicationXProtobuf, "PBjoey").Code, http.StatusUnsupportedMediaType)
+}
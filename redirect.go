@@ -0,0 +1,201 @@
+package lars
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrInvalidRedirectCode is returned by Redirect when the status code isn't a 3xx
+	// redirection code
+	ErrInvalidRedirectCode = errors.New("invalid redirect code")
+
+	// ErrRedirectNotAllowed is returned by Redirect when the URL's host isn't in the
+	// allowlist set using SetRedirectAllowlist
+	ErrRedirectNotAllowed = errors.New("redirect host not allowed")
+
+	// ErrRouteNotFound is returned by URL when no route has the name
+	ErrRouteNotFound = errors.New("route not found")
+
+	// ErrRouteParams is returned by URL when the number of params doesn't match the
+	// number of params in the route's path
+	ErrRouteParams = errors.New("wrong number of route params")
+)
+
+// Name names the route so it's URL can be built using URL and redirected to using
+// c.RedirectToRoute, the name must be unique.
+// NOTE: panics if another route has the same name
+func (r *Route) Name(name string) *Route {
+
+	l := r.lars
+
+	l.routesMu.Lock()
+	defer l.routesMu.Unlock()
+
+	if _, ok := l.namedRoutes[name]; ok {
+		panic("Route name '" + name + "' is already registered")
+	}
+
+	if l.namedRoutes == nil {
+		l.namedRoutes = make(map[string]*Route)
+	}
+
+	r.name = name
+	l.namedRoutes[name] = r
+
+	return r
+}
+
+// URL builds the path of the route with name, substituting params, in order, for the
+// route's URL params; the values are escaped, except for the slashes of a catch-all.
+// Trailing optional params may be omitted.
+//
+// i.e. for l.Get("/users/:id/files/*", h).Name("file") URL("file", "1", "a/b.txt")
+// returns "/users/1/files/a/b.txt"
+func (l *LARS) URL(name string, params ...string) (string, error) {
+
+	l.routesMu.RLock()
+	route, ok := l.namedRoutes[name]
+	l.routesMu.RUnlock()
+
+	if !ok {
+		return blank, ErrRouteNotFound
+	}
+
+	segments := strings.Split(route.path, basePath)
+	i := 0
+
+	for j, s := range segments {
+
+		if s == blank || (s[0] != paramByte && s[0] != wildByte) {
+			continue
+		}
+
+		if i == len(params) {
+
+			// optional params, the final segments, may be omitted
+			if s[len(s)-1] == '?' {
+				segments = segments[:j]
+				break
+			}
+
+			return blank, ErrRouteParams
+		}
+
+		if s[0] == wildByte {
+
+			parts := strings.Split(params[i], basePath)
+
+			for k := range parts {
+				parts[k] = url.PathEscape(parts[k])
+			}
+
+			segments[j] = strings.Join(parts, basePath)
+		} else {
+			segments[j] = url.PathEscape(params[i])
+		}
+
+		i++
+	}
+
+	if i != len(params) {
+		return blank, ErrRouteParams
+	}
+
+	if len(segments) == 1 && segments[0] == blank {
+		return basePath, nil
+	}
+
+	return strings.Join(segments, basePath), nil
+}
+
+// SetRedirectAllowlist sets the hosts, i.e. "example.com", c.Redirect may redirect to
+// guarding against open redirects; redirects to relative URLs are always allowed.
+// default nil, redirects to any host are allowed
+func (l *LARS) SetRedirectAllowlist(hosts ...string) {
+
+	if len(hosts) == 0 {
+		l.redirectAllowlist = nil
+		return
+	}
+
+	l.redirectAllowlist = make(map[string]struct{}, len(hosts))
+
+	for _, h := range hosts {
+		l.redirectAllowlist[strings.ToLower(h)] = struct{}{}
+	}
+}
+
+// Redirect redirects the request to url with the status code, which must be a 3xx
+// redirection code, setting the Location header; when an allowlist was set using
+// SetRedirectAllowlist the url's host must be in it.
+func (c *Ctx) Redirect(code int, url string) error {
+
+	if code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect {
+		return ErrInvalidRedirectCode
+	}
+
+	if !c.lars.redirectAllowed(url) {
+		return ErrRedirectNotAllowed
+	}
+
+	c.response.Header().Set(Location, url)
+	c.response.WriteHeader(code)
+
+	return nil
+}
+
+// RedirectToRoute redirects the request, with a 302 Found, to the route with name
+// built using URL with params.
+func (c *Ctx) RedirectToRoute(name string, params ...string) error {
+
+	u, err := c.lars.URL(name, params...)
+	if err != nil {
+		return err
+	}
+
+	return c.Redirect(http.StatusFound, u)
+}
+
+// redirectAllowed returns whether redirecting to rawurl is allowed by the allowlist
+func (l *LARS) redirectAllowed(rawurl string) bool {
+
+	if l.redirectAllowlist == nil {
+		return true
+	}
+
+	// browsers strip leading and trailing whitespace, and control characters, so
+	// " //evil.com" would be scheme relative
+	for i := 0; i < len(rawurl); i++ {
+		if rawurl[i] < ' ' || rawurl[i] == 0x7f {
+			return false
+		}
+	}
+
+	if strings.TrimSpace(rawurl) != rawurl {
+		return false
+	}
+
+	// browsers treat backslashes as slashes, "/\evil.com" is scheme relative
+	rawurl = strings.Replace(rawurl, "\\", basePath, -1)
+
+	// any number of leading slashes, "///evil.com", is scheme relative
+	if strings.HasPrefix(rawurl, "//") {
+		rawurl = "//" + strings.TrimLeft(rawurl, basePath)
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme == blank && u.Host == blank && !strings.HasPrefix(rawurl, "//") {
+		return true
+	}
+
+	_, ok := l.redirectAllowlist[strings.ToLower(u.Hostname())]
+
+	return ok
+}
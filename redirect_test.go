@@ -0,0 +1,135 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestURL(t *testing.T) {
+
+	l := New()
+	l.Get("/", basicHandler).Name("home")
+	l.Get("/users/:id{int}", basicHandler).Name("user")
+
+	g := l.Group("/users/:id")
+	g.Get("/files/*", basicHandler).Name("file")
+
+	u, err := l.URL("home")
+	Equal(t, err, nil)
+	Equal(t, u, "/")
+
+	u, err = l.URL("user", "1")
+	Equal(t, err, nil)
+	Equal(t, u, "/users/1")
+
+	u, err = l.URL("file", "a b", "dir/c?.txt")
+	Equal(t, err, nil)
+	Equal(t, u, "/users/a%20b/files/dir/c%3F.txt")
+
+	_, err = l.URL("missing")
+	Equal(t, err, ErrRouteNotFound)
+
+	_, err = l.URL("user")
+	Equal(t, err, ErrRouteParams)
+
+	_, err = l.URL("user", "1", "2")
+	Equal(t, err, ErrRouteParams)
+
+	Equal(t, l.Routes()[0].Name, "home")
+
+	PanicMatches(t, func() { l.Get("/other", basicHandler).Name("home") }, "Route name 'home' is already registered")
+}
+
+func TestContextRedirect(t *testing.T) {
+
+	l := New()
+	l.Get("/users/:id", basicHandler).Name("user")
+	l.Get("/redirect", func(c Context) error {
+		return c.Redirect(http.StatusSeeOther, c.QueryParam("to"))
+	})
+	l.Get("/invalid", func(c Context) error {
+		return c.Redirect(http.StatusOK, "/")
+	})
+	l.Get("/route", func(c Context) error {
+		return c.RedirectToRoute("user", "7")
+	})
+	l.Get("/missing-route", func(c Context) error {
+		return c.RedirectToRoute("missing")
+	})
+
+	redirect := func(to string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, "/redirect", nil)
+		r.URL.RawQuery = "to=" + to
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	w := redirect("https://evil.com/")
+	Equal(t, w.Code, http.StatusSeeOther)
+	Equal(t, w.Header().Get(Location), "https://evil.com/")
+
+	code, _ := request(GET, "/invalid", l)
+	Equal(t, code, http.StatusInternalServerError)
+
+	r, _ := http.NewRequest(GET, "/route", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusFound)
+	Equal(t, w.Header().Get(Location), "/users/7")
+
+	code, _ = request(GET, "/missing-route", l)
+	Equal(t, code, http.StatusInternalServerError)
+
+	l.SetRedirectAllowlist("example.com", "Sub.Example.com")
+
+	w = redirect("/local")
+	Equal(t, w.Code, http.StatusSeeOther)
+	Equal(t, w.Header().Get(Location), "/local")
+
+	w = redirect("https://sub.example.com:8443/path")
+	Equal(t, w.Code, http.StatusSeeOther)
+
+	w = redirect("https://example.com/")
+	Equal(t, w.Code, http.StatusSeeOther)
+
+	for _, to := range []string{"https://evil.com/", "//evil.com", "/%5Cevil.com", "javascript:alert(1)"} {
+		w = redirect(to)
+		Equal(t, w.Code, http.StatusInternalServerError)
+		Equal(t, w.Header().Get(Location), "")
+	}
+
+	// whitespace and control characters browsers strip, and any number of leading slashes
+	for _, to := range []string{" //evil.com/x", "\t//evil.com", "//evil.com/x ", "\x00//evil.com", "/\t/evil.com", "/\\evil.com", "\\/evil.com", "///evil.com", "/\\/evil.com", "https:///evil.com"} {
+		w = redirect(url.QueryEscape(to))
+		Equal(t, w.Code, http.StatusInternalServerError)
+		Equal(t, w.Header().Get(Location), "")
+	}
+
+	w = redirect(url.QueryEscape("/local/path?next=//evil.com"))
+	Equal(t, w.Code, http.StatusSeeOther)
+	Equal(t, w.Header().Get(Location), "/local/path?next=//evil.com")
+
+	w = redirect(url.QueryEscape("//example.com/x"))
+	Equal(t, w.Code, http.StatusSeeOther)
+
+	l.SetRedirectAllowlist()
+
+	w = redirect("https://evil.com/")
+	Equal(t, w.Code, http.StatusSeeOther)
+}
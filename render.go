@@ -0,0 +1,175 @@
+package lars
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Renderer renders the named template, with data, to w
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}, c Context) error
+}
+
+// HTMLRenderer is the default html/template based Renderer. Templates are looked
+// up by name, relative to Dir and without the extension, i.e. "users/show" renders
+// Dir/users/show.html; each is parsed once, together with the Layout and Partials,
+// and cached unless Reload is set.
+type HTMLRenderer struct {
+	// Dir is the directory containing the templates
+	Dir string
+
+	// Extension of the template files. default ".html"
+	Extension string
+
+	// Layout is the name of the template every page is rendered within, the layout
+	// renders the page using {{ template "content" . }} when the page defines it
+	// with {{ define "content" }}...{{ end }}. blank for none
+	Layout string
+
+	// Partials is the name of the directory, relative to Dir, whose templates are
+	// available to every page by their name i.e. {{ template "partials/nav" . }}
+	Partials string
+
+	// Funcs are the template functions available to every template
+	Funcs template.FuncMap
+
+	// Reload reparses the templates on every render so changes are picked up
+	// without a restart, intended for development only
+	Reload bool
+
+	mu    sync.RWMutex
+	cache map[string]*template.Template
+}
+
+var _ Renderer = new(HTMLRenderer)
+
+// Render renders the named template, within the Layout if set, with data to w.
+func (r *HTMLRenderer) Render(w io.Writer, name string, data interface{}, c Context) error {
+
+	t, err := r.template(name)
+	if err != nil {
+		return err
+	}
+
+	if r.Layout != blank {
+		return t.ExecuteTemplate(w, r.Layout, data)
+	}
+
+	return t.ExecuteTemplate(w, name, data)
+}
+
+// template returns the parsed template set for name, from the cache unless reloading
+func (r *HTMLRenderer) template(name string) (*template.Template, error) {
+
+	if !r.Reload {
+
+		r.mu.RLock()
+		t, ok := r.cache[name]
+		r.mu.RUnlock()
+
+		if ok {
+			return t, nil
+		}
+	}
+
+	t, err := r.parse(name)
+	if err != nil || r.Reload {
+		return t, err
+	}
+
+	r.mu.Lock()
+
+	if r.cache == nil {
+		r.cache = make(map[string]*template.Template)
+	}
+
+	r.cache[name] = t
+
+	r.mu.Unlock()
+
+	return t, nil
+}
+
+// parse parses the Layout, Partials and named page into a single template set,
+// each named by it's path relative to Dir without the extension.
+func (r *HTMLRenderer) parse(name string) (*template.Template, error) {
+
+	ext := r.Extension
+	if ext == blank {
+		ext = ".html"
+	}
+
+	var names []string
+
+	if r.Layout != blank {
+		names = append(names, r.Layout)
+	}
+
+	if r.Partials != blank {
+
+		partials, err := filepath.Glob(filepath.Join(r.Dir, r.Partials, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range partials {
+
+			if p, err = filepath.Rel(r.Dir, p); err != nil {
+				return nil, err
+			}
+
+			names = append(names, strings.TrimSuffix(filepath.ToSlash(p), ext))
+		}
+	}
+
+	names = append(names, name)
+
+	root := template.New(blank).Funcs(r.Funcs)
+
+	for _, n := range names {
+
+		b, err := ioutil.ReadFile(filepath.Join(r.Dir, filepath.FromSlash(n)+ext))
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = root.New(n).Parse(string(b)); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+// SetRenderer sets the Renderer used by c.Render, i.e. an *HTMLRenderer
+func (l *LARS) SetRenderer(r Renderer) {
+	l.renderer = r
+}
+
+// Render renders the named template, using the Renderer set using SetRenderer, with
+// data and writes it as HTML with status code. The template is rendered into a buffer
+// first so a failing template doesn't leave a partially written response.
+func (c *Ctx) Render(code int, name string, data interface{}) (err error) {
+
+	if c.lars.renderer == nil {
+		return ErrNoRenderer
+	}
+
+	buff := bufferPool.Get().(*bytes.Buffer)
+	buff.Reset()
+
+	defer bufferPool.Put(buff)
+
+	if err = c.lars.renderer.Render(buff, name, data, c.parent); err != nil {
+		return
+	}
+
+	c.response.Header().Set(ContentType, TextHTMLCharsetUTF8)
+
+	return c.writeBody(code, buff.Bytes())
+}
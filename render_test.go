@@ -0,0 +1,138 @@
+package lars
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestRender(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "lars-render")
+	Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	write := func(name, s string) {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		Equal(t, os.MkdirAll(filepath.Dir(name), 0755), nil)
+		Equal(t, ioutil.WriteFile(name, []byte(s), 0644), nil)
+	}
+
+	write("layouts/main.html", `<html>{{ template "partials/nav" . }}{{ template "content" . }}</html>`)
+	write("partials/nav.html", `<nav>{{ upper .Site }}</nav>`)
+	write("users/show.html", `{{ define "content" }}<h1>{{ .Name }}</h1>{{ end }}`)
+	write("broken.html", `{{ define "content" }}{{ .Missing.Field }}{{ end }}`)
+
+	r := &HTMLRenderer{
+		Dir:      dir,
+		Layout:   "layouts/main",
+		Partials: "partials",
+		Funcs:    template.FuncMap{"upper": strings.ToUpper},
+	}
+
+	l := New()
+	l.Get("/users/:name", func(c Context) error {
+		return c.Render(http.StatusOK, "users/show", map[string]string{"Site": "lars", "Name": "<" + c.Param("name") + ">"})
+	})
+	l.Get("/broken", func(c Context) error {
+		return c.Render(http.StatusOK, "broken", map[string]interface{}{"Missing": 1})
+	})
+	l.Get("/missing", func(c Context) error {
+		return c.Render(http.StatusOK, "missing", nil)
+	})
+
+	code, body := request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusInternalServerError)
+	Equal(t, body, `{"message":"Internal Server Error"}`)
+
+	l.SetRenderer(r)
+
+	code, body = request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "<html><nav>LARS</nav><h1>&lt;joeybloggs&gt;</h1></html>")
+
+	// a failing template doesn't write a partial response
+	code, body = request(GET, "/broken", l)
+	Equal(t, code, http.StatusInternalServerError)
+	Equal(t, body, `{"message":"Internal Server Error"}`)
+
+	code, _ = request(GET, "/missing", l)
+	Equal(t, code, http.StatusInternalServerError)
+
+	// cached unless reloading
+	write("users/show.html", `{{ define "content" }}<h2>{{ .Name }}</h2>{{ end }}`)
+
+	code, body = request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "<html><nav>LARS</nav><h1>&lt;joeybloggs&gt;</h1></html>")
+
+	r.Reload = true
+
+	code, body = request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "<html><nav>LARS</nav><h2>&lt;joeybloggs&gt;</h2></html>")
+
+	// without a layout the page itself is rendered
+	write("plain.tmpl", `<p>{{ . }}</p>`)
+
+	l.SetRenderer(&HTMLRenderer{Dir: dir, Extension: ".tmpl"})
+	l.Get("/plain", func(c Context) error {
+		return c.Render(http.StatusCreated, "plain", "hello")
+	})
+
+	code, body = request(GET, "/plain", l)
+	Equal(t, code, http.StatusCreated)
+	Equal(t, body, "<p>hello</p>")
+}
+
+func TestCustomRenderer(t *testing.T) {
+
+	var renderErr error
+
+	l := New()
+	l.SetRenderer(rendererFunc(func(w io.Writer, name string, data interface{}, c Context) error {
+		if name == "fail" {
+			return errors.New("render failed")
+		}
+		_, err := io.WriteString(w, name+" "+c.Param("id"))
+		return err
+	}))
+	l.Get("/:id", func(c Context) {
+		renderErr = c.Render(http.StatusOK, c.Param("id"), nil)
+	})
+
+	code, body := request(GET, "/page", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "page page")
+	Equal(t, renderErr, nil)
+
+	code, body = request(GET, "/fail", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "")
+	Equal(t, renderErr.Error(), "render failed")
+}
+
+type rendererFunc func(w io.Writer, name string, data interface{}, c Context) error
+
+func (fn rendererFunc) Render(w io.Writer, name string, data interface{}, c Context) error {
+	return fn(w, name, data, c)
+}
@@ -2,6 +2,7 @@ package lars
 
 import (
 	"bufio"
+	"bytes"
 	"io"
 	"log"
 	"net"
@@ -18,6 +19,15 @@ type Response struct {
 	size      int64
 	committed bool
 	context   Context
+
+	// buffer holds the body while the response is being buffered, see SetBufferedResponse
+	buffer    *bytes.Buffer
+	headerSet bool
+
+	// before and after are the hooks run before the header is written and once the
+	// request completes
+	before []func()
+	after  []func()
 }
 
 // newResponse creates a new Response for testing purposes
@@ -26,7 +36,10 @@ func newResponse(w http.ResponseWriter, context Context) *Response {
 }
 
 // SetWriter sets the provided writer as the new *Response http.ResponseWriter
+// NOTE: when buffering, anything already buffered is first committed to the
+// current writer, as it was written while that writer was in place.
 func (r *Response) SetWriter(w http.ResponseWriter) {
+	r.commitBuffer()
 	r.ResponseWriter = w
 }
 
@@ -52,10 +65,17 @@ func (r *Response) Header() http.Header {
 // Thus explicit calls to WriteHeader are mainly used to
 // send error codes.
 func (r *Response) WriteHeader(code int) {
+	if r.buffer != nil {
+		// not yet sent, a later call can still override it
+		r.status = code
+		r.headerSet = true
+		return
+	}
 	if r.committed {
-		log.Println("response already committed")
+		log.Printf("lars: superfluous WriteHeader call with %d, response already committed with %d", code, r.status)
 		return
 	}
+	r.beforeCommit()
 	r.status = code
 	r.ResponseWriter.WriteHeader(code)
 	r.committed = true
@@ -67,6 +87,16 @@ func (r *Response) WriteHeader(code int) {
 // Content-Type line, Write adds a Content-Type set to the result of passing
 // the initial 512 bytes of written data to DetectContentType.
 func (r *Response) Write(b []byte) (n int, err error) {
+	if r.buffer != nil {
+		n, err = r.buffer.Write(b)
+		r.size += int64(n)
+		return
+	}
+	if !r.committed {
+		// implicit WriteHeader(http.StatusOK) is left to the underlying writer
+		r.beforeCommit()
+		r.committed = true
+	}
 	n, err = r.ResponseWriter.Write(b)
 	r.size += int64(n)
 	return n, err
@@ -74,19 +104,51 @@ func (r *Response) Write(b []byte) (n int, err error) {
 
 // WriteString write string to ResponseWriter
 func (r *Response) WriteString(s string) (n int, err error) {
+	if r.buffer != nil {
+		n, err = r.buffer.WriteString(s)
+		r.size += int64(n)
+		return
+	}
+	if !r.committed {
+		r.beforeCommit()
+		r.committed = true
+	}
 	n, err = io.WriteString(r.ResponseWriter, s)
 	r.size += int64(n)
 	return
 }
 
 // Flush wraps response writer's Flush function.
+// NOTE: when buffering, the buffered response is committed and
+// everything written afterwards is sent directly.
 func (r *Response) Flush() {
+	r.commitBuffer()
 	r.ResponseWriter.(http.Flusher).Flush()
 }
 
-// Hijack wraps response writer's Hijack function.
+// Hijack wraps response writer's Hijack function, letting the handler take over the
+// connection; anything buffered is discarded and the response is considered committed.
+// http.ErrNotSupported is returned when the underlying writer doesn't support it.
 func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return r.ResponseWriter.(http.Hijacker).Hijack()
+
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.buffer != nil {
+		bufferPool.Put(r.buffer)
+		r.buffer = nil
+	}
+
+	r.committed = true
+
+	return conn, rw, nil
 }
 
 // CloseNotify wraps response writer's CloseNotify function.
@@ -94,6 +156,17 @@ func (r *Response) CloseNotify() <-chan bool {
 	return r.ResponseWriter.(http.CloseNotifier).CloseNotify()
 }
 
+// Push wraps response writer's Push function, initiating an HTTP/2 server push;
+// http.ErrNotSupported is returned when the connection doesn't support it.
+func (r *Response) Push(target string, opts *http.PushOptions) error {
+
+	if p, ok := r.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
 // Status returns the *Response's current http status code.
 func (r *Response) Status() int {
 	return r.status
@@ -106,13 +179,150 @@ func (r *Response) Size() int64 {
 
 // Committed returns whether the *Response header has already been written to
 // and if has been committed to this return.
+// NOTE: a buffered response isn't committed until the buffer is flushed
 func (r *Response) Committed() bool {
 	return r.committed
 }
 
+// written returns whether the status or any of the body has been
+// written, whether or not it's been committed.
+func (r *Response) written() bool {
+	return r.committed || r.headerSet || (r.buffer != nil && r.buffer.Len() > 0)
+}
+
+// startBuffering buffers the response until it's flushed or the request completes
+func (r *Response) startBuffering() {
+	r.buffer = bufferPool.Get().(*bytes.Buffer)
+	r.buffer.Reset()
+}
+
+// commitBuffer writes the buffered status and body, if any were written, to the
+// underlying writer; afterwards writes are no longer buffered.
+func (r *Response) commitBuffer() {
+
+	if r.buffer == nil || (!r.headerSet && r.buffer.Len() == 0) {
+		return
+	}
+
+	buff := r.buffer
+	r.buffer = nil
+
+	r.beforeCommit()
+	r.ResponseWriter.WriteHeader(r.status)
+	r.committed = true
+	r.ResponseWriter.Write(buff.Bytes())
+
+	bufferPool.Put(buff)
+}
+
+// endBuffering commits anything still buffered once the request completes.
+func (r *Response) endBuffering() {
+
+	r.commitBuffer()
+
+	if r.buffer != nil {
+		bufferPool.Put(r.buffer)
+		r.buffer = nil
+	}
+}
+
+// Reset discards the buffered status and partial body so a clean response, such as an
+// error, can be written in it's place; headers set by the handler are kept. Returns
+// false, discarding nothing, when the response isn't buffered, see SetBufferedResponse,
+// or has already been committed i.e. by Flush.
+func (r *Response) Reset() bool {
+
+	if r.buffer == nil {
+		return false
+	}
+
+	r.buffer.Reset()
+	r.size = 0
+	r.status = http.StatusOK
+	r.headerSet = false
+	r.Header().Del(ContentLength)
+
+	return true
+}
+
+// Before registers a hook run immediately before the header is written, in the order
+// they were registered, so middleware can set headers, such as Server-Timing or
+// cookies, at the last safe moment; hooks aren't run when nothing is written.
+func (r *Response) Before(fn func()) {
+	r.before = append(r.before, fn)
+}
+
+// After registers a hook run once the request's handlers have completed and the
+// response has been written, in the order they were registered, i.e. to record the
+// final status and size.
+func (r *Response) After(fn func()) {
+	r.after = append(r.after, fn)
+}
+
+// runAfter runs the After hooks
+func (r *Response) runAfter() {
+	for _, fn := range r.after {
+		fn()
+	}
+}
+
+// beforeCommit is run just before the header is written, it runs the Before hooks,
+// saves the session and applies the default response headers registered on the LARS
+// instance.
+func (r *Response) beforeCommit() {
+
+	// cleared as they're run so they're only run once
+	for i, fn := range r.before {
+		r.before[i] = nil
+		fn()
+	}
+
+	r.before = r.before[:0]
+
+	if r.context == nil {
+		return
+	}
+
+	c := r.context.BaseContext()
+	c.saveSession()
+
+	l := c.lars
+	if l == nil || len(l.responseHeaders) == 0 {
+		return
+	}
+
+	h := r.Header()
+
+	for k, v := range l.responseHeaders {
+
+		if v == blank {
+			h.Del(k)
+			continue
+		}
+
+		if _, ok := h[http.CanonicalHeaderKey(k)]; !ok {
+			h.Set(k, v)
+		}
+	}
+}
+
 func (r *Response) reset(w http.ResponseWriter) {
 	r.ResponseWriter = w
 	r.size = 0
 	r.status = http.StatusOK
 	r.committed = false
+	r.buffer = nil
+	r.headerSet = false
+
+	// cleared so the previous request's hooks can be garbage collected
+	for i := range r.before {
+		r.before[i] = nil
+	}
+
+	for i := range r.after {
+		r.after[i] = nil
+	}
+
+	r.before = r.before[:0]
+	r.after = r.after[:0]
 }
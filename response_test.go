@@ -1,8 +1,14 @@
 package lars
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strconv"
 	"testing"
 
 	. "gopkg.in/go-playground/assert.v1"
@@ -58,7 +64,7 @@ func TestResponse(t *testing.T) {
 	r.Flush()
 
 	// Size
-	IsEqual(len(info), r.Size())
+	Equal(t, r.Size(), int64(len(info)))
 
 	// WriteString
 	s := "lars"
@@ -69,17 +75,11 @@ func TestResponse(t *testing.T) {
 	//committed
 	Equal(t, true, r.Committed())
 
-	panicStr := "interface conversion: *httptest.ResponseRecorder is not http.Hijacker: missing method Hijack"
-	fnPanic := func() {
-		_, _, err := r.Hijack()
-		if err != nil {
-			panic(err)
-		}
-	}
-	PanicMatches(t, fnPanic, panicStr)
+	_, _, err = r.Hijack()
+	Equal(t, err, http.ErrNotSupported)
 
-	panicStr = "interface conversion: *httptest.ResponseRecorder is not http.CloseNotifier: missing method CloseNotify"
-	fnPanic = func() {
+	panicStr := "interface conversion: *httptest.ResponseRecorder is not http.CloseNotifier: missing method CloseNotify"
+	fnPanic := func() {
 		r.CloseNotify()
 	}
 	PanicMatches(t, fnPanic, panicStr)
@@ -87,3 +87,485 @@ func TestResponse(t *testing.T) {
 	// reset
 	r.reset(httptest.NewRecorder())
 }
+
+func TestResponseHeaders(t *testing.T) {
+
+	l := New()
+	l.SetResponseHeaders(map[string]string{
+		"Server":          "lars",
+		"X-Powered-By":    "",
+		"X-Frame-Options": "DENY",
+	})
+
+	l.Get("/default", func(c Context) {
+		c.Response().Header().Set("X-Powered-By", "PHP")
+		c.Text(http.StatusOK, "default")
+	})
+
+	l.Get("/override", func(c Context) {
+		c.Response().Header().Set("Server", "custom")
+		c.Response().Write([]byte("override"))
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(GET, "/default", nil)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get("Server"), "lars")
+	Equal(t, w.Header().Get("X-Frame-Options"), "DENY")
+	Equal(t, w.Header().Get("X-Powered-By"), "")
+
+	r, _ = http.NewRequest(GET, "/override", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "override")
+	Equal(t, w.Header().Get("Server"), "custom")
+	Equal(t, w.Header().Get("X-Frame-Options"), "DENY")
+
+	r, _ = http.NewRequest(GET, "/notfound", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Header().Get("Server"), "lars")
+}
+
+func TestResponseStatusAndSize(t *testing.T) {
+
+	type result struct {
+		status    int
+		size      int64
+		committed bool
+	}
+
+	var res result
+
+	l := New()
+	l.Use(func(c Context) {
+		c.Next()
+		res = result{c.Response().Status(), c.Response().Size(), c.Response().Committed()}
+	})
+	l.Get("/write", func(c Context) {
+		c.Response().Write([]byte("test"))
+		c.Response().WriteString("ing")
+	})
+	l.Get("/error", func(c Context) {
+		http.Error(c.Response(), "not here", http.StatusNotFound)
+	})
+	l.Get("/json", func(c Context) {
+		c.JSON(http.StatusCreated, "ok")
+	})
+	l.Get("/nothing", func(c Context) {
+	})
+
+	code, _ := request(GET, "/write", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, res, result{http.StatusOK, 7, true})
+
+	code, _ = request(GET, "/error", l)
+	Equal(t, code, http.StatusNotFound)
+	Equal(t, res, result{http.StatusNotFound, 9, true})
+
+	code, _ = request(GET, "/json", l)
+	Equal(t, code, http.StatusCreated)
+	Equal(t, res, result{http.StatusCreated, 4, true})
+
+	code, _ = request(GET, "/nothing", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, res, result{http.StatusOK, 0, false})
+
+	buff := new(bytes.Buffer)
+	log.SetOutput(buff)
+	defer log.SetOutput(os.Stderr)
+
+	l.Get("/twice", func(c Context) {
+		c.Response().WriteHeader(http.StatusAccepted)
+		c.Response().WriteHeader(http.StatusInternalServerError)
+	})
+
+	code, _ = request(GET, "/twice", l)
+	Equal(t, code, http.StatusAccepted)
+	Equal(t, res, result{http.StatusAccepted, 0, true})
+	MatchRegex(t, buff.String(), "lars: superfluous WriteHeader call with 500, response already committed with 202\n$")
+}
+
+// reader returns an io.Reader of b that isn't an io.ReadSeeker,
+// those are served using ServeContent
+func reader(b []byte) io.Reader {
+	return struct{ io.Reader }{bytes.NewReader(b)}
+}
+
+func TestStreamThreshold(t *testing.T) {
+
+	// large must exceed the http.Server's own response buffer, otherwise
+	// it would set the Content-Length itself
+	small := bytes.Repeat([]byte("a"), 10)
+	large := bytes.Repeat([]byte("a"), 8192)
+
+	l := New()
+	l.SetStreamThreshold(4096)
+	l.Get("/small", func(c Context) {
+		c.TextBytes(http.StatusOK, small)
+	})
+	l.Get("/large", func(c Context) {
+		c.TextBytes(http.StatusOK, large)
+	})
+	l.Get("/jsonp", func(c Context) {
+		c.JSONP(http.StatusOK, 1, "cb")
+	})
+	l.Get("/encoded", func(c Context) {
+		c.Response().Header().Set(ContentEncoding, Gzip)
+		c.TextBytes(http.StatusOK, small)
+	})
+	l.Get("/attachment-small", func(c Context) {
+		c.Attachment(reader(small), "small.txt")
+	})
+	l.Get("/attachment-exact", func(c Context) {
+		c.Attachment(reader(large[:4096]), "exact.txt")
+	})
+	l.Get("/attachment-large", func(c Context) {
+		c.Inline(reader(large), "large.txt")
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	tests := []struct {
+		path          string
+		contentLength int64
+		body          string
+	}{
+		{path: "/small", contentLength: 10, body: string(small)},
+		{path: "/large", contentLength: -1, body: string(large)},
+		{path: "/jsonp", contentLength: 6, body: "cb(1);"},
+		{path: "/attachment-small", contentLength: 10, body: string(small)},
+		{path: "/attachment-exact", contentLength: 4096, body: string(large[:4096])},
+		{path: "/attachment-large", contentLength: -1, body: string(large)},
+	}
+
+	for _, tt := range tests {
+
+		resp, err := http.Get(server.URL + tt.path)
+		Equal(t, err, nil)
+		Equal(t, resp.StatusCode, http.StatusOK)
+		Equal(t, resp.ContentLength, tt.contentLength)
+
+		if tt.contentLength == -1 {
+			Equal(t, resp.TransferEncoding, []string{"chunked"})
+		}
+
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		Equal(t, err, nil)
+		Equal(t, string(b), tt.body)
+	}
+
+	r, _ := http.NewRequest(GET, "/encoded", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Header().Get(ContentLength), "")
+
+	l.SetStreamThreshold(0)
+
+	r, _ = http.NewRequest(GET, "/small", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Header().Get(ContentLength), "")
+	Equal(t, w.Body.String(), string(small))
+
+	r, _ = http.NewRequest(GET, "/attachment-small", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Header().Get(ContentLength), "")
+	Equal(t, w.Body.String(), string(small))
+}
+
+func benchmarkResponse(b *testing.B, size int) {
+
+	body := bytes.Repeat([]byte("a"), size)
+
+	l := New()
+	l.Get("/bytes", func(c Context) {
+		c.TextBytes(http.StatusOK, body)
+	})
+	l.Get("/reader", func(c Context) {
+		c.Attachment(reader(body), "file.txt")
+	})
+
+	hf := l.Serve()
+
+	b.Run("Bytes", func(b *testing.B) {
+
+		r, _ := http.NewRequest(GET, "/bytes", nil)
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			hf.ServeHTTP(httptest.NewRecorder(), r)
+		}
+	})
+
+	b.Run("Reader", func(b *testing.B) {
+
+		r, _ := http.NewRequest(GET, "/reader", nil)
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			hf.ServeHTTP(httptest.NewRecorder(), r)
+		}
+	})
+}
+
+func BenchmarkSmallResponse(b *testing.B) {
+	benchmarkResponse(b, 1<<10) // 1 KB, written with a Content-Length
+}
+
+func BenchmarkLargeResponse(b *testing.B) {
+	benchmarkResponse(b, 1<<20) // 1 MB, streamed
+}
+
+func TestBufferedResponse(t *testing.T) {
+
+	l := New()
+	l.SetBufferedResponse(true)
+	l.Use(func(c Context) {
+		c.Next()
+
+		// the handler has "written" but the status can still be changed
+		Equal(t, c.Response().Committed(), false)
+
+		switch c.Request().URL.Path {
+		case "/override":
+			c.Response().WriteHeader(http.StatusAccepted)
+
+		case "/reset":
+			// replace the partial body with an error page
+			Equal(t, c.Response().Status(), http.StatusInternalServerError)
+			Equal(t, c.Response().Reset(), true)
+			Equal(t, c.Response().Size(), int64(0))
+			c.Text(http.StatusServiceUnavailable, "maintenance")
+		}
+	})
+	l.Get("/reset", func(c Context) {
+		c.Response().WriteHeader(http.StatusInternalServerError)
+		c.Response().Write([]byte("partial"))
+	})
+	l.Get("/ok", func(c Context) {
+		c.Response().Header().Set("X-Test", "1")
+		c.Text(http.StatusCreated, "created")
+	})
+	l.Get("/override", func(c Context) {
+		c.Text(http.StatusOK, "ok")
+	})
+	l.Get("/error", func(c Context) error {
+		c.Text(http.StatusOK, "partial")
+		return NewHTTPError(http.StatusBadRequest, "bad")
+	})
+	l.Get("/nothing", func(c Context) {
+	})
+	l.Get("/native", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("native"))
+	}, func(c Context) {
+		panic("chain continued after native handler wrote a response")
+	})
+
+	code, body := request(GET, "/ok", l)
+	Equal(t, code, http.StatusCreated)
+	Equal(t, body, "created")
+
+	r, _ := http.NewRequest(GET, "/ok", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Header().Get("X-Test"), "1")
+	Equal(t, w.Header().Get(ContentLength), "7")
+
+	code, body = request(GET, "/override", l)
+	Equal(t, code, http.StatusAccepted)
+	Equal(t, body, "ok")
+
+	code, body = request(GET, "/error", l)
+	Equal(t, code, http.StatusBadRequest)
+	Equal(t, body, `{"message":"bad"}`)
+
+	code, body = request(GET, "/nothing", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "")
+
+	code, body = request(GET, "/reset", l)
+	Equal(t, code, http.StatusServiceUnavailable)
+	Equal(t, body, "maintenance")
+
+	// nothing to discard when not buffering
+	Equal(t, newResponse(httptest.NewRecorder(), nil).Reset(), false)
+
+	code, body = request(GET, "/native", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "native")
+}
+
+func TestBufferedResponseStream(t *testing.T) {
+
+	var committed []bool
+
+	l := New()
+	l.SetBufferedResponse(true)
+	l.Get("/stream", func(c Context) {
+
+		c.Response().WriteHeader(http.StatusTeapot)
+		committed = append(committed, c.Response().Committed())
+
+		count := 0
+
+		c.Stream(func(w io.Writer) bool {
+			w.Write([]byte("a"))
+			committed = append(committed, c.Response().Committed())
+			count++
+			return count != 3
+		})
+	})
+
+	code, body := request(GET, "/stream", l)
+	Equal(t, code, http.StatusTeapot)
+	Equal(t, body, "aaa")
+	Equal(t, committed, []bool{false, false, true, true})
+
+	// buffers aren't carried over once buffering is turned off
+	l.SetBufferedResponse(false)
+
+	committed = nil
+
+	code, body = request(GET, "/stream", l)
+	Equal(t, code, http.StatusTeapot)
+	Equal(t, body, "aaa")
+	Equal(t, committed, []bool{true, true, true, true})
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target+" "+opts.Header.Get(AcceptEncoding))
+	return nil
+}
+
+func TestPush(t *testing.T) {
+
+	l := New()
+	l.Get("/", func(c Context) error {
+
+		if err := c.Push("/app.css", &http.PushOptions{Header: http.Header{AcceptEncoding: []string{Gzip}}}); err != nil {
+			return err
+		}
+
+		if err := c.Push("/app.js", &http.PushOptions{}); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, "page")
+	})
+
+	// HTTP/1.1, pushing is a no-op
+	code, body := request(GET, "/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "page")
+
+	r, _ := http.NewRequest(GET, "/", nil)
+	w := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.pushed, []string{"/app.css gzip", "/app.js "})
+
+	Equal(t, newResponse(httptest.NewRecorder(), nil).Push("/app.js", nil), http.ErrNotSupported)
+}
+
+func TestResponseHooks(t *testing.T) {
+
+	var events []string
+
+	l := New()
+	l.Use(func(c Context) {
+
+		res := c.Response()
+
+		res.Before(func() {
+			events = append(events, "before")
+			res.Header().Set("Server-Timing", "app;dur=1")
+		})
+		res.After(func() {
+			events = append(events, "after "+strconv.Itoa(res.Status())+" "+strconv.FormatInt(res.Size(), 10))
+		})
+
+		c.Next()
+	})
+	l.Get("/", func(c Context) {
+		c.Text(http.StatusCreated, "created")
+		c.Response().Write([]byte("!"))
+		events = append(events, "handler")
+	})
+	l.Get("/nothing", basicHandler)
+
+	r, _ := http.NewRequest(GET, "/", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "created!")
+	Equal(t, w.Header().Get("Server-Timing"), "app;dur=1")
+	Equal(t, events, []string{"before", "handler", "after 201 8"})
+
+	// hooks aren't carried over to the next request and Before isn't run without writes
+	events = nil
+
+	code, _ := request(GET, "/nothing", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, events, []string{"after 200 0"})
+
+	// buffered responses run Before when the buffer is committed
+	l.SetBufferedResponse(true)
+	events = nil
+
+	r, _ = http.NewRequest(GET, "/", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Header().Get("Server-Timing"), "app;dur=1")
+	Equal(t, events, []string{"handler", "before", "after 201 8"})
+}
+
+func TestHijack(t *testing.T) {
+
+	l := New()
+	l.SetBufferedResponse(true)
+	l.Use(func(c Context) {
+		c.Next()
+		Equal(t, c.Response().Committed(), true)
+	})
+	l.Get("/", func(c Context) {
+
+		c.Response().Header().Set("X-Test", "discarded")
+		c.Response().WriteString("discarded")
+
+		conn, rw, err := c.Response().Hijack()
+		Equal(t, err, nil)
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		rw.Flush()
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	Equal(t, err, nil)
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	Equal(t, err, nil)
+	Equal(t, resp.StatusCode, http.StatusOK)
+	Equal(t, resp.Header.Get("X-Test"), "")
+	Equal(t, string(b), "hijacked")
+}
@@ -0,0 +1,245 @@
+package lars
+
+import (
+	"sort"
+	"strings"
+)
+
+// Route contains the information of a single registered route and
+// allows for additional route specific configuration.
+type Route struct {
+	lars        *LARS
+	name        string
+	method      string
+	host        string
+	path        string
+	handlerName string
+	chainNames  []string
+	silent      bool
+	// maxBodySize overrides the maximum request body size when > 0
+	maxBodySize int64
+	catchAll    bool
+	// constraints are the param constraints the route only matches when satisfied
+	constraints []paramConstraint
+	// documentation is the route's OpenAPI documentation, nil when there's none
+	documentation *routeDoc
+	// meta is the route's metadata set using Meta, nil when there's none
+	meta map[string]interface{}
+}
+
+// RouteInfo describes a single registered route, including the names of
+// all the middleware and handlers in it's chain in the order they're run.
+type RouteInfo struct {
+	Name        string
+	Method      string
+	Host        string
+	Path        string
+	HandlerName string
+	Handlers    []string
+	// Middleware is the number of middleware run before the route's handler
+	Middleware int
+	// Meta is the route's metadata set using Meta, nil when there's none
+	Meta map[string]interface{}
+}
+
+// Method returns the HTTP method the route was registered for.
+func (r *Route) Method() string {
+	return r.method
+}
+
+// Host returns the host pattern the route was registered for using Host,
+// blank when registered for all hosts.
+func (r *Route) Host() string {
+	return r.host
+}
+
+// Path returns the full path the route was registered with, including
+// any group prefix.
+func (r *Route) Path() string {
+	return r.path
+}
+
+// HandlerName returns the name of the route's final handler.
+func (r *Route) HandlerName() string {
+	return r.handlerName
+}
+
+// Silent flags the route to be skipped by access logging middleware,
+// useful for noisy endpoints such as health checks and metrics scrapes.
+func (r *Route) Silent() *Route {
+	return r.set(func() { r.silent = true })
+}
+
+// IsSilent returns whether the route was flagged using Silent().
+func (r *Route) IsSilent() bool {
+
+	r.lars.routesMu.RLock()
+	defer r.lars.routesMu.RUnlock()
+
+	return r.silent
+}
+
+// BodyLimit sets the maximum size, in bytes, of the route's request bodies, replacing
+// the limit set using SetMaxRequestBodySize for the route i.e. a larger limit for an
+// upload endpoint; reading beyond it fails with ErrRequestEntityTooLarge.
+func (r *Route) BodyLimit(n int64) *Route {
+	return r.set(func() { r.maxBodySize = n })
+}
+
+// set runs fn, modifying the route, holding the routes lock as the route is already
+// registered and may be serving requests; used by all of the route's setters.
+func (r *Route) set(fn func()) *Route {
+
+	r.lars.routesMu.Lock()
+	fn()
+	r.lars.routesMu.Unlock()
+
+	return r
+}
+
+// Meta attaches the metadata key with value to the route, available to middleware
+// during the request using c.RouteMeta, so generic middleware such as authorization
+// or rate limiting can change behaviour based on the matched route.
+//
+//	l.Delete("/users/:id", deleteUser).Meta("auth", "admin")
+func (r *Route) Meta(key string, value interface{}) *Route {
+	return r.set(func() {
+
+		// a copy is published, never modifying the map requests may be reading
+		meta := copyMeta(r.meta)
+
+		if meta == nil {
+			meta = make(map[string]interface{})
+		}
+
+		meta[key] = value
+		r.meta = meta
+	})
+}
+
+// MetaValue returns the metadata value for key set using Meta and whether it exists
+func (r *Route) MetaValue(key string) (value interface{}, exists bool) {
+
+	r.lars.routesMu.RLock()
+	meta := r.meta
+	r.lars.routesMu.RUnlock()
+
+	value, exists = meta[key]
+	return
+}
+
+// optionalPaths returns the paths to register for path, which may end with optional
+// params i.e. /articles/:year/:month?/:day? returns /articles/:year,
+// /articles/:year/:month and /articles/:year/:month/:day; otherwise just path.
+func optionalPaths(path string) []string {
+
+	segments := strings.Split(path, basePath)
+	first := -1
+
+	for i, s := range segments {
+
+		if len(s) > 1 && s[0] == paramByte && s[len(s)-1] == '?' {
+
+			if first == -1 {
+				first = i
+			}
+
+			segments[i] = s[:len(s)-1]
+			continue
+		}
+
+		if first != -1 {
+			panic("Optional params must be the final segments, path '" + path + "'")
+		}
+	}
+
+	if first == -1 {
+		return []string{path}
+	}
+
+	paths := make([]string, 0, len(segments)-first+1)
+
+	for i := first; i <= len(segments); i++ {
+
+		p := strings.Join(segments[:i], basePath)
+		if p == blank {
+			p = basePath
+		}
+
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+// Routes returns information about every registered route, including it's full
+// handler chain, sorted by host, path and then method; useful for logging the routes
+// at startup, verifying middleware order and generating route documentation.
+func (l *LARS) Routes() []RouteInfo {
+
+	var routes []RouteInfo
+
+	l.routesMu.RLock()
+	defer l.routesMu.RUnlock()
+
+	add := func(route *Route) {
+		routes = append(routes, RouteInfo{
+			Name:        route.name,
+			Method:      route.method,
+			Host:        route.host,
+			Path:        route.path,
+			HandlerName: route.handlerName,
+			Handlers:    append([]string(nil), route.chainNames...),
+			Middleware:  len(route.chainNames) - 1,
+			Meta:        copyMeta(route.meta),
+		})
+	}
+
+	for _, tree := range l.trees {
+		tree.walk(add)
+	}
+
+	for _, h := range l.hosts {
+		for _, tree := range h.trees {
+			tree.walk(add)
+		}
+	}
+
+	sort.Sort(routeInfos(routes))
+
+	return routes
+}
+
+// copyMeta returns a copy of the route's metadata, so it can't be modified
+// through the RouteInfo, or nil when there's none
+func copyMeta(meta map[string]interface{}) map[string]interface{} {
+
+	if meta == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{}, len(meta))
+
+	for k, v := range meta {
+		m[k] = v
+	}
+
+	return m
+}
+
+type routeInfos []RouteInfo
+
+func (r routeInfos) Len() int      { return len(r) }
+func (r routeInfos) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r routeInfos) Less(i, j int) bool {
+
+	if r[i].Host != r[j].Host {
+		return r[i].Host < r[j].Host
+	}
+
+	if r[i].Path == r[j].Path {
+		return r[i].Method < r[j].Method
+	}
+
+	return r[i].Path < r[j].Path
+}
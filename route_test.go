@@ -0,0 +1,326 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestRoute(t *testing.T) {
+
+	var logged []string
+
+	logger := func(c Context) {
+		c.Next()
+
+		if route := c.Route(); route != nil && route.IsSilent() {
+			return
+		}
+
+		logged = append(logged, c.Request().URL.Path)
+	}
+
+	l := New()
+	l.Use(logger)
+
+	r := l.Get("/health", HandlerForName).Silent()
+	Equal(t, r.IsSilent(), true)
+	Equal(t, r.Method(), GET)
+	Equal(t, r.Path(), "/health")
+	MatchRegex(t, r.HandlerName(), "^(.*/vendor/)?github.com/go-playground/lars.HandlerForName$")
+
+	g := l.Group("/users")
+	r = g.Post("", basicHandler)
+	Equal(t, r.IsSilent(), false)
+	Equal(t, r.Method(), POST)
+	Equal(t, r.Path(), "/users")
+
+	r = l.Get("", basicHandler)
+	Equal(t, r.Path(), "/")
+
+	code, _ := request(GET, "/health", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(POST, "/users", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/notfound", l)
+	Equal(t, code, http.StatusNotFound)
+
+	Equal(t, logged, []string{"/users", "/notfound"})
+}
+
+func routeMiddleware1(c Context) { c.Next() }
+func routeMiddleware2(c Context) { c.Next() }
+func routeMiddleware3(c Context) { c.Next() }
+
+func TestRoutes(t *testing.T) {
+
+	l := New()
+	l.Use(routeMiddleware1)
+	l.Get("/home", HandlerForName)
+
+	users := l.Group("/users", routeMiddleware2)
+	users.Post("", routeMiddleware3, basicHandler)
+
+	admin := users.Group("/admin", nil)
+	admin.Delete("/:id", basicHandler).Meta("auth", "admin")
+
+	routes := l.Routes()
+	Equal(t, len(routes), 3)
+
+	Equal(t, routes[0].Method, GET)
+	Equal(t, routes[0].Path, "/home")
+	MatchRegex(t, routes[0].HandlerName, "lars.HandlerForName$")
+	Equal(t, len(routes[0].Handlers), 2)
+	Equal(t, routes[0].Middleware, 1)
+	MatchRegex(t, routes[0].Handlers[0], "lars.routeMiddleware1$")
+	MatchRegex(t, routes[0].Handlers[1], "lars.HandlerForName$")
+
+	Equal(t, routes[1].Method, POST)
+	Equal(t, routes[1].Path, "/users")
+	MatchRegex(t, routes[1].HandlerName, "lars.(init|glob.).func[0-9]+$")
+	Equal(t, len(routes[1].Handlers), 4)
+	Equal(t, routes[1].Middleware, 3)
+	MatchRegex(t, routes[1].Handlers[0], "lars.routeMiddleware1$")
+	MatchRegex(t, routes[1].Handlers[1], "lars.routeMiddleware2$")
+	MatchRegex(t, routes[1].Handlers[2], "lars.routeMiddleware3$")
+	Equal(t, routes[1].Handlers[3], routes[1].HandlerName)
+
+	Equal(t, routes[2].Method, DELETE)
+	Equal(t, routes[2].Path, "/users/admin/:id")
+	Equal(t, len(routes[2].Handlers), 1)
+	Equal(t, routes[2].Middleware, 0)
+	Equal(t, routes[2].Meta, map[string]interface{}{"auth": "admin"})
+	Equal(t, routes[0].Meta == nil, true)
+
+	// modifying the returned info doesn't affect the registered route
+	routes[0].Handlers[0] = "changed"
+	routes[2].Meta["auth"] = "changed"
+	MatchRegex(t, l.Routes()[0].Handlers[0], "lars.routeMiddleware1$")
+	Equal(t, l.Routes()[2].Meta["auth"], "admin")
+}
+
+func TestRouteMeta(t *testing.T) {
+
+	authz := func(c Context) {
+
+		if role, ok := c.RouteMeta("auth"); ok && c.Request().Header.Get("X-Role") != role {
+			c.Response().WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		c.Next()
+	}
+
+	l := New()
+	l.Use(authz)
+
+	r := l.Delete("/users/:id", basicHandler).Meta("auth", "admin").Meta("rate", 10)
+	l.Get("/users/:id", basicHandler)
+
+	v, ok := r.MetaValue("rate")
+	Equal(t, ok, true)
+	Equal(t, v, 10)
+
+	_, ok = r.MetaValue("missing")
+	Equal(t, ok, false)
+
+	code, _ := request(GET, "/users/7", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(DELETE, "/users/7", l)
+	Equal(t, code, http.StatusForbidden)
+
+	hf := l.Serve()
+
+	req, _ := http.NewRequest(DELETE, "/users/7", nil)
+	req.Header.Set("X-Role", "admin")
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, req)
+	Equal(t, w.Code, http.StatusOK)
+
+	// no route matched
+	code, _ = request(GET, "/missing", l)
+	Equal(t, code, http.StatusNotFound)
+
+	c := NewContext(l)
+	_, ok = c.RouteMeta("auth")
+	Equal(t, ok, false)
+
+	// metadata added while the route is serving requests
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			r.Meta("key"+strconv.Itoa(i), i)
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(DELETE, "/users/7", nil)
+			hf.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+
+	wg.Wait()
+
+	v, ok = r.MetaValue("key9")
+	Equal(t, ok, true)
+	Equal(t, v, 9)
+
+	v, _ = r.MetaValue("auth")
+	Equal(t, v, "admin")
+}
+
+func TestRemoveAndRegisterWhileServing(t *testing.T) {
+
+	l := New()
+	l.Get("/users/:id", basicHandler).Name("user")
+	l.Get(`/files/:id(\d+)`, basicHandler)
+	l.Get("/files/:id", func(c Context) {
+		c.Text(http.StatusOK, "any")
+	})
+
+	tenants := l.Group("/tenants")
+
+	hf := l.Serve()
+
+	serve := func(method, path string) (int, string) {
+		r, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w.Code, w.Body.String()
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			tenants.Get("/"+strconv.Itoa(i)+"/:a/:b/:c/:d", basicHandler)
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			serve(GET, "/users/1")
+		}()
+	}
+
+	wg.Wait()
+
+	code, _ := serve(GET, "/tenants/9/a/b/c/d")
+	Equal(t, code, http.StatusOK)
+
+	Equal(t, tenants.Remove(GET, "/9/:a/:b/:c/:d"), true)
+	Equal(t, tenants.Remove(GET, "/9/:a/:b/:c/:d"), false)
+
+	code, _ = serve(GET, "/tenants/9/a/b/c/d")
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = serve(GET, "/tenants/8/a/b/c/d")
+	Equal(t, code, http.StatusOK)
+
+	// the route can be registered again
+	tenants.Get("/9/:a/:b/:c/:d", basicHandler)
+
+	code, _ = serve(GET, "/tenants/9/a/b/c/d")
+	Equal(t, code, http.StatusOK)
+
+	// named routes are removed
+	Equal(t, l.Remove(GET, "/users/:id"), true)
+
+	code, _ = serve(GET, "/users/1")
+	Equal(t, code, http.StatusNotFound)
+
+	_, err := l.URL("user", "1")
+	Equal(t, err, ErrRouteNotFound)
+
+	l.Get("/users/:id", basicHandler).Name("user")
+
+	// constrained routes are removed separately
+	code, body := serve(GET, "/files/1")
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "")
+
+	Equal(t, l.Remove(GET, `/files/:id(\d+)`), true)
+
+	code, body = serve(GET, "/files/1")
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "any")
+
+	Equal(t, l.Remove(POST, "/files/:id"), false)
+	Equal(t, l.Remove(GET, "/missing"), false)
+
+	// removed routes are pruned so conflicting routes can be registered in their place
+	l.Get("/static/*filepath", func(c Context) {
+		c.Text(http.StatusOK, c.Param("filepath"))
+	})
+
+	Equal(t, l.Remove(GET, "/users/:id"), true)
+	l.Get("/users/new", basicHandler)
+
+	code, _ = serve(GET, "/users/new")
+	Equal(t, code, http.StatusOK)
+
+	code, _ = serve(GET, "/users/1")
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = serve(GET, "/static/css/app.css")
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "css/app.css")
+
+	l.Post("/only", basicHandler)
+	Equal(t, l.Remove(POST, "/only"), true)
+
+	code, _ = serve(POST, "/only")
+	Equal(t, code, http.StatusNotFound)
+
+	l.Post("/:id", basicHandler)
+
+	code, _ = serve(POST, "/7")
+	Equal(t, code, http.StatusOK)
+
+	// setters of routes registered while serving
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		l.Put("/limited", basicHandler).BodyLimit(10).Silent().Summary("limited")
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 10; i++ {
+			serve(PUT, "/limited")
+		}
+	}()
+
+	wg.Wait()
+
+	code, _ = serve(PUT, "/limited")
+	Equal(t, code, http.StatusOK)
+}
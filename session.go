@@ -0,0 +1,447 @@
+package lars
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSessionName   = "lars_session"
+	defaultSessionMaxAge = 24 * time.Hour
+
+	// maxCookieSize is the maximum size of a cookie value browsers are
+	// guaranteed to store
+	maxCookieSize = 4096
+)
+
+// ErrNoSessionStore is returned by Session when no SessionStore
+// was set using SetSessionStore
+var ErrNoSessionStore = errors.New("no SessionStore set, see SetSessionStore")
+
+// ErrSessionTooLarge is returned by the CookieSessionStore when the
+// encoded session exceeds the maximum size of a cookie
+var ErrSessionTooLarge = errors.New("session too large to be stored in a cookie")
+
+// SessionStore loads and saves the values of sessions identified by the value of the
+// session cookie, such as an ID for server-side stores or the encoded values themselves.
+type SessionStore interface {
+	// Load returns the values of the session identified by value, ok is false when
+	// it doesn't exist, has expired or is invalid
+	Load(value string) (values map[string]interface{}, ok bool, err error)
+
+	// Save saves the values of the session identified by value, blank for a new
+	// session, until maxAge has passed and returns the value identifying it
+	Save(value string, values map[string]interface{}, maxAge time.Duration) (string, error)
+
+	// Delete deletes the session identified by value
+	Delete(value string) error
+}
+
+// SessionOptions configures the session cookie, the cookie is always HttpOnly and the
+// defaults set using SetCookieDefaults apply to it as well
+type SessionOptions struct {
+	// Name of the session cookie. default "lars_session"
+	Name string
+
+	// Path of the session cookie. default "/"
+	Path string
+
+	// Domain of the session cookie. default blank, the request's host
+	Domain string
+
+	// MaxAge is how long a session lives after it was last saved. default 24h
+	MaxAge time.Duration
+
+	// Secure restricts the session cookie to HTTPS requests
+	Secure bool
+
+	// SameSite of the session cookie. default http.SameSiteLaxMode
+	SameSite http.SameSite
+}
+
+// Session holds the values of a client's session, it's loaded on the first call to
+// c.Session() and saved, when modified, just before the response is committed or
+// once the request completes.
+type Session struct {
+	values     map[string]interface{}
+	value      string
+	isNew      bool
+	modified   bool
+	regenerate bool
+	destroyed  bool
+}
+
+// Get returns the value stored under key, nil if there isn't one
+func (s *Session) Get(key string) interface{} {
+	return s.values[key]
+}
+
+// Set stores value under key
+func (s *Session) Set(key string, value interface{}) {
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete deletes the value stored under key
+func (s *Session) Delete(key string) {
+	delete(s.values, key)
+	s.modified = true
+}
+
+// Clear deletes all of the session's values
+func (s *Session) Clear() {
+	s.values = make(map[string]interface{})
+	s.modified = true
+}
+
+// IsNew returns whether the session was just created for the request
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Regenerate replaces the session's identifier, keeping it's values, when it's
+// saved; it should be called when privileges change, such as on login, to prevent
+// session fixation.
+func (s *Session) Regenerate() {
+	s.regenerate = true
+	s.modified = true
+}
+
+// Destroy deletes the session from the store and expires the session cookie
+func (s *Session) Destroy() {
+	s.values = make(map[string]interface{})
+	s.destroyed = true
+	s.modified = true
+}
+
+// SetSessionStore sets the SessionStore sessions, accessed using c.Session(), are
+// kept in along with the options of the cookie identifying them.
+func (l *LARS) SetSessionStore(store SessionStore, options SessionOptions) {
+
+	if options.Name == blank {
+		options.Name = defaultSessionName
+	}
+
+	if options.Path == blank {
+		options.Path = basePath
+	}
+
+	if options.MaxAge <= 0 {
+		options.MaxAge = defaultSessionMaxAge
+	}
+
+	if options.SameSite == 0 {
+		options.SameSite = http.SameSiteLaxMode
+	}
+
+	l.sessionStore = store
+	l.sessionOptions = options
+}
+
+// Session returns the request's session, loading it from the SessionStore on first
+// access; a new session is returned when the request has none or it has expired.
+func (c *Ctx) Session() (*Session, error) {
+
+	if c.session != nil {
+		return c.session, nil
+	}
+
+	store := c.lars.sessionStore
+	if store == nil {
+		return nil, ErrNoSessionStore
+	}
+
+	s := &Session{}
+
+	if cookie, err := c.request.Cookie(c.lars.sessionOptions.Name); err == nil && cookie.Value != blank {
+
+		values, ok, err := store.Load(cookie.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			s.values = values
+			s.value = cookie.Value
+		}
+	}
+
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+		s.isNew = true
+	}
+
+	c.session = s
+
+	return s, nil
+}
+
+// saveSession saves the request's session, when it was modified, and sets
+// the session cookie; it must be called before the response is committed.
+func (c *Ctx) saveSession() {
+
+	s := c.session
+	if s == nil || !s.modified {
+		return
+	}
+
+	s.modified = false
+
+	store := c.lars.sessionStore
+	opts := c.lars.sessionOptions
+
+	cookie := &http.Cookie{
+		Name:     opts.Name,
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		Secure:   opts.Secure,
+		HttpOnly: true,
+		SameSite: opts.SameSite,
+	}
+
+	if s.destroyed || s.regenerate {
+
+		if s.value != blank {
+			if err := store.Delete(s.value); err != nil {
+				c.Log("error", "session delete failed", "error", err)
+			}
+		}
+
+		s.value = blank
+		s.regenerate = false
+	}
+
+	if s.destroyed {
+		s.destroyed = false
+		cookie.MaxAge = -1
+		c.SetCookie(cookie)
+		return
+	}
+
+	value, err := store.Save(s.value, s.values, opts.MaxAge)
+	if err != nil {
+		c.Log("error", "session save failed", "error", err)
+		return
+	}
+
+	s.value = value
+
+	cookie.Value = value
+	cookie.MaxAge = int(opts.MaxAge / time.Second)
+	c.SetCookie(cookie)
+}
+
+// MemorySessionStore is an in-memory server-side SessionStore, the session cookie
+// only holds a random session ID. Sessions are lost on restart and aren't shared
+// between instances, use a store backed by a database for those.
+type MemorySessionStore struct {
+	sessions  map[string]*memorySession
+	lastSweep time.Time
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+type memorySession struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+var _ SessionStore = new(MemorySessionStore)
+
+// sessionSweepInterval is how often the MemorySessionStore evicts expired sessions
+const sessionSweepInterval = time.Minute
+
+// NewMemorySessionStore returns a new in-memory server-side SessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*memorySession),
+		now:      time.Now,
+	}
+}
+
+// Load returns the values of the session with the ID value
+func (m *MemorySessionStore) Load(value string) (map[string]interface{}, bool, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[value]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !m.now().Before(s.expires) {
+		delete(m.sessions, value)
+		return nil, false, nil
+	}
+
+	values := make(map[string]interface{}, len(s.values))
+
+	for k, v := range s.values {
+		values[k] = v
+	}
+
+	return values, true, nil
+}
+
+// Save saves the values of the session with the ID value, a new ID is generated
+// when it's blank.
+func (m *MemorySessionStore) Save(value string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+
+	if value == blank {
+
+		id, err := newSessionID()
+		if err != nil {
+			return blank, err
+		}
+
+		value = id
+	}
+
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) >= sessionSweepInterval {
+
+		m.lastSweep = now
+
+		for k, s := range m.sessions {
+			if !now.Before(s.expires) {
+				delete(m.sessions, k)
+			}
+		}
+	}
+
+	copied := make(map[string]interface{}, len(values))
+
+	for k, v := range values {
+		copied[k] = v
+	}
+
+	m.sessions[value] = &memorySession{values: copied, expires: now.Add(maxAge)}
+
+	return value, nil
+}
+
+// Delete deletes the session with the ID value
+func (m *MemorySessionStore) Delete(value string) error {
+
+	m.mu.Lock()
+	delete(m.sessions, value)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// newSessionID returns a new random session ID
+func newSessionID() (string, error) {
+
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return blank, err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CookieSessionStore is a SessionStore which keeps the session's values in the session
+// cookie itself, JSON encoded and signed using HMAC-SHA256 so they can't be tampered
+// with. The values are NOT encrypted, they're readable by the client, and are decoded
+// as JSON would, numbers as float64's; the encoded session must fit in a cookie.
+type CookieSessionStore struct {
+	key []byte
+	now func() time.Time
+}
+
+type cookieSession struct {
+	Values  map[string]interface{} `json:"v"`
+	Expires int64                  `json:"e"`
+}
+
+var _ SessionStore = new(CookieSessionStore)
+
+// NewCookieSessionStore returns a new CookieSessionStore signing the sessions using
+// key, which should be at least 32 random bytes.
+func NewCookieSessionStore(key []byte) *CookieSessionStore {
+
+	if len(key) == 0 {
+		panic("session: cookie store key is required")
+	}
+
+	return &CookieSessionStore{key: key, now: time.Now}
+}
+
+// Load verifies and decodes the session's values from the cookie value
+func (cs *CookieSessionStore) Load(value string) (map[string]interface{}, bool, error) {
+
+	i := strings.LastIndexByte(value, '.')
+	if i == -1 {
+		return nil, false, nil
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(value[i+1:])
+	if err != nil || !hmac.Equal(sig, cs.sign(value[:i])) {
+		return nil, false, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(value[:i])
+	if err != nil {
+		return nil, false, nil
+	}
+
+	var s cookieSession
+
+	if err = json.Unmarshal(b, &s); err != nil {
+		return nil, false, nil
+	}
+
+	if cs.now().Unix() >= s.Expires {
+		return nil, false, nil
+	}
+
+	if s.Values == nil {
+		s.Values = make(map[string]interface{})
+	}
+
+	return s.Values, true, nil
+}
+
+// Save encodes and signs the session's values, returning them as the cookie value
+func (cs *CookieSessionStore) Save(value string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+
+	b, err := json.Marshal(cookieSession{Values: values, Expires: cs.now().Add(maxAge).Unix()})
+	if err != nil {
+		return blank, err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	value = payload + "." + base64.RawURLEncoding.EncodeToString(cs.sign(payload))
+
+	if len(value) > maxCookieSize {
+		return blank, ErrSessionTooLarge
+	}
+
+	return value, nil
+}
+
+// Delete is a no-op, the session is deleted by expiring the cookie
+func (cs *CookieSessionStore) Delete(value string) error {
+	return nil
+}
+
+func (cs *CookieSessionStore) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, cs.key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
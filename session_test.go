@@ -0,0 +1,282 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func sessionRoutes(l *LARS) {
+
+	l.Get("/set", func(c Context) {
+		s, _ := c.Session()
+		s.Set("user", c.QueryParams().Get("user"))
+		c.Text(http.StatusOK, "set")
+	})
+	l.Get("/get", func(c Context) {
+		s, _ := c.Session()
+		user, _ := s.Get("user").(string)
+		c.Text(http.StatusOK, user)
+	})
+	l.Get("/silent-set", func(c Context) {
+		s, _ := c.Session()
+		s.Set("user", "silent")
+	})
+	l.Get("/regenerate", func(c Context) {
+		s, _ := c.Session()
+		s.Regenerate()
+		c.Text(http.StatusOK, "regenerated")
+	})
+	l.Get("/destroy", func(c Context) {
+		s, _ := c.Session()
+		s.Destroy()
+		c.Text(http.StatusOK, "destroyed")
+	})
+}
+
+func serveSession(hf http.Handler, path string, cookie *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+
+	r, _ := http.NewRequest(GET, path, nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) == 0 {
+		return w, nil
+	}
+
+	return w, cookies[0]
+}
+
+func TestMemorySession(t *testing.T) {
+
+	store := NewMemorySessionStore()
+
+	l := New()
+	l.SetSessionStore(store, SessionOptions{Secure: true})
+	sessionRoutes(l)
+
+	hf := l.Serve()
+
+	w, cookie := serveSession(hf, "/get", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "")
+	Equal(t, cookie, nil) // unmodified sessions aren't saved
+
+	w, cookie = serveSession(hf, "/set?user=joeybloggs", nil)
+	Equal(t, w.Code, http.StatusOK)
+	NotEqual(t, cookie, nil)
+	Equal(t, cookie.Name, "lars_session")
+	Equal(t, cookie.Path, "/")
+	Equal(t, cookie.MaxAge, 86400)
+	Equal(t, cookie.HttpOnly, true)
+	Equal(t, cookie.Secure, true)
+	Equal(t, cookie.SameSite, http.SameSiteLaxMode)
+	Equal(t, len(store.sessions), 1)
+
+	w, _ = serveSession(hf, "/get", cookie)
+	Equal(t, w.Body.String(), "joeybloggs")
+
+	// regenerating keeps the values under a new ID
+	w, regenerated := serveSession(hf, "/regenerate", cookie)
+	NotEqual(t, regenerated, nil)
+	NotEqual(t, regenerated.Value, cookie.Value)
+	Equal(t, len(store.sessions), 1)
+
+	w, _ = serveSession(hf, "/get", cookie)
+	Equal(t, w.Body.String(), "")
+
+	w, _ = serveSession(hf, "/get", regenerated)
+	Equal(t, w.Body.String(), "joeybloggs")
+
+	w, destroyed := serveSession(hf, "/destroy", regenerated)
+	NotEqual(t, destroyed, nil)
+	Equal(t, destroyed.MaxAge, -1)
+	Equal(t, len(store.sessions), 0)
+
+	w, _ = serveSession(hf, "/get", regenerated)
+	Equal(t, w.Body.String(), "")
+
+	// saved once the request completes when nothing was written
+	w, cookie = serveSession(hf, "/silent-set", nil)
+	NotEqual(t, cookie, nil)
+
+	w, _ = serveSession(hf, "/get", cookie)
+	Equal(t, w.Body.String(), "silent")
+
+	// unknown ID's get a new session
+	w, _ = serveSession(hf, "/get", &http.Cookie{Name: "lars_session", Value: "unknown"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "")
+}
+
+func TestMemorySessionExpiry(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	store := NewMemorySessionStore()
+	store.now = func() time.Time { return now }
+
+	id, err := store.Save("", map[string]interface{}{"a": 1}, time.Minute)
+	Equal(t, err, nil)
+	NotEqual(t, id, "")
+
+	other, _ := store.Save("", map[string]interface{}{"b": 2}, time.Hour)
+
+	values, ok, err := store.Load(id)
+	Equal(t, err, nil)
+	Equal(t, ok, true)
+	Equal(t, values["a"], 1)
+
+	// loaded values are a copy
+	values["a"] = 2
+	values, _, _ = store.Load(id)
+	Equal(t, values["a"], 1)
+
+	now = now.Add(time.Minute)
+
+	values, ok, _ = store.Load(id)
+	Equal(t, ok, false)
+	Equal(t, values, nil)
+	Equal(t, len(store.sessions), 1)
+
+	// expired sessions are swept when saving
+	id, _ = store.Save("", nil, time.Minute)
+	now = now.Add(time.Hour)
+	store.Save(id, nil, time.Minute)
+	Equal(t, len(store.sessions), 1)
+
+	_, ok, _ = store.Load(other)
+	Equal(t, ok, false)
+}
+
+func TestCookieSession(t *testing.T) {
+
+	store := NewCookieSessionStore([]byte("secret-key-of-at-least-32-bytes!"))
+
+	l := New()
+	l.SetSessionStore(store, SessionOptions{Name: "sess", Path: "/app", MaxAge: time.Hour, SameSite: http.SameSiteStrictMode})
+	sessionRoutes(l)
+
+	hf := l.Serve()
+
+	w, cookie := serveSession(hf, "/set?user=joeybloggs", nil)
+	Equal(t, w.Code, http.StatusOK)
+	NotEqual(t, cookie, nil)
+	Equal(t, cookie.Name, "sess")
+	Equal(t, cookie.Path, "/app")
+	Equal(t, cookie.MaxAge, 3600)
+	Equal(t, cookie.Secure, false)
+	Equal(t, cookie.SameSite, http.SameSiteStrictMode)
+
+	w, _ = serveSession(hf, "/get", cookie)
+	Equal(t, w.Body.String(), "joeybloggs")
+
+	// tampered with
+	tampered := *cookie
+	tampered.Value = "x" + cookie.Value[1:]
+
+	w, _ = serveSession(hf, "/get", &tampered)
+	Equal(t, w.Body.String(), "")
+
+	w, destroyed := serveSession(hf, "/destroy", cookie)
+	Equal(t, destroyed.MaxAge, -1)
+}
+
+func TestCookieSessionStore(t *testing.T) {
+
+	now := time.Unix(1000, 0)
+
+	store := NewCookieSessionStore([]byte("secret"))
+	store.now = func() time.Time { return now }
+
+	value, err := store.Save("", map[string]interface{}{"id": 1}, time.Minute)
+	Equal(t, err, nil)
+
+	values, ok, err := store.Load(value)
+	Equal(t, err, nil)
+	Equal(t, ok, true)
+	Equal(t, values["id"], float64(1))
+
+	// signed with another key
+	_, ok, _ = NewCookieSessionStore([]byte("other")).Load(value)
+	Equal(t, ok, false)
+
+	for _, v := range []string{"", "nodot", "bad.!!!", "!!!." + value[strings.LastIndexByte(value, '.')+1:]} {
+		_, ok, err = store.Load(v)
+		Equal(t, ok, false)
+		Equal(t, err, nil)
+	}
+
+	now = now.Add(time.Minute)
+
+	_, ok, _ = store.Load(value)
+	Equal(t, ok, false)
+
+	_, err = store.Save("", map[string]interface{}{"big": strings.Repeat("a", maxCookieSize)}, time.Minute)
+	Equal(t, err, ErrSessionTooLarge)
+
+	_, err = store.Save("", map[string]interface{}{"bad": make(chan int)}, time.Minute)
+	NotEqual(t, err, nil)
+
+	Equal(t, store.Delete(value), nil)
+
+	PanicMatches(t, func() { NewCookieSessionStore(nil) }, "session: cookie store key is required")
+}
+
+func TestSessionSaveError(t *testing.T) {
+
+	var logged []LogEntry
+
+	l := New()
+	l.SetSessionStore(NewCookieSessionStore([]byte("secret")), SessionOptions{})
+	l.SetLogSink(func(c Context, entries []LogEntry) {
+		logged = append(logged, entries...)
+	})
+	l.Get("/big", func(c Context) {
+		s, _ := c.Session()
+		s.Set("big", strings.Repeat("a", maxCookieSize))
+		c.Text(http.StatusOK, "ok")
+	})
+
+	hf := l.Serve()
+
+	w, cookie := serveSession(hf, "/big", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, cookie, nil)
+	Equal(t, len(logged), 1)
+	Equal(t, logged[0].Message, "session save failed")
+}
+
+func TestNoSessionStore(t *testing.T) {
+
+	var err error
+
+	l := New()
+	l.Get("/", func(c Context) {
+		_, err = c.Session()
+	})
+
+	code, _ := request(GET, "/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, err, ErrNoSessionStore)
+}
@@ -0,0 +1,184 @@
+package lars
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// shutdownPollInterval is how often Shutdown checks for the
+// in-flight requests to have completed
+const shutdownPollInterval = 50 * time.Millisecond
+
+// notifySignals relays the signals that trigger a graceful shutdown
+// of Run and RunTLS to c, replaceable for testing.
+var notifySignals = func(c chan<- os.Signal) {
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+}
+
+// RunServer starts an *http.Server, owned by LARS, listening on the TCP network address addr
+// and serving the LARS handler. It blocks until the server stops and returns nil when it was
+// stopped using Shutdown.
+func (l *LARS) RunServer(addr string) error {
+	return l.runServer(addr, func(srv *http.Server) error {
+		return srv.ListenAndServe()
+	})
+}
+
+// Run starts a server, just like RunServer, and gracefully shuts it down, using
+// Shutdown, once SIGINT or SIGTERM is received; in-flight requests are given up to
+// the shutdown timeout, set using SetShutdownTimeout, to complete. It blocks until
+// the server stops and returns nil when it was stopped gracefully.
+func (l *LARS) Run(addr string) error {
+	return l.run(addr, func(srv *http.Server) error {
+		return srv.ListenAndServe()
+	})
+}
+
+// RunTLS is the same as Run but serves HTTPS using the provided certificate and
+// matching private key files.
+func (l *LARS) RunTLS(addr, certFile, keyFile string) error {
+	return l.run(addr, func(srv *http.Server) error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+func (l *LARS) run(addr string, listen func(*http.Server) error) error {
+
+	sig := make(chan os.Signal, 1)
+	notifySignals(sig)
+	defer signal.Stop(sig)
+
+	srvErr := make(chan error, 1)
+
+	go func() {
+		srvErr <- l.runServer(addr, listen)
+	}()
+
+	select {
+	case err := <-srvErr:
+		return err
+	case <-sig:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	err := l.Shutdown(ctx)
+
+	if e := <-srvErr; err == nil {
+		err = e
+	}
+
+	return err
+}
+
+func (l *LARS) runServer(addr string, listen func(*http.Server) error) error {
+
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: l.Serve(),
+	}
+
+	l.serverMu.Lock()
+	l.server = srv
+	l.serverMu.Unlock()
+
+	if err := listen(srv); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown gracefully shuts down LARS, including the server started using RunServer. The
+// readiness checks fail from the start while requests are still served for the shutdown delay,
+// set using SetShutdownDelay, after which the server stops accepting new connections; new
+// requests on existing connections are answered with 503 Service Unavailable while in-flight
+// requests are waited on until they complete and their Context's RequestEnd has run. If a
+// drain timeout was set using SetDrainTimeout the contexts of requests still running after it
+// elapses are cancelled, which handlers observe through the Context's Done() channel, and
+// Shutdown keeps waiting for them to return.
+//
+// The provided ctx is the hard deadline of the whole shutdown, if it is done before all
+// in-flight requests complete ctx's error is returned; the requests are only cancelled when a
+// drain timeout is set, otherwise their handlers keep running after Shutdown returns. The drain
+// timeout should therefore be shorter than ctx's deadline to leave cancelled handlers time to
+// clean up.
+func (l *LARS) Shutdown(ctx context.Context) error {
+
+	atomic.StoreInt32(&l.inFlight.draining, 1)
+
+	if l.shutdownDelay > 0 {
+
+		t := time.NewTimer(l.shutdownDelay)
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+
+	atomic.StoreInt32(&l.inFlight.shuttingDown, 1)
+
+	l.health.stopPeriodic()
+
+	l.serverMu.Lock()
+	srv := l.server
+	l.serverMu.Unlock()
+
+	if srv == nil {
+		return l.drain(ctx)
+	}
+
+	srvErr := make(chan error, 1)
+
+	go func() {
+		srvErr <- srv.Shutdown(ctx)
+	}()
+
+	err := l.drain(ctx)
+
+	if e := <-srvErr; err == nil {
+		err = e
+	}
+
+	return err
+}
+
+// drain waits for all in-flight requests to complete, cancelling them, when cancellable,
+// once the drain timeout elapses or ctx is done.
+func (l *LARS) drain(ctx context.Context) error {
+
+	var drain <-chan time.Time
+
+	if l.drainTimeout > 0 {
+		t := time.NewTimer(l.drainTimeout)
+		defer t.Stop()
+		drain = t.C
+	}
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if l.inFlight.len() == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			l.inFlight.cancelAll()
+			return ctx.Err()
+		case <-drain:
+			l.inFlight.cancelAll()
+			drain = nil
+		case <-ticker.C:
+		}
+	}
+}
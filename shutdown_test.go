@@ -0,0 +1,366 @@
+package lars
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestShutdown(t *testing.T) {
+
+	l := New()
+	l.SetDrainTimeout(time.Millisecond * 100)
+
+	started := make(chan struct{})
+	cancelled := make(chan bool, 1)
+
+	l.Get("/hang", func(c Context) {
+		close(started)
+
+		select {
+		case <-c.Done():
+			cancelled <- true
+		case <-time.After(time.Second * 5):
+			cancelled <- false
+		}
+	})
+
+	done := make(chan int)
+
+	go func() {
+		code, _ := request(GET, "/hang", l)
+		done <- code
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	Equal(t, l.Shutdown(ctx), nil)
+	Equal(t, <-cancelled, true)
+	Equal(t, <-done, http.StatusOK)
+
+	code, _ := request(GET, "/hang", l)
+	Equal(t, code, http.StatusServiceUnavailable)
+
+	// context deadline reached before handler completes
+	l2 := New()
+
+	started = make(chan struct{})
+	release := make(chan struct{})
+
+	l2.Get("/slow", func(c Context) {
+		close(started)
+		<-release
+	})
+
+	go request(GET, "/slow", l2)
+
+	<-started
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel2()
+
+	Equal(t, l2.Shutdown(ctx2), context.DeadlineExceeded)
+	close(release)
+
+	// a panicking handler doesn't leave the request in-flight
+	l3 := New()
+	l3.Get("/panic", func(c Context) {
+		panic("boom")
+	})
+
+	func() {
+		defer func() { recover() }()
+		request(GET, "/panic", l3)
+	}()
+
+	ctx3, cancel3 := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel3()
+
+	Equal(t, l3.Shutdown(ctx3), nil)
+
+	// Shutdown beginning after the request passed the first shutting down check but
+	// before it's in-flight
+	l4 := New()
+
+	shutdownErr := make(chan error, 1)
+
+	l4.RegisterContext(func(l *LARS) Context {
+		return &startContext{Ctx: NewContext(l), start: func() {
+
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				shutdownErr <- l.Shutdown(ctx)
+			}()
+
+			for atomic.LoadInt32(&l.inFlight.shuttingDown) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}}
+	})
+	l4.Get("/late", basicHandler)
+
+	code, _ = request(GET, "/late", l4)
+	Equal(t, code, http.StatusServiceUnavailable)
+	Equal(t, <-shutdownErr, nil)
+}
+
+func TestRunServer(t *testing.T) {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	Equal(t, err, nil)
+
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ended := make(chan bool, 1)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	l := New()
+	l.RegisterContext(func(l *LARS) Context {
+		return &endContext{Ctx: NewContext(l), ended: ended}
+	})
+	l.Get("/slow", func(c Context) {
+		close(started)
+		<-release
+		c.Text(http.StatusOK, "done")
+	})
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- l.RunServer(addr)
+	}()
+
+	// keep-alives disabled so no spare idle connections hold up the server's shutdown
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	var res *http.Response
+
+	for i := 0; i < 100; i++ {
+		if res, err = client.Get("http://" + addr + "/notfound"); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	Equal(t, err, nil)
+	Equal(t, res.StatusCode, http.StatusNotFound)
+	res.Body.Close()
+	<-ended
+
+	body := make(chan string, 1)
+
+	go func() {
+		res, err := client.Get("http://" + addr + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+
+		b, _ := ioutil.ReadAll(res.Body)
+		body <- string(b)
+	}()
+
+	<-started
+
+	shutdown := make(chan error, 1)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		shutdown <- l.Shutdown(ctx)
+	}()
+
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+
+	Equal(t, <-shutdown, nil)
+	Equal(t, <-serverErr, nil)
+	Equal(t, <-body, "done")
+	Equal(t, <-ended, true)
+}
+
+func TestRun(t *testing.T) {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	Equal(t, err, nil)
+
+	addr := ln.Addr().String()
+	ln.Close()
+
+	sig := make(chan chan<- os.Signal, 1)
+
+	notify := notifySignals
+	notifySignals = func(c chan<- os.Signal) {
+		sig <- c
+	}
+	defer func() { notifySignals = notify }()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	l := New()
+	l.SetShutdownTimeout(time.Second * 5)
+	l.Get("/slow", func(c Context) {
+		close(started)
+		<-release
+		c.Text(http.StatusOK, "done")
+	})
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- l.Run(addr)
+	}()
+
+	notified := <-sig
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	body := make(chan string, 1)
+
+	go func() {
+		var res *http.Response
+		var err error
+
+		for i := 0; i < 100; i++ {
+			if res, err = client.Get("http://" + addr + "/slow"); err == nil {
+				break
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+
+		b, _ := ioutil.ReadAll(res.Body)
+		body <- string(b)
+	}()
+
+	<-started
+
+	notified <- os.Interrupt
+
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+
+	Equal(t, <-serverErr, nil)
+	Equal(t, <-body, "done")
+
+	// the in-flight request was drained, new ones are refused
+	_, err = client.Get("http://" + addr + "/slow")
+	NotEqual(t, err, nil)
+}
+
+func TestRunTimeout(t *testing.T) {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	Equal(t, err, nil)
+
+	addr := ln.Addr().String()
+	ln.Close()
+
+	sig := make(chan chan<- os.Signal, 1)
+
+	notify := notifySignals
+	notifySignals = func(c chan<- os.Signal) {
+		sig <- c
+	}
+	defer func() { notifySignals = notify }()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	l := New()
+	l.SetShutdownTimeout(time.Millisecond * 100)
+	l.Get("/slow", func(c Context) {
+		close(started)
+
+		select {
+		case <-c.Done():
+		case <-release:
+		}
+	})
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- l.Run(addr)
+	}()
+
+	notified := <-sig
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			if res, err := http.Get("http://" + addr + "/slow"); err == nil {
+				res.Body.Close()
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}()
+
+	<-started
+
+	notified <- syscall.SIGTERM
+
+	Equal(t, <-serverErr, context.DeadlineExceeded)
+}
+
+func TestRunTLSError(t *testing.T) {
+
+	l := New()
+
+	err := l.RunTLS("127.0.0.1:0", "missing-cert.pem", "missing-key.pem")
+	NotEqual(t, err, nil)
+	Equal(t, os.IsNotExist(err), true)
+}
+
+type endContext struct {
+	*Ctx
+	ended chan bool
+}
+
+func (c *endContext) RequestEnd() {
+	c.ended <- true
+	c.Ctx.RequestEnd()
+}
+
+type startContext struct {
+	*Ctx
+	start func()
+}
+
+func (c *startContext) RequestStart(w http.ResponseWriter, r *http.Request) {
+	c.Ctx.RequestStart(w, r)
+	c.start()
+}
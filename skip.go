@@ -0,0 +1,66 @@
+package lars
+
+import "strings"
+
+// SkipFunc returns whether a middleware added using UseWithSkip is skipped for the
+// request i.e. func(c Context) bool { return c.Request().Method == OPTIONS }
+type SkipFunc func(c Context) bool
+
+// SkipPaths returns a SkipFunc which skips requests for any of the paths, paths ending
+// in /* skip all the paths below them i.e. SkipPaths("/health", "/assets/*")
+func SkipPaths(paths ...string) SkipFunc {
+
+	exact := make(map[string]struct{})
+	var prefixes []string
+
+	for _, p := range paths {
+
+		if strings.HasSuffix(p, "/*") {
+			prefixes = append(prefixes, p[:len(p)-1])
+			continue
+		}
+
+		exact[p] = struct{}{}
+	}
+
+	return func(c Context) bool {
+
+		path := c.Request().URL.Path
+
+		if _, ok := exact[path]; ok {
+			return true
+		}
+
+		for _, p := range prefixes {
+			if strings.HasPrefix(path, p) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// UseWithSkip adds a middleware handler to the group middleware chain which is
+// bypassed, continuing on to the next handler, for requests any of the SkipFuncs
+// return true for; so global middleware, such as logging, can skip health checks and
+// static assets without restructuring groups.
+//
+// i.e. l.UseWithSkip(logger, lars.SkipPaths("/health", "/metrics"))
+func (g *routeGroup) UseWithSkip(m Handler, skip ...SkipFunc) {
+
+	h, name := g.lars.wrapHandlerWithName(m)
+
+	g.middleware = append(g.middleware, func(c Context) {
+
+		for _, s := range skip {
+			if s(c) {
+				c.Next()
+				return
+			}
+		}
+
+		h(c)
+	})
+	g.middlewareNames = append(g.middlewareNames, name)
+}
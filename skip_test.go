@@ -0,0 +1,59 @@
+package lars
+
+import (
+	"net/http"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestUseWithSkip(t *testing.T) {
+
+	var logged []string
+
+	logger := func(c Context) {
+		logged = append(logged, c.Request().URL.Path)
+		c.Next()
+	}
+
+	l := New()
+	l.UseWithSkip(logger, SkipPaths("/health", "/assets/*"), func(c Context) bool {
+		return c.Request().Method == OPTIONS
+	})
+	l.Get("/health", basicHandler)
+	l.Get("/users", basicHandler)
+	l.Options("/users", basicHandler)
+	l.Get("/assets/*", basicHandler)
+	l.Get("/assetsfile", basicHandler)
+
+	code, _ := request(GET, "/health", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/users", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(OPTIONS, "/users", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/assets/app.js", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/assetsfile", l)
+	Equal(t, code, http.StatusOK)
+
+	Equal(t, logged, []string{"/users", "/assetsfile"})
+
+	Equal(t, len(l.Routes()[0].Handlers), 2)
+	MatchRegex(t, l.Routes()[0].Handlers[0], "TestUseWithSkip")
+}
@@ -0,0 +1,193 @@
+package lars
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrEventStreamClosed is returned when sending to an EventStream
+// after the request has completed
+var ErrEventStreamClosed = errors.New("event stream closed")
+
+var sseNewlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// EventStream writes Server-Sent Events to the client, it's safe
+// for concurrent use.
+type EventStream struct {
+	c      *Ctx
+	mu     sync.Mutex
+	err    error
+	closed bool
+	stop   chan struct{}
+}
+
+// SSE starts a Server-Sent Events stream, writing the text/event-stream headers, and
+// returns the EventStream used to send events; subsequent calls return the same stream.
+// The stream is closed, along with any heartbeat, once the request completes.
+func (c *Ctx) SSE() *EventStream {
+
+	if c.sse != nil {
+		return c.sse
+	}
+
+	h := c.response.Header()
+	h.Set(ContentType, TextEventStream)
+	h.Set(CacheControl, "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // disables proxy buffering, such as nginx's
+
+	c.response.WriteHeader(http.StatusOK)
+	c.response.Flush()
+
+	c.sse = &EventStream{c: c}
+
+	return c.sse
+}
+
+// LastEventID returns the ID of the last event the client received, sent in the
+// Last-Event-ID header when it reconnects, so it can resume from there.
+func (s *EventStream) LastEventID() string {
+	return s.c.request.Header.Get(LastEventID)
+}
+
+// Send sends an event with the data, which may span multiple lines; blank event and
+// id fields are omitted. An event without a name is dispatched by the client as a
+// "message" event. The first error writing to the client is returned by all
+// subsequent calls.
+func (s *EventStream) Send(event, id, data string) error {
+
+	var b strings.Builder
+
+	if id != blank {
+		b.WriteString("id: ")
+		b.WriteString(sseField(id))
+		b.WriteByte('\n')
+	}
+
+	if event != blank {
+		b.WriteString("event: ")
+		b.WriteString(sseField(event))
+		b.WriteByte('\n')
+	}
+
+	for _, line := range strings.Split(sseNewlines.Replace(data), "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	b.WriteByte('\n')
+
+	return s.write(b.String())
+}
+
+// Retry sets how long the client waits before reconnecting once the connection is lost
+func (s *EventStream) Retry(d time.Duration) error {
+	return s.write("retry: " + strconv.FormatInt(int64(d/time.Millisecond), 10) + "\n\n")
+}
+
+// Comment sends a comment, which the client ignores
+func (s *EventStream) Comment(text string) error {
+
+	var b strings.Builder
+
+	for _, line := range strings.Split(sseNewlines.Replace(text), "\n") {
+		b.WriteString(": ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	b.WriteByte('\n')
+
+	return s.write(b.String())
+}
+
+// Heartbeat sends a comment every interval, keeping the connection from being closed
+// by proxies as idle, until the request completes; calling it again replaces the
+// previous interval and an interval <= 0 stops it.
+func (s *EventStream) Heartbeat(interval time.Duration) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+
+	if interval <= 0 || s.closed {
+		return
+	}
+
+	stop := make(chan struct{})
+	s.stop = stop
+
+	go func() {
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				if s.write(":\n\n") != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// write writes and flushes p unless the stream is closed or a previous write failed
+func (s *EventStream) write(p string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrEventStreamClosed
+	}
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if _, s.err = s.c.response.WriteString(p); s.err != nil {
+		return s.err
+	}
+
+	s.c.response.Flush()
+
+	return nil
+}
+
+// close stops the heartbeat and fails any further sends, it's called once the
+// request completes as the *Ctx is about to be reused.
+func (s *EventStream) close() {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// sseField strips the line breaks from an event or id field, they'd end the field
+func sseField(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,94 @@
+package lars
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestSSE(t *testing.T) {
+
+	var stream *EventStream
+
+	l := New()
+	l.Get("/events", func(c Context) {
+
+		stream = c.SSE()
+		Equal(t, c.SSE(), stream)
+
+		stream.Retry(time.Second * 3)
+		stream.Send("", "", "hello")
+		stream.Send("update", "2", "line 1\nline 2\r\nline 3")
+		stream.Send("bad\nevent", "bad\r\nid", "")
+		stream.Comment("a comment")
+		stream.Send("resumed", "", stream.LastEventID())
+	})
+
+	r, _ := http.NewRequest(GET, "/events", nil)
+	r.Header.Set(LastEventID, "41")
+	w := &closeNotifyingRecorder{httptest.NewRecorder(), make(chan bool, 1)}
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), TextEventStream)
+	Equal(t, w.Header().Get(CacheControl), "no-cache")
+	Equal(t, w.Flushed, true)
+	Equal(t, w.Body.String(), "retry: 3000\n\n"+
+		"data: hello\n\n"+
+		"id: 2\nevent: update\ndata: line 1\ndata: line 2\ndata: line 3\n\n"+
+		"id: badid\nevent: badevent\ndata: \n\n"+
+		": a comment\n\n"+
+		"event: resumed\ndata: 41\n\n")
+
+	// closed once the request completes
+	Equal(t, stream.Send("", "", "late"), ErrEventStreamClosed)
+	stream.Heartbeat(time.Millisecond)
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+
+	l := New()
+	l.Get("/events", func(c Context) {
+
+		stream := c.SSE()
+		stream.Heartbeat(time.Millisecond * 5)
+
+		time.Sleep(time.Millisecond * 50)
+
+		// replaced, then stopped
+		stream.Heartbeat(time.Hour)
+		stream.Heartbeat(0)
+
+		stream.Send("done", "", "")
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	Equal(t, err, nil)
+
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	body := string(b)
+
+	Equal(t, strings.HasPrefix(body, ":\n\n"), true)
+	Equal(t, strings.HasSuffix(body, ":\n\nevent: done\ndata: \n\n"), true)
+}
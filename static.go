@@ -0,0 +1,305 @@
+package lars
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// indexFile is the file served for requests to a directory
+const indexFile = "index.html"
+
+// immutableCacheControl is sent for fingerprinted static files, see SetStaticImmutable
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// precompressed are the content codings, and file extensions, of the precompressed
+// siblings of static files in order of preference; the smallest first.
+var precompressed = []struct{ encoding, ext string }{
+	{Brotli, ".br"},
+	{Gzip, ".gz"},
+}
+
+// Static serves the files in dir, and it's sub directories, for GET and HEAD
+// requests under prefix through the group's middleware; the Content-Type is
+// detected from the file extension and requests to a directory serve it's
+// index.html. Paths are cleaned so they can't escape dir, any file not found
+// is answered by the 404 handlers, and directories are never listed.
+//
+// A file's precompressed sibling, i.e. app.css.br or app.css.gz, is served in it's
+// place, with the Content-Encoding, when the client accepts the encoding; so files can
+// be compressed at build time, at the highest level, instead of on every request.
+//
+// i.e. l.Static("/assets", "public/assets") serves /assets/css/app.css from
+// public/assets/css/app.css
+func (g *routeGroup) Static(prefix, dir string) {
+	g.StaticFS(prefix, http.Dir(dir))
+}
+
+// StaticFS serves the files in fs the same as Static, use http.FS to serve an fs.FS
+// such as an embed.FS so binaries built with go:embed don't need the files on disk.
+// Files without a modification time, as is the case for embedded files, are tagged
+// with an ETag generated from their content so they can still be cached.
+//
+// i.e. l.StaticFS("/assets", http.FS(assets))
+func (g *routeGroup) StaticFS(prefix string, fs http.FileSystem) {
+
+	h := func(c Context) {
+		serveFile(c, fs, c.Param(WildcardParam))
+	}
+
+	p := joinPaths(prefix, "/*")
+
+	g.Get(p, h)
+	g.Head(p, h)
+}
+
+// StaticFile serves the single file for GET and HEAD requests to path through
+// the group's middleware, the file is answered by the 404 handlers when it
+// does not exist.
+//
+// i.e. l.StaticFile("/favicon.ico", "public/favicon.ico")
+func (g *routeGroup) StaticFile(path, file string) {
+	g.StaticFileFS(path, filepath.Base(file), http.Dir(filepath.Dir(file)))
+}
+
+// StaticFileFS serves the file name, from fs, the same as StaticFile.
+//
+// i.e. l.StaticFileFS("/favicon.ico", "favicon.ico", http.FS(assets))
+func (g *routeGroup) StaticFileFS(path, name string, fs http.FileSystem) {
+
+	h := func(c Context) {
+		serveFile(c, fs, name)
+	}
+
+	g.Get(path, h)
+	g.Head(path, h)
+}
+
+// SPA serves a single page application from dir under prefix; files in dir are served
+// the same as Static while unknown paths without a file extension, requested by a
+// browser i.e. with Accept: text/html, are answered with dir's index file, blank for
+// index.html, so client-side routes can be deep linked. Routes registered under prefix,
+// such as an API, take precedence; any other request is answered by the 404 handlers
+// registered using l.Register404.
+//
+// i.e. l.SPA("/", "dist", "") serves /js/app.js from dist/js/app.js and /users/7
+// from dist/index.html
+func (g *routeGroup) SPA(prefix, dir, index string) {
+	g.SPAFS(prefix, http.Dir(dir), index)
+}
+
+// SPAFS serves the single page application in fs the same as SPA, use http.FS to serve
+// an fs.FS such as an embed.FS.
+func (g *routeGroup) SPAFS(prefix string, fs http.FileSystem, index string) {
+
+	if index == blank {
+		index = indexFile
+	}
+
+	spa := g.Group(prefix).(*routeGroup)
+
+	spa.Register404(func(c Context) {
+
+		req := c.Request()
+
+		if req.Method != GET && req.Method != HEAD {
+			c.BaseContext().run(g.lars.http404)
+			return
+		}
+
+		name := path.Clean(basePath + strings.TrimPrefix(req.URL.Path, spa.prefix))
+
+		if f, err := fs.Open(name); err == nil {
+
+			fi, err := f.Stat()
+			f.Close()
+
+			if err == nil && !fi.IsDir() {
+				serveFile(c, fs, name)
+				return
+			}
+		}
+
+		if path.Ext(name) != blank || !strings.Contains(req.Header.Get(Accept), TextHTML) {
+			c.BaseContext().run(g.lars.http404)
+			return
+		}
+
+		f, err := fs.Open(basePath + index)
+		if err != nil {
+			c.BaseContext().run(g.lars.http404)
+			return
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil || fi.IsDir() {
+			c.BaseContext().run(g.lars.http404)
+			return
+		}
+
+		serveContent(c, fi, f)
+	})
+}
+
+// serveFile serves the file name from fs, name is cleaned and rooted so
+// it can't escape the file system.
+func serveFile(c Context, fs http.FileSystem, name string) {
+
+	name = path.Clean("/" + name)
+
+	f, err := fs.Open(name)
+	if err != nil {
+		c.BaseContext().notFound()
+		return
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		c.BaseContext().notFound()
+		return
+	}
+
+	if fi.IsDir() {
+
+		// redirect so relative links in the index resolve within the directory
+		if p := c.Request().URL.Path; !strings.HasSuffix(p, basePath) {
+			u := *c.Request().URL
+			u.Path = p + basePath
+			http.Redirect(c.Response(), c.Request(), u.String(), http.StatusMovedPermanently)
+			return
+		}
+
+		index, err := fs.Open(path.Join(name, indexFile))
+		if err != nil {
+			c.BaseContext().notFound()
+			return
+		}
+		defer index.Close()
+
+		if fi, err = index.Stat(); err != nil || fi.IsDir() {
+			c.BaseContext().notFound()
+			return
+		}
+
+		f = index
+		name = path.Join(name, indexFile)
+	}
+
+	if l := c.BaseContext().lars; l != nil && l.staticImmutable != nil && l.staticImmutable(fi.Name()) {
+		c.Response().Header().Set(CacheControl, immutableCacheControl)
+	}
+
+	if servePrecompressed(c, fs, name, fi) {
+		return
+	}
+
+	serveContent(c, fi, f)
+}
+
+// servePrecompressed serves the precompressed sibling of the file name, with the
+// Content-Type of the file, when there's one the client accepts; returning false
+// when there's none.
+func servePrecompressed(c Context, fs http.FileSystem, name string, fi os.FileInfo) bool {
+
+	var files [2]http.File
+	var infos [2]os.FileInfo
+	var available []string
+
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+			}
+		}
+	}()
+
+	for i, p := range precompressed {
+
+		f, err := fs.Open(name + p.ext)
+		if err != nil {
+			continue
+		}
+
+		files[i] = f
+
+		if infos[i], err = f.Stat(); err != nil || infos[i].IsDir() {
+			continue
+		}
+
+		available = append(available, p.encoding)
+	}
+
+	if len(available) == 0 {
+		return false
+	}
+
+	h := c.Response().Header()
+	h.Add(Vary, AcceptEncoding)
+
+	encoding := c.AcceptEncoding(available...)
+	if encoding == blank {
+		return false
+	}
+
+	for i, p := range precompressed {
+
+		if p.encoding != encoding {
+			continue
+		}
+
+		h.Set(ContentType, detectContentType(fi.Name()))
+		h.Set(ContentEncoding, encoding)
+
+		serveContent(c, infos[i], files[i])
+	}
+
+	return true
+}
+
+// serveContent serves the file f, those without a modification time, i.e. embedded
+// files, are tagged with an ETag generated from their content instead.
+func serveContent(c Context, fi os.FileInfo, f http.File) {
+
+	if fi.ModTime().IsZero() {
+		if err := c.ServeContentConditional(fi.Name(), blank, fi.ModTime(), f); err != nil {
+			http.Error(c.Response(), http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.ServeContent(fi.Name(), fi.ModTime(), f)
+}
+
+// SetStaticImmutable sets the function reporting whether a static file, by it's name,
+// is fingerprinted i.e. app.3f2a1b9c.js; the content of such files never changes, a new
+// version has a new name, so they're sent with a Cache-Control header allowing clients
+// to cache them for a year without revalidating. IsFingerprinted detects names with a
+// hexadecimal content hash. default nil (disabled)
+func (l *LARS) SetStaticImmutable(fn func(name string) bool) {
+	l.staticImmutable = fn
+}
+
+// IsFingerprinted reports whether the file name has a fingerprint, a hexadecimal hash
+// of at least 8 characters, preceding it's extension and separated by a dot or hyphen
+// i.e. app.3f2a1b9c.js or app-3f2a1b9c.css.
+func IsFingerprinted(name string) bool {
+
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	i := strings.LastIndexAny(name, ".-")
+	if i <= 0 || len(name)-i-1 < 8 {
+		return false
+	}
+
+	for _, c := range []byte(name[i+1:]) {
+		if !isHex(c) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,347 @@
+package lars
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestStatic(t *testing.T) {
+
+	root, err := ioutil.TempDir("", "lars-static")
+	Equal(t, err, nil)
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "public")
+
+	Equal(t, os.MkdirAll(filepath.Join(dir, "css"), 0755), nil)
+	Equal(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755), nil)
+	Equal(t, os.MkdirAll(filepath.Join(dir, "empty"), 0755), nil)
+	Equal(t, ioutil.WriteFile(filepath.Join(dir, "css", "app.css"), []byte("body{}"), 0644), nil)
+	Equal(t, ioutil.WriteFile(filepath.Join(dir, "docs", "index.html"), []byte("<h1>docs</h1>"), 0644), nil)
+	Equal(t, ioutil.WriteFile(filepath.Join(dir, "favicon.ico"), []byte("ico"), 0644), nil)
+	Equal(t, ioutil.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0644), nil)
+
+	l := New()
+	l.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "custom 404")
+	})
+
+	assets := l.Group("/assets")
+	assets.Use(func(c Context) {
+		c.Response().Header().Set("X-Assets", "true")
+		c.Next()
+	})
+	assets.Static("/", dir)
+
+	l.StaticFile("/favicon.ico", filepath.Join(dir, "favicon.ico"))
+	l.StaticFile("/missing.ico", filepath.Join(dir, "missing.ico"))
+
+	hf := l.Serve()
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(GET, "/assets/css/app.css")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "body{}")
+	Equal(t, w.Header().Get(ContentType), "text/css; charset=utf-8")
+	Equal(t, w.Header().Get("X-Assets"), "true")
+	NotEqual(t, w.Header().Get("Last-Modified"), "")
+
+	w = serve(HEAD, "/assets/css/app.css")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.Len(), 0)
+	Equal(t, w.Header().Get(ContentLength), "6")
+
+	w = serve(GET, "/assets/docs/")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "<h1>docs</h1>")
+	Equal(t, w.Header().Get(ContentType), "text/html; charset=utf-8")
+
+	w = serve(GET, "/assets/docs?v=1")
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/assets/docs/?v=1")
+
+	w = serve(GET, "/assets/empty/")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "custom 404")
+
+	w = serve(GET, "/assets/css/missing.css")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "custom 404")
+
+	// path traversal
+	w = serve(GET, "/assets/../secret.txt")
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serve(GET, "/assets/css/../../secret.txt")
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serve(GET, "/assets/css/../css/app.css")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "body{}")
+
+	w = serve(GET, "/favicon.ico")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "ico")
+	Equal(t, w.Header().Get("X-Assets"), "")
+
+	w = serve(GET, "/missing.ico")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "custom 404")
+}
+
+func TestSPA(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "lars-spa")
+	Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	Equal(t, os.MkdirAll(filepath.Join(dir, "js"), 0755), nil)
+	Equal(t, ioutil.WriteFile(filepath.Join(dir, "js", "app.js"), []byte("app()"), 0644), nil)
+	Equal(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("<div id=app></div>"), 0644), nil)
+
+	l := New()
+	l.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "custom 404")
+	})
+	l.Use(func(c Context) {
+		c.Response().Header().Add("X-Middleware", "true")
+		c.Next()
+	})
+	l.Get("/api/users", func(c Context) {
+		c.Text(http.StatusOK, "users")
+	})
+	l.SPA("/", dir, "")
+
+	hf := l.Serve()
+
+	serve := func(method, path, accept string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, path, nil)
+		if accept != "" {
+			r.Header.Set(Accept, accept)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	const browser = "text/html,application/xhtml+xml,*/*;q=0.8"
+
+	w := serve(GET, "/js/app.js", "*/*")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "app()")
+	Equal(t, w.Header()["X-Middleware"], []string{"true"})
+
+	w = serve(GET, "/users/7", browser)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), TextHTMLCharsetUTF8)
+	Equal(t, w.Body.String(), "<div id=app></div>")
+
+	w = serve(GET, "/", browser)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "<div id=app></div>")
+
+	w = serve(HEAD, "/users/7", browser)
+	Equal(t, w.Code, http.StatusOK)
+
+	w = serve(GET, "/api/users", browser)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "users")
+
+	// only browsers navigating to a client-side route get the index
+	w = serve(GET, "/api/missing", "application/json")
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "custom 404")
+	Equal(t, w.Header()["X-Middleware"], []string{"true"})
+
+	w = serve(GET, "/js/missing.js", browser)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "custom 404")
+
+	w = serve(POST, "/users/7", browser)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serve(GET, "/../secret.txt", browser)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	// no index file
+	l = New()
+	l.Group("/app").SPA("/", filepath.Join(dir, "js"), "missing.html")
+
+	hf = l.Serve()
+
+	w = serve(GET, "/app/app.js", "")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "app()")
+
+	w = serve(GET, "/app/users", browser)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "Not Found\n")
+
+	w = serve(GET, "/other", browser)
+	Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestStaticFS(t *testing.T) {
+
+	modtime := time.Date(2016, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	// embedded files have no modification time
+	assets := fstest.MapFS{
+		"css/app.css":  {Data: []byte("body{}")},
+		"js/app.js":    {Data: []byte("app()"), ModTime: modtime},
+		"favicon.ico":  {Data: []byte("ico")},
+		"index.html":   {Data: []byte("<div id=app></div>")},
+		"docs/a/b.txt": {Data: []byte("b")},
+	}
+
+	l := New()
+	l.StaticFS("/assets", http.FS(assets))
+	l.StaticFileFS("/favicon.ico", "favicon.ico", http.FS(assets))
+	l.Group("/app").SPAFS("/", http.FS(assets), "")
+
+	hf := l.Serve()
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/assets/css/app.css", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "body{}")
+	Equal(t, w.Header().Get(ContentType), "text/css; charset=utf-8")
+	Equal(t, w.Header().Get(LastModified), "")
+
+	etag := w.Header().Get(ETag)
+	Equal(t, etag, GenerateETag([]byte("body{}")))
+
+	w = serve("/assets/css/app.css", http.Header{IfNoneMatch: {etag}})
+	Equal(t, w.Code, http.StatusNotModified)
+	Equal(t, w.Body.String(), "")
+
+	// files with a modification time are served as usual
+	w = serve("/assets/js/app.js", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ETag), "")
+	Equal(t, w.Header().Get(LastModified), modtime.Format(http.TimeFormat))
+
+	w = serve("/assets/missing.js", nil)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serve("/favicon.ico", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "ico")
+
+	w = serve("/app/users/7", http.Header{Accept: {TextHTML}})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "<div id=app></div>")
+	Equal(t, w.Header().Get(ETag), GenerateETag([]byte("<div id=app></div>")))
+
+	w = serve("/app/docs/a/b.txt", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "b")
+}
+
+func TestStaticPrecompressed(t *testing.T) {
+
+	assets := fstest.MapFS{
+		"app.3f2a1b9c.js":    {Data: []byte("app()")},
+		"app.3f2a1b9c.js.br": {Data: []byte("br")},
+		"app.3f2a1b9c.js.gz": {Data: []byte("gz")},
+		"app.css":            {Data: []byte("body{}")},
+		"app.css.gz":         {Data: []byte("gz")},
+		"docs/index.html":    {Data: []byte("docs")},
+		"docs/index.html.gz": {Data: []byte("gz")},
+		"logo.png":           {Data: []byte("png")},
+	}
+
+	l := New()
+	l.SetStaticImmutable(IsFingerprinted)
+	l.StaticFS("/", http.FS(assets))
+
+	hf := l.Serve()
+
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		if acceptEncoding != "" {
+			r.Header.Set(AcceptEncoding, acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	// brotli is preferred, being smaller, unless the client prefers otherwise
+	w := serve("/app.3f2a1b9c.js", "gzip, deflate, br")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "br")
+	Equal(t, w.Header().Get(ContentEncoding), Brotli)
+	Equal(t, w.Header().Get(ContentType), "text/javascript; charset=utf-8")
+	Equal(t, w.Header().Get(Vary), AcceptEncoding)
+	Equal(t, w.Header().Get(CacheControl), "public, max-age=31536000, immutable")
+
+	w = serve("/app.3f2a1b9c.js", "gzip, br;q=0.5")
+	Equal(t, w.Body.String(), "gz")
+	Equal(t, w.Header().Get(ContentEncoding), Gzip)
+
+	w = serve("/app.3f2a1b9c.js", "deflate")
+	Equal(t, w.Body.String(), "app()")
+	Equal(t, w.Header().Get(ContentEncoding), "")
+	Equal(t, w.Header().Get(Vary), AcceptEncoding)
+
+	w = serve("/app.3f2a1b9c.js", "")
+	Equal(t, w.Body.String(), "app()")
+
+	w = serve("/app.css", "*")
+	Equal(t, w.Body.String(), "gz")
+	Equal(t, w.Header().Get(ContentType), "text/css; charset=utf-8")
+	Equal(t, w.Header().Get(CacheControl), "")
+
+	w = serve("/app.css", "gzip;q=0, *")
+	Equal(t, w.Body.String(), "body{}")
+
+	w = serve("/docs/", "gzip")
+	Equal(t, w.Body.String(), "gz")
+	Equal(t, w.Header().Get(ContentType), TextHTMLCharsetUTF8)
+
+	w = serve("/logo.png", "gzip, br")
+	Equal(t, w.Body.String(), "png")
+	Equal(t, w.Header().Get(Vary), "")
+
+	Equal(t, IsFingerprinted("app-3f2a1b9c.css"), true)
+	Equal(t, IsFingerprinted("app.3F2A1B9C0D.js"), true)
+	Equal(t, IsFingerprinted("app.3f2a1b9.js"), false)
+	Equal(t, IsFingerprinted("app.min.js"), false)
+	Equal(t, IsFingerprinted("3f2a1b9c.js"), false)
+	Equal(t, IsFingerprinted("deadbeefcafe"), false)
+}
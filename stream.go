@@ -0,0 +1,165 @@
+package lars
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// jsonArrayFlushItems is the number of items written between each flush to the client
+const jsonArrayFlushItems = 100
+
+var (
+	jsonArrayStart = []byte("[")
+	jsonArraySep   = []byte(",")
+	jsonArrayEnd   = []byte("]")
+)
+
+// JSONStream encodes provided interface directly to the response + returns JSON +
+// status code, unlike JSON the encoded value isn't held in memory before being sent
+// so it's suited to large payloads; the response is sent chunked, terminated by a
+// newline and isn't indented. The status code isn't written when encoding fails
+// before anything was written, so an error response can still be sent.
+// NOTE: the JSONCodec, see SetJSONCodec, must implement JSONEncoder to encode
+// directly, otherwise the value is marshaled first.
+func (c *Ctx) JSONStream(code int, i interface{}) error {
+
+	c.response.Header().Set(ContentType, ApplicationJSONCharsetUTF8)
+
+	w := &lazyWriter{c: c, code: code}
+
+	if enc, ok := c.jsonCodec().(JSONEncoder); ok {
+		if err := enc.Encode(w, i); err != nil {
+			return err
+		}
+		return w.err
+	}
+
+	b, err := c.jsonCodec().Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	w.Write(append(b, '\n'))
+
+	return w.err
+}
+
+// JSONArrayStream streams a JSON array response with status code, writing each item
+// returned by next, until it returns false, as an element of the array; items are
+// flushed to the client as they're written so large result sets aren't held in
+// memory. Streaming stops, returning nil, when the client goes away. An error
+// marshaling an item is returned and, when items were already sent, leaves the array
+// unterminated so the client can tell the response was cut short.
+//
+//	c.JSONArrayStream(http.StatusOK, func() (interface{}, bool) {
+//		if !rows.Next() {
+//			return nil, false
+//		}
+//		...
+//		return user, true
+//	})
+func (c *Ctx) JSONArrayStream(code int, next func() (interface{}, bool)) error {
+
+	c.response.Header().Set(ContentType, ApplicationJSONCharsetUTF8)
+
+	w := &lazyWriter{c: c, code: code, prefix: jsonArrayStart}
+	codec := c.jsonCodec()
+	done := c.request.Context().Done()
+
+	for n := 1; ; n++ {
+
+		item, ok := next()
+		if !ok {
+			break
+		}
+
+		b, err := codec.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		if n > 1 {
+			w.Write(jsonArraySep)
+		}
+
+		if w.Write(b); w.err != nil {
+			return w.err
+		}
+
+		if n%jsonArrayFlushItems != 0 {
+			continue
+		}
+
+		c.response.Flush()
+
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+	}
+
+	w.Write(jsonArrayEnd)
+
+	if w.err != nil {
+		return w.err
+	}
+
+	c.response.Flush()
+
+	return nil
+}
+
+// XMLStream encodes provided interface directly to the response + returns XML +
+// status code, unlike XML the encoded value isn't held in memory before being sent
+// so it's suited to large payloads; the response is sent chunked. The status code
+// isn't written when encoding fails before anything was written, so an error
+// response can still be sent.
+func (c *Ctx) XMLStream(code int, i interface{}) error {
+
+	c.response.Header().Set(ContentType, ApplicationXMLCharsetUTF8)
+
+	w := &lazyWriter{c: c, code: code, prefix: xmlHeader}
+
+	if err := xml.NewEncoder(w).Encode(i); err != nil {
+		return err
+	}
+
+	return w.err
+}
+
+// lazyWriter writes the status code, followed by prefix, on the first write so
+// nothing is committed when encoding fails before producing any output; like
+// streamWriter it records the first error writing to the *Response.
+type lazyWriter struct {
+	c       *Ctx
+	code    int
+	prefix  []byte
+	started bool
+	err     error
+}
+
+var _ io.Writer = &lazyWriter{}
+
+func (w *lazyWriter) Write(b []byte) (n int, err error) {
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if !w.started {
+
+		w.started = true
+		w.c.response.WriteHeader(w.code)
+
+		if len(w.prefix) > 0 {
+			if _, w.err = w.c.response.Write(w.prefix); w.err != nil {
+				return 0, w.err
+			}
+		}
+	}
+
+	n, w.err = w.c.response.Write(b)
+
+	return n, w.err
+}
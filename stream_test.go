@@ -0,0 +1,169 @@
+package lars
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+// marshalOnlyCodec shadows StdJSONCodec's Encode so it doesn't implement JSONEncoder
+type marshalOnlyCodec struct{ StdJSONCodec }
+
+func (marshalOnlyCodec) Encode() {}
+
+func TestJSONAndXMLStream(t *testing.T) {
+
+	l := New()
+	l.Get("/json", func(c Context) error {
+		return c.JSONStream(http.StatusCreated, zombie{1, "Patient <Zero>"})
+	})
+	l.Get("/xml", func(c Context) error {
+		return c.XMLStream(http.StatusCreated, zombie{1, "Patient Zero"})
+	})
+	l.Get("/badjson", func(c Context) {
+		if err := c.JSONStream(http.StatusOK, func() {}); err != nil {
+			http.Error(c.Response(), err.Error(), http.StatusInternalServerError)
+		}
+	})
+	l.Get("/badxml", func(c Context) {
+		if err := c.XMLStream(http.StatusOK, func() {}); err != nil {
+			http.Error(c.Response(), err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/json")
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Header().Get(ContentLength), "")
+	Equal(t, w.Body.String(), "{\"id\":1,\"name\":\"Patient \\u003cZero\\u003e\"}\n")
+
+	w = serve("/xml")
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Header().Get(ContentType), ApplicationXMLCharsetUTF8)
+	Equal(t, w.Body.String(), xml.Header+"<zombie><id>1</id><name>Patient Zero</name></zombie>")
+
+	// nothing is committed when encoding fails
+	w = serve("/badjson")
+	Equal(t, w.Code, http.StatusInternalServerError)
+	Equal(t, w.Body.String(), "json: unsupported type: func()\n")
+
+	w = serve("/badxml")
+	Equal(t, w.Code, http.StatusInternalServerError)
+	Equal(t, w.Body.String(), "xml: unsupported type: func()\n")
+
+	l.SetJSONCodec(StdJSONCodec{DisableHTMLEscape: true})
+
+	w = serve("/json")
+	Equal(t, w.Body.String(), "{\"id\":1,\"name\":\"Patient <Zero>\"}\n")
+
+	// codecs which can't encode to a writer are marshaled first
+	l.SetJSONCodec(marshalOnlyCodec{})
+
+	w = serve("/json")
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "{\"id\":1,\"name\":\"Patient \\u003cZero\\u003e\"}\n")
+
+	w = serve("/badjson")
+	Equal(t, w.Code, http.StatusInternalServerError)
+}
+
+func TestJSONArrayStream(t *testing.T) {
+
+	var cancel context.CancelFunc
+	var produced int
+
+	items := func(n int, bad bool) func() (interface{}, bool) {
+
+		produced = 0
+
+		return func() (interface{}, bool) {
+
+			if produced == n {
+				return nil, false
+			}
+
+			produced++
+
+			if produced == 150 && cancel != nil {
+				cancel()
+			}
+
+			if bad && produced == 2 {
+				return func() {}, true
+			}
+
+			return zombie{produced, "Patient Zero"}, true
+		}
+	}
+
+	l := New()
+	l.Get("/empty", func(c Context) error {
+		return c.JSONArrayStream(http.StatusOK, items(0, false))
+	})
+	l.Get("/array", func(c Context) error {
+		return c.JSONArrayStream(http.StatusOK, items(2, false))
+	})
+	l.Get("/large", func(c Context) error {
+		return c.JSONArrayStream(http.StatusOK, items(1000, false))
+	})
+	l.Get("/bad", func(c Context) {
+		err := c.JSONArrayStream(http.StatusOK, items(3, true))
+		Equal(t, err.Error(), "json: unsupported type: func()")
+	})
+
+	serve := func(path string, ctx context.Context) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r.WithContext(ctx))
+		return w
+	}
+
+	w := serve("/empty", context.Background())
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), "[]")
+
+	w = serve("/array", context.Background())
+	Equal(t, w.Flushed, true)
+	Equal(t, w.Body.String(), `[{"id":1,"name":"Patient Zero"},{"id":2,"name":"Patient Zero"}]`)
+
+	w = serve("/large", context.Background())
+	Equal(t, strings.Count(w.Body.String(), `"id"`), 1000)
+	Equal(t, strings.HasSuffix(w.Body.String(), "]"), true)
+
+	// the array is left unterminated when an item can't be marshaled
+	w = serve("/bad", context.Background())
+	Equal(t, w.Body.String(), `[{"id":1,"name":"Patient Zero"}`)
+
+	// the client goes away, streaming stops at the next flush
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	w = serve("/large", ctx)
+	Equal(t, produced, 200)
+	Equal(t, strings.Count(w.Body.String(), `"id"`), 200)
+}
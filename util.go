@@ -2,12 +2,28 @@ package lars
 
 import (
 	"mime"
+	"net"
 	"net/http"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// acceptValue is a single value of an Accept-* header and it's quality
+type acceptValue struct {
+	value   string
+	quality float64
+}
+
+type acceptValues []acceptValue
+
+func (a acceptValues) Len() int           { return len(a) }
+func (a acceptValues) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a acceptValues) Less(i, j int) bool { return a[i].quality > a[j].quality }
+
 // NativeChainHandler is used in native handler chain middleware
 // example using nosurf crsf middleware nosurf.NewPure(lars.NativeChainHandler)
 var NativeChainHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -30,6 +46,103 @@ func GetContext(w http.ResponseWriter) Context {
 	return w.(*Response).context
 }
 
+// parseAccept parses an Accept-* header into it's values sorted by descending
+// quality, values with no or a malformed q parameter default to 1.0 and ties
+// preserve the order they were sent in.
+func parseAccept(header string) acceptValues {
+
+	options := strings.Split(header, ",")
+	values := make(acceptValues, 0, len(options))
+
+	for _, option := range options {
+
+		params := strings.Split(option, ";")
+
+		v := acceptValue{value: strings.TrimSpace(params[0]), quality: 1}
+		if v.value == blank {
+			continue
+		}
+
+		for _, p := range params[1:] {
+
+			p = strings.TrimSpace(p)
+
+			if len(p) > 2 && (p[0] == 'q' || p[0] == 'Q') && p[1] == '=' {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q >= 0 && q <= 1 {
+					v.quality = q
+				}
+				break
+			}
+		}
+
+		values = append(values, v)
+	}
+
+	sort.Stable(values)
+
+	return values
+}
+
+// WebSocketOrigins returns a function, for use as a websocket.Upgrader's CheckOrigin,
+// that only allows websocket upgrades from the provided origins i.e. "https://example.com"
+// Requests without an Origin header, sent by non browser clients, are allowed.
+func WebSocketOrigins(origins ...string) func(r *http.Request) bool {
+
+	return func(r *http.Request) bool {
+
+		origin := r.Header.Get(Origin)
+		if origin == blank {
+			return true
+		}
+
+		for _, o := range origins {
+			if strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// ParseNetworks parses the networks, as CIDRs or single IPs i.e. "10.0.0.0/8" or
+// "127.0.0.1", a single IP becoming a network containing only itself; used for both the
+// trusted proxies and the ip filter middleware so they're interpreted the same.
+// NOTE: panics, prefixing the message with prefix, if a network is not a valid CIDR or IP
+func ParseNetworks(prefix string, networks ...string) []*net.IPNet {
+
+	parsed := make([]*net.IPNet, 0, len(networks))
+
+	for _, n := range networks {
+
+		if strings.IndexByte(n, '/') == -1 {
+
+			ip := net.ParseIP(n)
+			if ip == nil {
+				panic(prefix + " '" + n + "'")
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+
+			parsed = append(parsed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(n)
+		if err != nil {
+			panic(prefix + " '" + n + "': " + err.Error())
+		}
+
+		parsed = append(parsed, network)
+	}
+
+	return parsed
+}
+
 func detectContentType(filename string) (t string) {
 	if t = mime.TypeByExtension(filepath.Ext(filename)); t == "" {
 		t = OctetStream
@@ -47,12 +160,21 @@ func (l *LARS) wrapHandler(h Handler) HandlerFunc {
 	case func(Context):
 		return h
 
+	case func(Context) error:
+		return func(c Context) {
+			if err := h(c); err != nil {
+				// roll back a buffered response so the error can be written cleanly
+				c.Response().Reset()
+				l.errorHandler(err, c)
+			}
+		}
+
 	case http.Handler, http.HandlerFunc:
 		return func(c Context) {
 
 			ctx := c.BaseContext()
 
-			if h.(http.Handler).ServeHTTP(ctx.response, ctx.request); ctx.response.status != http.StatusOK || ctx.response.committed {
+			if h.(http.Handler).ServeHTTP(ctx.response, ctx.Request()); ctx.response.status != http.StatusOK || ctx.response.written() {
 				return
 			}
 
@@ -66,7 +188,7 @@ func (l *LARS) wrapHandler(h Handler) HandlerFunc {
 
 			ctx := c.BaseContext()
 
-			if h(ctx.response, ctx.request); ctx.response.status != http.StatusOK || ctx.response.committed {
+			if h(ctx.response, ctx.Request()); ctx.response.status != http.StatusOK || ctx.response.written() {
 				return
 			}
 
@@ -80,18 +202,14 @@ func (l *LARS) wrapHandler(h Handler) HandlerFunc {
 		return func(c Context) {
 			ctx := c.BaseContext()
 
-			h(ctx.response, ctx.request, NativeChainHandler)
+			h(ctx.response, ctx.Request(), http.HandlerFunc(ctx.resume))
 		}
 
-	case func(http.Handler) http.Handler:
-
-		hf := h(NativeChainHandler)
-
-		return func(c Context) {
-			ctx := c.BaseContext()
+	case func(http.ResponseWriter, *http.Request, http.HandlerFunc):
+		return WrapNegroni(h)
 
-			hf.ServeHTTP(ctx.response, ctx.request)
-		}
+	case func(http.Handler) http.Handler:
+		return WrapMiddleware(h)
 
 	default:
 		if fn, ok := l.customHandlersFuncs[reflect.TypeOf(h)]; ok {
@@ -114,10 +232,10 @@ func (l *LARS) wrapHandlerWithName(h Handler) (chain HandlerFunc, handlerName st
 
 func (l *LARS) redirect(method string, to string) (handlers HandlersChain) {
 
-	code := http.StatusMovedPermanently
+	code := l.redirectPermanentCode
 
 	if method != GET {
-		code = http.StatusTemporaryRedirect
+		code = l.redirectTemporaryCode
 	}
 
 	fn := func(c Context) {
@@ -133,6 +251,40 @@ func (l *LARS) redirect(method string, to string) (handlers HandlersChain) {
 	return
 }
 
+// joinPaths joins two path segments avoiding a double slash
+// where they meet i.e. "/admin/" + "/users" = "/admin/users"
+func joinPaths(a, b string) string {
+
+	if len(a) > 0 && a[len(a)-1] == slashByte && len(b) > 0 && b[0] == slashByte {
+		return a + b[1:]
+	}
+
+	return a + b
+}
+
+// validMethod reports whether method is a non-empty token as defined by
+// RFC 7230 section 3.2.6
+func validMethod(method string) bool {
+
+	if len(method) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(method); i++ {
+
+		c := method[i]
+
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 func min(a, b int) int {
 
 	if a <= b {
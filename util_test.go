@@ -92,3 +92,18 @@ func TestTooManyParams(t *testing.T) {
 	l := New()
 	PanicMatches(t, func() { l.Get(s, func(c Context) {}) }, "too many parameters defined in path, max is 255")
 }
+
+func TestParseNetworks(t *testing.T) {
+
+	networks := ParseNetworks("invalid network", "10.0.0.0/8", "127.0.0.1", "::1")
+
+	Equal(t, len(networks), 3)
+	Equal(t, networks[0].String(), "10.0.0.0/8")
+	Equal(t, networks[1].String(), "127.0.0.1/32")
+	Equal(t, networks[2].String(), "::1/128")
+
+	Equal(t, len(ParseNetworks("invalid network")), 0)
+
+	PanicMatches(t, func() { ParseNetworks("invalid network", "bad") }, "invalid network 'bad'")
+	PanicMatches(t, func() { ParseNetworks("invalid network", "10.0.0.0/99") }, "invalid network '10.0.0.0/99': invalid CIDR address: 10.0.0.0/99")
+}
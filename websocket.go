@@ -0,0 +1,89 @@
+package lars
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketConn is a websocket connection, independent of the websocket library
+// providing it. Message types are those defined by RFC 6455, as used by gorilla's
+// websocket.TextMessage and websocket.BinaryMessage; *websocket.Conn satisfies it.
+type WebSocketConn interface {
+	ReadMessage() (messageType int, data []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// WebSocketUpgrader upgrades a request to a websocket connection, it's the extension
+// point allowing websocket libraries other than gorilla/websocket to be used. When the
+// handshake fails an error response must already have been written.
+type WebSocketUpgrader interface {
+	Upgrade(w http.ResponseWriter, r *http.Request) (WebSocketConn, error)
+}
+
+// GorillaUpgrader adapts a gorilla websocket.Upgrader to a WebSocketUpgrader
+type GorillaUpgrader websocket.Upgrader
+
+var _ WebSocketUpgrader = new(GorillaUpgrader)
+
+// Upgrade upgrades the request using the gorilla websocket.Upgrader
+func (u *GorillaUpgrader) Upgrade(w http.ResponseWriter, r *http.Request) (WebSocketConn, error) {
+
+	ws, err := (*websocket.Upgrader)(u).Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// WebSocket returns context's assotiated *websocket.Conn, nil when the connection
+// was upgraded using another websocket library, see WebSocketConn.
+func (c *Ctx) WebSocket() *websocket.Conn {
+	ws, _ := c.websocket.(*websocket.Conn)
+	return ws
+}
+
+// WebSocketConn returns context's assotiated websocket connection, regardless of the
+// library it was upgraded with.
+func (c *Ctx) WebSocketConn() WebSocketConn {
+	return c.websocket
+}
+
+// Upgrade upgrades the current request to a websocket connection using the provided
+// upgrader, which configures origin checking and subprotocol negotiation, then calls
+// handler with the connection which is also accessible using WebSocket(). The connection
+// is closed once handler returns. If the handshake fails an error response has already
+// been written by the upgrader and the error is returned.
+// NOTE: use WebSocketOrigins to only allow the upgrade from an allowlist of origins,
+// preventing cross-site websocket hijacking.
+func (c *Ctx) Upgrade(upgrader websocket.Upgrader, handler func(*websocket.Conn)) error {
+
+	u := GorillaUpgrader(upgrader)
+
+	return c.UpgradeWith(&u, func(conn WebSocketConn) {
+		handler(conn.(*websocket.Conn))
+	})
+}
+
+// UpgradeWith upgrades the current request to a websocket connection using the provided
+// WebSocketUpgrader, allowing any websocket library to be used, then calls handler with
+// the connection which is also accessible using WebSocketConn(). The connection is closed
+// once handler returns. If the handshake fails the error is returned.
+func (c *Ctx) UpgradeWith(upgrader WebSocketUpgrader, handler func(WebSocketConn)) (err error) {
+
+	if c.websocket, err = upgrader.Upgrade(c.response, c.request); err != nil {
+		c.websocket = nil
+		return
+	}
+
+	defer func() {
+		c.websocket.Close()
+		c.websocket = nil
+	}()
+
+	handler(c.websocket)
+
+	return
+}
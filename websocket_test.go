@@ -0,0 +1,123 @@
+package lars
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+type stubConn struct {
+	written []string
+	closed  bool
+}
+
+func (s *stubConn) ReadMessage() (int, []byte, error) {
+	return websocket.TextMessage, []byte("ping"), nil
+}
+
+func (s *stubConn) WriteMessage(messageType int, data []byte) error {
+	s.written = append(s.written, string(data))
+	return nil
+}
+
+func (s *stubConn) Close() error {
+	s.closed = true
+	return nil
+}
+
+type stubUpgrader struct {
+	conn *stubConn
+	err  error
+}
+
+func (u *stubUpgrader) Upgrade(w http.ResponseWriter, r *http.Request) (WebSocketConn, error) {
+
+	if u.err != nil {
+		http.Error(w, u.err.Error(), http.StatusBadRequest)
+		return nil, u.err
+	}
+
+	return u.conn, nil
+}
+
+func TestUpgradeWith(t *testing.T) {
+
+	upgrader := &stubUpgrader{conn: new(stubConn)}
+
+	var inHandler, gorilla WebSocketConn
+	var upgradeErr error
+
+	l := New()
+	l.Get("/ws", func(c Context) {
+
+		upgradeErr = c.UpgradeWith(upgrader, func(conn WebSocketConn) {
+
+			inHandler = c.WebSocketConn()
+			gorilla = c.WebSocket()
+
+			_, data, _ := conn.ReadMessage()
+			conn.WriteMessage(websocket.TextMessage, append(data, " pong"...))
+		})
+
+		Equal(t, c.WebSocketConn(), nil)
+	})
+
+	code, _ := request(GET, "/ws", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, upgradeErr, nil)
+	Equal(t, inHandler, upgrader.conn)
+	Equal(t, gorilla, (*websocket.Conn)(nil))
+	Equal(t, upgrader.conn.written, []string{"ping pong"})
+	Equal(t, upgrader.conn.closed, true)
+
+	upgrader = &stubUpgrader{err: errors.New("bad handshake")}
+
+	code, body := request(GET, "/ws", l)
+	Equal(t, code, http.StatusBadRequest)
+	Equal(t, body, "bad handshake\n")
+	Equal(t, upgradeErr, upgrader.err)
+}
+
+func TestGorillaUpgrader(t *testing.T) {
+
+	l := New()
+	l.Get("/ws", func(c Context) error {
+		return c.UpgradeWith(&GorillaUpgrader{}, func(conn WebSocketConn) {
+
+			Equal(t, c.WebSocket() == conn, true)
+
+			typ, data, _ := conn.ReadMessage()
+			conn.WriteMessage(typ, data)
+		})
+	})
+
+	server := httptest.NewServer(l.Serve())
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	Equal(t, err, nil)
+	defer ws.Close()
+
+	ws.WriteMessage(websocket.BinaryMessage, []byte("echo"))
+
+	typ, data, err := ws.ReadMessage()
+	Equal(t, err, nil)
+	Equal(t, typ, websocket.BinaryMessage)
+	Equal(t, string(data), "echo")
+}
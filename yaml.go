@@ -0,0 +1,78 @@
+package lars
+
+import "io"
+
+// yamlMediaTypes are the media types YAML request bodies are sent with
+var yamlMediaTypes = []string{ApplicationYAML, ApplicationXYAML, TextYAML}
+
+// YAMLCodec marshals responses and decodes request bodies as YAML, lars has no YAML
+// implementation of it's own so one, such as gopkg.in/yaml.v3, must be adapted to it
+// and set using SetYAMLCodec i.e.
+//
+//	func (yamlCodec) Marshal(v interface{}) ([]byte, error) { return yaml.Marshal(v) }
+//	func (yamlCodec) Decode(r io.Reader, v interface{}) error { return yaml.NewDecoder(r).Decode(v) }
+type YAMLCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Decode(r io.Reader, v interface{}) error
+}
+
+// SetYAMLCodec sets the YAMLCodec used by c.YAML and to decode request bodies with the
+// "application/yaml", "application/x-yaml" or "text/yaml" Content-Type using Bind and
+// Decode; setting nil removes YAML support. default nil
+func (l *LARS) SetYAMLCodec(codec YAMLCodec) {
+
+	l.yamlCodec = codec
+
+	fn := BinderFunc(bindYAML)
+	if codec == nil {
+		fn = nil
+	}
+
+	for _, typ := range yamlMediaTypes {
+		l.RegisterBinder(typ, fn)
+	}
+}
+
+// YAML marshals provided interface + returns YAML + status code,
+// ErrNoYAMLCodec is returned when no YAMLCodec was set.
+func (c *Ctx) YAML(code int, i interface{}) error {
+
+	codec := c.lars.yamlCodec
+	if codec == nil {
+		return ErrNoYAMLCodec
+	}
+
+	b, err := codec.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	return c.YAMLBytes(code, b)
+}
+
+// YAMLBytes returns provided YAML response with status code
+func (c *Ctx) YAMLBytes(code int, b []byte) error {
+
+	c.response.Header().Set(ContentType, ApplicationYAML+"; "+CharsetUTF8)
+	return c.writeBody(code, b)
+}
+
+// YAMLOffer offers i rendered as YAML using c.YAML
+func YAMLOffer(i interface{}) Offer {
+	return Offer{
+		MediaType: ApplicationYAML,
+		Render: func(c Context, code int) error {
+			return c.YAML(code, i)
+		},
+	}
+}
+
+func bindYAML(c Context, v interface{}) error {
+
+	codec := c.BaseContext().lars.yamlCodec
+	if codec == nil {
+		return ErrUnsupportedMediaType
+	}
+
+	return codec.Decode(c.Request().Body, v)
+}
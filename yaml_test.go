@@ -0,0 +1,132 @@
+package lars
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+type yamlConfig struct {
+	Name string
+}
+
+// testYAMLCodec stands in for a YAML library, handling a single name field
+type testYAMLCodec struct{}
+
+func (testYAMLCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte("name: " + v.(yamlConfig).Name + "\n"), nil
+}
+
+func (testYAMLCodec) Decode(r io.Reader, v interface{}) error {
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s := strings.TrimSpace(string(b))
+
+	if !strings.HasPrefix(s, "name: ") {
+		return errors.New("invalid yaml")
+	}
+
+	v.(*yamlConfig).Name = s[len("name: "):]
+
+	return nil
+}
+
+func TestYAML(t *testing.T) {
+
+	l := New()
+	l.Get("/yaml", func(c Context) error {
+		return c.YAML(http.StatusOK, yamlConfig{Name: "lars"})
+	})
+	l.Get("/negotiate", func(c Context) error {
+		return c.Negotiate(http.StatusOK, JSONOffer(yamlConfig{Name: "lars"}), YAMLOffer(yamlConfig{Name: "lars"}))
+	})
+	l.Post("/bind", func(c Context) error {
+
+		var cfg yamlConfig
+
+		if err := c.Bind(&cfg); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, cfg.Name)
+	})
+	l.Post("/decode", func(c Context) error {
+
+		var cfg yamlConfig
+
+		if err := c.Decode(false, 1024, &cfg); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, cfg.Name)
+	})
+
+	post := func(path, contentType, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(POST, path, strings.NewReader(body))
+		r.Header.Set(ContentType, contentType)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	// no codec set
+	code, _ := request(GET, "/yaml", l)
+	Equal(t, code, http.StatusInternalServerError)
+
+	Equal(t, post("/bind", ApplicationYAML, "name: joey").Code, http.StatusUnsupportedMediaType)
+	Equal(t, post("/decode", ApplicationYAML, "name: joey").Code, http.StatusUnsupportedMediaType)
+
+	l.SetYAMLCodec(testYAMLCodec{})
+
+	r, _ := http.NewRequest(GET, "/yaml", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), "application/yaml; charset=utf-8")
+	Equal(t, w.Body.String(), "name: lars\n")
+
+	r, _ = http.NewRequest(GET, "/negotiate", nil)
+	r.Header.Set(Accept, ApplicationYAML)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "name: lars\n")
+
+	for _, typ := range []string{ApplicationYAML, ApplicationXYAML, TextYAML + "; charset=utf-8"} {
+
+		w = post("/bind", typ, "name: joey\n")
+		Equal(t, w.Code, http.StatusOK)
+		Equal(t, w.Body.String(), "joey")
+
+		w = post("/decode", typ, "name: joey\n")
+		Equal(t, w.Code, http.StatusOK)
+		Equal(t, w.Body.String(), "joey")
+	}
+
+	Equal(t, post("/bind", ApplicationYAML, "joey").Code, http.StatusInternalServerError)
+
+	l.SetYAMLCodec(nil)
+
+	Equal(t, post("/bind", TextYAML, "name: joey").Code, http.StatusUnsupportedMediaType)
+}